@@ -1,29 +1,71 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"go.uber.org/fx"
 )
 
+// The OTLP protocols OtelProtocol can be set to
+const (
+	OtelProtocolGRPC = "grpc"
+	OtelProtocolHTTP = "http/protobuf"
+)
+
+// The exporters MetricsExporter can be set to
+const (
+	MetricsExporterOTLP       = "otlp"
+	MetricsExporterPrometheus = "prometheus"
+)
+
 type Config struct {
-	Environment             string
-	OtelEndpoint            string
-	ServiceName             string
-	LogLevel                string
-	BatchSize               int
-	ExportTimeout           string
-	OtelExporterOtlpHeaders string
+	Environment  string
+	OtelEndpoint string
+	// OtelProtocol is how telemetry is exported to OtelEndpoint, OtelProtocolGRPC or
+	// OtelProtocolHTTP
+	OtelProtocol string
+	ServiceName  string
+	LogLevel     string
+	BatchSize    int
+	// ExportTimeout bounds connecting to the OTLP endpoint and flushing telemetry on shutdown
+	ExportTimeout time.Duration
+	// OtelInsecure sends telemetry in plaintext, for a local collector. When it's off the
+	// exporters use TLS verified against the system's root certificates.
+	OtelInsecure bool
+	// OtelSampleRatio is the fraction of traces kept, from 0 to 1. Spans follow their parent's
+	// decision when they have one.
+	OtelSampleRatio float64
+	// OtelProbe checks the OTLP endpoint can be reached at startup, waiting up to ExportTimeout
+	OtelProbe bool
+	// OtelExporterOtlpHeaders are sent with every export, e.g. an auth token for a hosted backend
+	OtelExporterOtlpHeaders map[string]string
+	// MetricsExporter is where metrics go, MetricsExporterOTLP to push them to OtelEndpoint
+	// with the traces and logs or MetricsExporterPrometheus to serve them for scraping
+	MetricsExporter string
+	// PrometheusAddr is the host:port the /metrics endpoint listens on with MetricsExporterPrometheus
+	PrometheusAddr string
 }
 
+// NewConfig loads a .env file into the environment if there is one, then reads the config
+// with NewConfigFromEnv. Variables already set in the environment win over the file.
 func NewConfig() (*Config, error) {
-	// Load environment variables from .env file
-	if err := godotenv.Load(); err != nil {
+	// Containers get their config straight from the environment, so no .env file is fine
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
 		return nil, fmt.Errorf("failed to load .env file: %w", err)
 	}
+	return NewConfigFromEnv()
+}
 
+// NewConfigFromEnv reads the config from environment variables only, never touching a .env file
+func NewConfigFromEnv() (*Config, error) {
 	// Get required environment variables
 	env := os.Getenv("ENVIRONMENT")
 	if env == "" {
@@ -36,14 +78,71 @@ func NewConfig() (*Config, error) {
 		otelEndpoint = "http://localhost:4318" // Default OTLP HTTP endpoint
 	}
 
+	otelProtocol := os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	switch otelProtocol {
+	case "":
+		otelProtocol = DefaultOtelProtocol(otelEndpoint)
+	case OtelProtocolGRPC, OtelProtocolHTTP:
+	default:
+		return nil, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_PROTOCOL %q, expected %s or %s", otelProtocol, OtelProtocolGRPC, OtelProtocolHTTP)
+	}
+
 	serviceName := os.Getenv("OTEL_SERVICE_NAME")
 	if serviceName == "" {
 		serviceName = "unknown-service"
 	}
 
-	headers := os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")
-	if headers == "" {
-		headers = ""
+	// Plaintext by default so local setups keep working, deployments turn it off
+	otelInsecure, err := boolFromEnv("OTEL_EXPORTER_OTLP_INSECURE", true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep everything while developing, production runs in tight loops and would flood the
+	// collector
+	sampleRatio := 1.0
+	if env == "production" {
+		sampleRatio = 0.1
+	}
+	if sampleRatioEnv := os.Getenv("OTEL_TRACES_SAMPLER_ARG"); sampleRatioEnv != "" {
+		parsed, err := strconv.ParseFloat(sampleRatioEnv, 64)
+		if err != nil || !(parsed >= 0 && parsed <= 1) {
+			return nil, fmt.Errorf("invalid OTEL_TRACES_SAMPLER_ARG %q, expected a ratio between 0 and 1", sampleRatioEnv)
+		}
+		sampleRatio = parsed
+	}
+
+	// The probe stalls startup when the collector comes up after us, so it's opt-in
+	otelProbe, err := boolFromEnv("OTEL_EXPORTER_OTLP_PROBE", false)
+	if err != nil {
+		return nil, err
+	}
+
+	headers, err := ParseOtlpHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_HEADERS: %w", err)
+	}
+
+	metricsExporter := os.Getenv("OTEL_METRICS_EXPORTER")
+	switch metricsExporter {
+	case "":
+		metricsExporter = MetricsExporterOTLP
+	case MetricsExporterOTLP, MetricsExporterPrometheus:
+	default:
+		return nil, fmt.Errorf("invalid OTEL_METRICS_EXPORTER %q, expected %s or %s", metricsExporter, MetricsExporterOTLP, MetricsExporterPrometheus)
+	}
+
+	// Same defaults as the spec, so a scrape config written for another service carries over
+	prometheusHost := os.Getenv("OTEL_EXPORTER_PROMETHEUS_HOST")
+	if prometheusHost == "" {
+		prometheusHost = "localhost"
+	}
+	prometheusPort := os.Getenv("OTEL_EXPORTER_PROMETHEUS_PORT")
+	if prometheusPort == "" {
+		prometheusPort = "9464"
+	}
+	if port, err := strconv.Atoi(prometheusPort); err != nil || port < 0 || port > 65535 {
+		return nil, fmt.Errorf("invalid OTEL_EXPORTER_PROMETHEUS_PORT %q, expected a port number", prometheusPort)
 	}
 
 	// Logging configuration with defaults
@@ -65,19 +164,92 @@ func NewConfig() (*Config, error) {
 	}
 
 	// Export timeout with default
-	exportTimeout := os.Getenv("OTEL_EXPORT_TIMEOUT")
-	if exportTimeout == "" {
-		exportTimeout = "5s"
+	exportTimeout := 5 * time.Second
+	if exportTimeoutEnv := os.Getenv("OTEL_EXPORT_TIMEOUT"); exportTimeoutEnv != "" {
+		parsed, err := time.ParseDuration(exportTimeoutEnv)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OTEL_EXPORT_TIMEOUT %q, expected a duration like 5s: %w", exportTimeoutEnv, err)
+		}
+		if parsed <= 0 {
+			return nil, fmt.Errorf("invalid OTEL_EXPORT_TIMEOUT %q, must be positive", exportTimeoutEnv)
+		}
+		exportTimeout = parsed
 	}
 
 	return &Config{
-		Environment:   env,
-		OtelEndpoint:  otelEndpoint,
-		ServiceName:   serviceName,
-		LogLevel:      logLevel,
-		BatchSize:     batchSize,
-		ExportTimeout: exportTimeout,
+		Environment:             env,
+		OtelEndpoint:            otelEndpoint,
+		OtelProtocol:            otelProtocol,
+		ServiceName:             serviceName,
+		LogLevel:                logLevel,
+		BatchSize:               batchSize,
+		ExportTimeout:           exportTimeout,
+		OtelInsecure:            otelInsecure,
+		OtelSampleRatio:         sampleRatio,
+		OtelProbe:               otelProbe,
+		OtelExporterOtlpHeaders: headers,
+		MetricsExporter:         metricsExporter,
+		PrometheusAddr:          net.JoinHostPort(prometheusHost, prometheusPort),
 	}, nil
 }
 
+// DefaultOtelProtocol guesses the protocol for an OTLP endpoint that doesn't say: the standard
+// ports 4317 (gRPC) and 4318 (HTTP) decide it, otherwise a URL with an http or https scheme is
+// taken to be HTTP and a bare host:port gRPC, the form each exporter's examples use
+func DefaultOtelProtocol(endpoint string) string {
+	host := endpoint
+	scheme, rest, hasScheme := strings.Cut(endpoint, "://")
+	if hasScheme {
+		host, _, _ = strings.Cut(rest, "/")
+	}
+	if _, port, err := net.SplitHostPort(host); err == nil {
+		switch port {
+		case "4317":
+			return OtelProtocolGRPC
+		case "4318":
+			return OtelProtocolHTTP
+		}
+	}
+	if hasScheme && (scheme == "http" || scheme == "https") {
+		return OtelProtocolHTTP
+	}
+	return OtelProtocolGRPC
+}
+
+// boolFromEnv reads a true/false environment variable, fallback if it isn't set
+func boolFromEnv(name string, fallback bool) (bool, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q, expected true or false: %w", name, value, err)
+	}
+	return parsed, nil
+}
+
+// ParseOtlpHeaders parses headers in the OTEL_EXPORTER_OTLP_HEADERS format, comma separated
+// key=value pairs with the values percent-encoded (e.g. "api-key=abc,authorization=Basic%20eA==").
+// Whitespace around keys and values is ignored and an empty string is no headers.
+func ParseOtlpHeaders(headers string) (map[string]string, error) {
+	parsed := make(map[string]string)
+	if strings.TrimSpace(headers) == "" {
+		return parsed, nil
+	}
+	for _, pair := range strings.Split(headers, ",") {
+		key, value, found := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("header %q isn't a key=value pair", strings.TrimSpace(pair))
+		}
+		decoded, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("header %q has a badly encoded value: %w", key, err)
+		}
+		parsed[key] = decoded
+	}
+	return parsed, nil
+}
+
 var Module = fx.Module("config", fx.Provide(NewConfig))
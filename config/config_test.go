@@ -0,0 +1,308 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Helper function to run the test from dir so NewConfig looks for .env there
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	previous, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(previous) })
+}
+
+func TestNewConfigWithoutEnvFile(t *testing.T) {
+	chdir(t, t.TempDir())
+	t.Setenv("ENVIRONMENT", "production")
+	t.Setenv("OTEL_SERVICE_NAME", "planner")
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("Expected a missing .env file to be fine, got %v", err)
+	}
+	if cfg.Environment != "production" || cfg.ServiceName != "planner" || cfg.LogLevel != "info" {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+}
+
+func TestNewConfigWithEnvFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("ENVIRONMENT=development\nOTEL_SERVICE_NAME=from-file\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write .env: %v", err)
+	}
+	chdir(t, dir)
+	// t.Setenv restores these afterwards, including the ones godotenv sets from the file
+	t.Setenv("ENVIRONMENT", "")
+	os.Unsetenv("ENVIRONMENT")
+	t.Setenv("OTEL_SERVICE_NAME", "from-env")
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Environment != "development" {
+		t.Errorf("Expected ENVIRONMENT from the .env file, got %q", cfg.Environment)
+	}
+	if cfg.ServiceName != "from-env" {
+		t.Errorf("Expected the environment to win over the .env file, got %q", cfg.ServiceName)
+	}
+}
+
+func TestNewConfigFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("ENVIRONMENT=from-file\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write .env: %v", err)
+	}
+	chdir(t, dir)
+	t.Setenv("ENVIRONMENT", "")
+	os.Unsetenv("ENVIRONMENT")
+
+	if _, err := NewConfigFromEnv(); err == nil {
+		t.Error("Expected an error without ENVIRONMENT, the .env file shouldn't be read")
+	}
+	t.Setenv("ENVIRONMENT", "staging")
+	cfg, err := NewConfigFromEnv()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Environment != "staging" || cfg.LogLevel != "debug" || cfg.BatchSize != 512 {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+}
+
+func TestExportTimeout(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "development")
+	tests := []struct {
+		value    string
+		expected time.Duration
+		valid    bool
+	}{
+		{value: "", expected: 5 * time.Second, valid: true},
+		{value: "1m30s", expected: 90 * time.Second, valid: true},
+		{value: "5", valid: false},
+		{value: "soon", valid: false},
+		{value: "-1s", valid: false},
+	}
+	for _, tt := range tests {
+		t.Setenv("OTEL_EXPORT_TIMEOUT", tt.value)
+		cfg, err := NewConfigFromEnv()
+		if !tt.valid {
+			if err == nil {
+				t.Errorf("Expected an error for %q", tt.value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", tt.value, err)
+		}
+		if cfg.ExportTimeout != tt.expected {
+			t.Errorf("Expected %s for %q, got %s", tt.expected, tt.value, cfg.ExportTimeout)
+		}
+	}
+}
+
+func TestParseOtlpHeaders(t *testing.T) {
+	tests := []struct {
+		headers  string
+		expected map[string]string
+		valid    bool
+	}{
+		{headers: "", expected: map[string]string{}, valid: true},
+		{headers: "api-key=abc", expected: map[string]string{"api-key": "abc"}, valid: true},
+		{
+			headers:  " api-key = abc , authorization=Basic%20dXNlcjpwYXNz ",
+			expected: map[string]string{"api-key": "abc", "authorization": "Basic dXNlcjpwYXNz"},
+			valid:    true,
+		},
+		{headers: "token=a=b", expected: map[string]string{"token": "a=b"}, valid: true},
+		{headers: "api-key", valid: false},
+		{headers: "=abc", valid: false},
+		{headers: "api-key=%zz", valid: false},
+	}
+	for _, tt := range tests {
+		parsed, err := ParseOtlpHeaders(tt.headers)
+		if !tt.valid {
+			if err == nil {
+				t.Errorf("Expected an error for %q", tt.headers)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", tt.headers, err)
+		}
+		if !reflect.DeepEqual(parsed, tt.expected) {
+			t.Errorf("Expected %v for %q, got %v", tt.expected, tt.headers, parsed)
+		}
+	}
+}
+
+func TestOtelInsecure(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "development")
+	tests := []struct {
+		value    string
+		expected bool
+		valid    bool
+	}{
+		{value: "", expected: true, valid: true},
+		{value: "false", expected: false, valid: true},
+		{value: "true", expected: true, valid: true},
+		{value: "sometimes", valid: false},
+	}
+	for _, tt := range tests {
+		t.Setenv("OTEL_EXPORTER_OTLP_INSECURE", tt.value)
+		cfg, err := NewConfigFromEnv()
+		if !tt.valid {
+			if err == nil {
+				t.Errorf("Expected an error for %q", tt.value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", tt.value, err)
+		}
+		if cfg.OtelInsecure != tt.expected {
+			t.Errorf("Expected insecure %t for %q, got %t", tt.expected, tt.value, cfg.OtelInsecure)
+		}
+	}
+}
+
+func TestOtelProbe(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "development")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROBE", "")
+	cfg, err := NewConfigFromEnv()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.OtelProbe {
+		t.Error("Expected the probe to be off by default")
+	}
+	t.Setenv("OTEL_EXPORTER_OTLP_PROBE", "1")
+	if cfg, err := NewConfigFromEnv(); err != nil || !cfg.OtelProbe {
+		t.Errorf("Expected the probe on, got %+v (%v)", cfg, err)
+	}
+	t.Setenv("OTEL_EXPORTER_OTLP_PROBE", "maybe")
+	if _, err := NewConfigFromEnv(); err == nil {
+		t.Error("Expected an error for a value that isn't a bool")
+	}
+}
+
+func TestOtelSampleRatio(t *testing.T) {
+	tests := []struct {
+		environment string
+		value       string
+		expected    float64
+		valid       bool
+	}{
+		{environment: "development", value: "", expected: 1, valid: true},
+		{environment: "production", value: "", expected: 0.1, valid: true},
+		{environment: "production", value: "0.25", expected: 0.25, valid: true},
+		{environment: "production", value: "0", expected: 0, valid: true},
+		{environment: "production", value: "1.5", valid: false},
+		{environment: "production", value: "-0.1", valid: false},
+		{environment: "production", value: "NaN", valid: false},
+		{environment: "production", value: "half", valid: false},
+	}
+	for _, tt := range tests {
+		t.Setenv("ENVIRONMENT", tt.environment)
+		t.Setenv("OTEL_TRACES_SAMPLER_ARG", tt.value)
+		cfg, err := NewConfigFromEnv()
+		if !tt.valid {
+			if err == nil {
+				t.Errorf("Expected an error for %q", tt.value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", tt.value, err)
+		}
+		if cfg.OtelSampleRatio != tt.expected {
+			t.Errorf("Expected ratio %v in %s for %q, got %v", tt.expected, tt.environment, tt.value, cfg.OtelSampleRatio)
+		}
+	}
+}
+
+func TestOtelProtocol(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "development")
+	tests := []struct {
+		endpoint string
+		value    string
+		expected string
+		valid    bool
+	}{
+		{endpoint: "", value: "", expected: OtelProtocolHTTP, valid: true},
+		{endpoint: "tempo:4317", value: "", expected: OtelProtocolGRPC, valid: true},
+		{endpoint: "http://tempo:4317", value: "", expected: OtelProtocolGRPC, valid: true},
+		{endpoint: "https://otlp.example.com", value: "", expected: OtelProtocolHTTP, valid: true},
+		{endpoint: "otlp.example.com:443", value: "", expected: OtelProtocolGRPC, valid: true},
+		{endpoint: "otlp.example.com:4318", value: "", expected: OtelProtocolHTTP, valid: true},
+		{endpoint: "tempo:4317", value: "http/protobuf", expected: OtelProtocolHTTP, valid: true},
+		{endpoint: "http://localhost:4318", value: "grpc", expected: OtelProtocolGRPC, valid: true},
+		{endpoint: "tempo:4317", value: "http/json", valid: false},
+	}
+	for _, tt := range tests {
+		t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", tt.endpoint)
+		t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", tt.value)
+		cfg, err := NewConfigFromEnv()
+		if !tt.valid {
+			if err == nil {
+				t.Errorf("Expected an error for %q", tt.value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", tt.value, err)
+		}
+		if cfg.OtelProtocol != tt.expected {
+			t.Errorf("Expected protocol %s for %q with %q, got %s", tt.expected, tt.endpoint, tt.value, cfg.OtelProtocol)
+		}
+	}
+}
+
+func TestMetricsExporter(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "development")
+	tests := []struct {
+		exporter     string
+		host         string
+		port         string
+		expected     string
+		expectedAddr string
+		valid        bool
+	}{
+		{expected: MetricsExporterOTLP, expectedAddr: "localhost:9464", valid: true},
+		{exporter: "prometheus", expected: MetricsExporterPrometheus, expectedAddr: "localhost:9464", valid: true},
+		{exporter: "prometheus", host: "0.0.0.0", port: "9090", expected: MetricsExporterPrometheus, expectedAddr: "0.0.0.0:9090", valid: true},
+		{exporter: "prometheus", host: "::", expected: MetricsExporterPrometheus, expectedAddr: "[::]:9464", valid: true},
+		{exporter: "otlp", expected: MetricsExporterOTLP, expectedAddr: "localhost:9464", valid: true},
+		{exporter: "statsd", valid: false},
+		{exporter: "prometheus", port: "metrics", valid: false},
+		{exporter: "prometheus", port: "70000", valid: false},
+	}
+	for _, tt := range tests {
+		t.Setenv("OTEL_METRICS_EXPORTER", tt.exporter)
+		t.Setenv("OTEL_EXPORTER_PROMETHEUS_HOST", tt.host)
+		t.Setenv("OTEL_EXPORTER_PROMETHEUS_PORT", tt.port)
+		cfg, err := NewConfigFromEnv()
+		if !tt.valid {
+			if err == nil {
+				t.Errorf("Expected an error for %q on port %q", tt.exporter, tt.port)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", tt.exporter, err)
+		}
+		if cfg.MetricsExporter != tt.expected || cfg.PrometheusAddr != tt.expectedAddr {
+			t.Errorf("Expected %s on %s, got %s on %s", tt.expected, tt.expectedAddr, cfg.MetricsExporter, cfg.PrometheusAddr)
+		}
+	}
+}
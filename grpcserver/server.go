@@ -0,0 +1,100 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"turionspace/nei-mission-planner/scheduler/scheduler"
+	"turionspace/nei-mission-planner/scheduler/schedulerpb"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// instrumentationName names the tracer the RPC spans come from
+const instrumentationName = "scheduler_grpc"
+
+// Server implements schedulerpb.SchedulerServer on top of a Scheduler
+type Server struct {
+	schedulerpb.UnimplementedSchedulerServer
+	scheduler *scheduler.Scheduler
+	logger    *otelzap.Logger
+}
+
+func NewServer(s *scheduler.Scheduler, logger *otelzap.Logger) *Server {
+	return &Server{scheduler: s, logger: logger}
+}
+
+// metadataCarrier lets the text map propagator read trace headers out of gRPC metadata
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Schedule runs FindBestScheduleContext over the request's tasks. Invalid tasks are
+// InvalidArgument, mandatory tasks that can't all fit FailedPrecondition.
+func (s *Server) Schedule(ctx context.Context, request *schedulerpb.ScheduleRequest) (*schedulerpb.ScheduleResponse, error) {
+	// Continue the caller's trace if they sent one, so the FindBestSchedule span hangs off
+	// this call's span
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	}
+	ctx, span := otel.GetTracerProvider().Tracer(instrumentationName).Start(ctx, schedulerpb.Scheduler_Schedule_FullMethodName, trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	chosen, totalPriority, rejected, err := s.scheduler.FindBestScheduleContext(ctx, schedulerpb.TasksFromProto(request.GetTasks()))
+	if err != nil {
+		span.RecordError(err)
+		s.logger.Ctx(ctx).Warn("Schedule RPC failed", zap.Error(err))
+		return nil, statusFromError(err)
+	}
+	response := &schedulerpb.ScheduleResponse{
+		ChosenTasks:   schedulerpb.TasksToProto(chosen),
+		RejectedTasks: make([]*schedulerpb.RejectedTask, len(rejected)),
+		TotalPriority: totalPriority,
+	}
+	for i, task := range rejected {
+		response.RejectedTasks[i] = schedulerpb.RejectedTaskToProto(task)
+	}
+	return response, nil
+}
+
+// statusFromError maps scheduler errors onto gRPC status codes
+func statusFromError(err error) error {
+	var invalid scheduler.ErrInvalidTask
+	var cycle scheduler.ErrDependencyCycle
+	var tie scheduler.ErrAmbiguousTie
+	var infeasible scheduler.ErrInfeasible
+	switch {
+	case errors.As(err, &invalid), errors.As(err, &cycle), errors.As(err, &tie):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.As(err, &infeasible):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+var Module = fx.Provide(NewServer)
@@ -0,0 +1,127 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+	"turionspace/nei-mission-planner/scheduler/scheduler"
+	"turionspace/nei-mission-planner/scheduler/schedulerpb"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Helper function to serve a Server over an in-memory connection and return a client for it
+func newTestClient(t *testing.T) schedulerpb.SchedulerClient {
+	t.Helper()
+	logger := otelzap.New(zap.NewNop())
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	schedulerpb.RegisterSchedulerServer(server, NewServer(scheduler.NewScheduler(scheduler.SchedulerConfig{Logger: logger}), logger))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return schedulerpb.NewSchedulerClient(conn)
+}
+
+func TestSchedule(t *testing.T) {
+	client := newTestClient(t)
+	base := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	tasks := []scheduler.Task{
+		{ID: "a", StartTime: base, EndTime: base.Add(2 * time.Hour), Priority: 5},
+		{ID: "b", StartTime: base.Add(time.Hour), EndTime: base.Add(3 * time.Hour), Priority: 3},
+	}
+	response, err := client.Schedule(context.Background(), &schedulerpb.ScheduleRequest{Tasks: schedulerpb.TasksToProto(tasks)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.GetTotalPriority() != 5 {
+		t.Errorf("Expected total priority 5, got %.2f", response.GetTotalPriority())
+	}
+	if chosen := response.GetChosenTasks(); len(chosen) != 1 || chosen[0].GetId() != "a" {
+		t.Errorf("Expected task a chosen, got %v", chosen)
+	}
+	rejected := response.GetRejectedTasks()
+	if len(rejected) != 1 {
+		t.Fatalf("Expected one rejection, got %v", rejected)
+	}
+	if back := schedulerpb.RejectedTaskFromProto(rejected[0]); back.TaskRejected.ID != "b" || back.Reason != scheduler.RejectionReasonLowPriority {
+		t.Errorf("Expected b rejected as low priority, got %+v", back)
+	}
+
+	// An empty request is an empty schedule
+	if response, err := client.Schedule(context.Background(), &schedulerpb.ScheduleRequest{}); err != nil || len(response.GetChosenTasks()) != 0 {
+		t.Errorf("Expected an empty schedule, got %v (%v)", response, err)
+	}
+}
+
+func TestStatusFromError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected codes.Code
+	}{
+		{name: "Invalid task", err: scheduler.ErrInvalidTask{}, expected: codes.InvalidArgument},
+		{name: "Dependency cycle", err: scheduler.ErrDependencyCycle{TaskIDs: []string{"a", "b", "a"}}, expected: codes.InvalidArgument},
+		{name: "Ambiguous tie", err: scheduler.ErrAmbiguousTie{First: 0, Second: 1}, expected: codes.InvalidArgument},
+		{name: "Infeasible", err: scheduler.ErrInfeasible{TaskIDs: []string{"a"}}, expected: codes.FailedPrecondition},
+		{name: "Cancelled", err: context.Canceled, expected: codes.Canceled},
+		{name: "Anything else", err: errors.New("boom"), expected: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := status.Code(statusFromError(tt.err)); code != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, code)
+			}
+		})
+	}
+}
+
+func TestScheduleErrors(t *testing.T) {
+	client := newTestClient(t)
+	base := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		tasks    []scheduler.Task
+		expected codes.Code
+	}{
+		{
+			name:     "Invalid task",
+			tasks:    []scheduler.Task{{ID: "backwards", StartTime: base, EndTime: base.Add(-time.Hour), Priority: 1}},
+			expected: codes.InvalidArgument,
+		},
+		{
+			name: "Infeasible",
+			tasks: []scheduler.Task{
+				{ID: "a", StartTime: base, EndTime: base.Add(2 * time.Hour), Priority: 1, Mandatory: true},
+				{ID: "b", StartTime: base.Add(time.Hour), EndTime: base.Add(3 * time.Hour), Priority: 1, Mandatory: true},
+			},
+			expected: codes.FailedPrecondition,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Schedule(context.Background(), &schedulerpb.ScheduleRequest{Tasks: schedulerpb.TasksToProto(tt.tasks)})
+			if status.Code(err) != tt.expected {
+				t.Errorf("Expected %s, got %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"turionspace/nei-mission-planner/scheduler/scheduler"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// instrumentationName names the tracer the HTTP spans come from
+const instrumentationName = "scheduler_http"
+
+// maxRequestBytes caps how much of a request body is read, well past any real batch of tasks
+const maxRequestBytes = 32 << 20
+
+// Handler serves the scheduler over HTTP
+type Handler struct {
+	scheduler *scheduler.Scheduler
+	logger    *otelzap.Logger
+	mux       *http.ServeMux
+}
+
+func NewHandler(s *scheduler.Scheduler, logger *otelzap.Logger) *Handler {
+	h := &Handler{scheduler: s, logger: logger, mux: http.NewServeMux()}
+	h.mux.HandleFunc("POST /schedule", h.schedule)
+	return h
+}
+
+// ServeHTTP routes the request, see the handler methods for each endpoint
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// errorResponse is the body of every non-2xx response, Fields lists what's wrong with each
+// task when the request didn't pass scheduler.ValidateTaskJSON
+type errorResponse struct {
+	Error  string                 `json:"error"`
+	Fields []scheduler.FieldError `json:"fields,omitempty"`
+}
+
+// schedule handles POST /schedule: a JSON array of tasks (times in RFC3339) in, a
+// ScheduleOutput out. Invalid tasks are a 400, mandatory tasks that can't all fit a 422.
+func (h *Handler) schedule(w http.ResponseWriter, r *http.Request) {
+	// Continue the caller's trace if they sent one, so the FindBestSchedule span hangs off
+	// this request's span and that off theirs
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	ctx, span := otel.GetTracerProvider().Tracer(instrumentationName).Start(ctx, "POST /schedule", trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+	logger := h.logger.Ctx(ctx)
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	if err != nil {
+		span.RecordError(err)
+		logger.Warn("Failed to read schedule request", zap.Error(err))
+		h.writeJSON(w, span, http.StatusBadRequest, errorResponse{Error: "failed to read the request: " + err.Error()})
+		return
+	}
+	// Check the payload's structure first so the client hears about every bad field at once
+	var invalidJSON scheduler.ErrInvalidTaskJSON
+	if err := scheduler.ValidateTaskJSON(body); errors.As(err, &invalidJSON) {
+		span.RecordError(err)
+		logger.Warn("Invalid schedule request", zap.Error(err))
+		h.writeJSON(w, span, http.StatusBadRequest, errorResponse{Error: err.Error(), Fields: invalidJSON.Fields})
+		return
+	}
+	var tasks []scheduler.Task
+	if err := json.Unmarshal(body, &tasks); err != nil {
+		span.RecordError(err)
+		logger.Warn("Malformed schedule request", zap.Error(err))
+		h.writeJSON(w, span, http.StatusBadRequest, errorResponse{Error: "malformed request, expected a JSON array of tasks: " + err.Error()})
+		return
+	}
+
+	chosen, totalPriority, rejected, err := h.scheduler.FindBestScheduleContext(ctx, tasks)
+	if err != nil {
+		span.RecordError(err)
+		status := http.StatusInternalServerError
+		var invalid scheduler.ErrInvalidTask
+		var cycle scheduler.ErrDependencyCycle
+		var tie scheduler.ErrAmbiguousTie
+		var infeasible scheduler.ErrInfeasible
+		switch {
+		case errors.As(err, &invalid), errors.As(err, &cycle), errors.As(err, &tie):
+			status = http.StatusBadRequest
+		case errors.As(err, &infeasible):
+			status = http.StatusUnprocessableEntity
+		}
+		h.writeJSON(w, span, status, errorResponse{Error: err.Error()})
+		return
+	}
+	h.writeJSON(w, span, http.StatusOK, scheduler.NewScheduleOutput(tasks, chosen, totalPriority, rejected))
+}
+
+// writeJSON writes body as the JSON response with the given status
+func (h *Handler) writeJSON(w http.ResponseWriter, span trace.Span, status int, body any) {
+	span.SetAttributes(attribute.Int("http.response.status_code", status))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.Warn("Failed to write response", zap.Error(err))
+	}
+}
+
+var Module = fx.Provide(NewHandler)
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"turionspace/nei-mission-planner/scheduler/scheduler"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+)
+
+// Helper function to create a handler that logs nowhere
+func newTestHandler() *Handler {
+	logger := otelzap.New(zap.NewNop())
+	return NewHandler(scheduler.NewScheduler(scheduler.SchedulerConfig{Logger: logger}), logger)
+}
+
+// Helper function to send a request to the handler
+func post(t *testing.T, h *Handler, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	return recorder
+}
+
+func TestSchedule(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedChosen []string
+	}{
+		{
+			name: "Valid tasks",
+			body: `[
+				{"id": "a", "start_time": "2024-01-01T09:00:00Z", "end_time": "2024-01-01T11:00:00Z", "priority": 5},
+				{"id": "b", "start_time": "2024-01-01T10:00:00Z", "end_time": "2024-01-01T12:00:00Z", "priority": 3},
+				{"id": "c", "start_time": "2024-01-01T11:00:00Z", "end_time": "2024-01-01T12:00:00Z", "priority": 1}
+			]`,
+			expectedStatus: http.StatusOK,
+			expectedChosen: []string{"a", "c"},
+		},
+		{
+			name: "Timezone offsets",
+			body: `[
+				{"id": "a", "start_time": "2024-01-01T04:00:00-05:00", "end_time": "2024-01-01T11:00:00+01:00", "priority": 5},
+				{"id": "b", "start_time": "2024-01-01T15:30:00+05:30", "end_time": "2024-01-01T11:00:00Z", "priority": 3}
+			]`,
+			expectedStatus: http.StatusOK,
+			expectedChosen: []string{"a", "b"},
+		},
+		{
+			name:           "Empty array",
+			body:           `[]`,
+			expectedStatus: http.StatusOK,
+			expectedChosen: []string{},
+		},
+		{
+			name:           "Malformed JSON",
+			body:           `[{"id": "a", "start_time": `,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Not RFC3339",
+			body:           `[{"id": "a", "start_time": "9am", "end_time": "10am", "priority": 1}]`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid task",
+			body:           `[{"id": "a", "start_time": "2024-01-01T11:00:00Z", "end_time": "2024-01-01T09:00:00Z", "priority": 1}]`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Dependency cycle",
+			body: `[
+				{"id": "a", "start_time": "2024-01-01T09:00:00Z", "end_time": "2024-01-01T10:00:00Z", "priority": 1, "depends_on": ["b"]},
+				{"id": "b", "start_time": "2024-01-01T11:00:00Z", "end_time": "2024-01-01T12:00:00Z", "priority": 1, "depends_on": ["a"]}
+			]`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := post(t, newTestHandler(), "/schedule", tt.body)
+			if response.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, response.Code, response.Body)
+			}
+			if response.Header().Get("Content-Type") != "application/json" {
+				t.Errorf("Expected a JSON response, got %q", response.Header().Get("Content-Type"))
+			}
+			if tt.expectedStatus != http.StatusOK {
+				var body errorResponse
+				if err := json.NewDecoder(response.Body).Decode(&body); err != nil || body.Error == "" {
+					t.Errorf("Expected an error message, got %s (%v)", response.Body, err)
+				}
+				return
+			}
+			var output scheduler.ScheduleOutput
+			if err := json.NewDecoder(response.Body).Decode(&output); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if output.ChosenTasks == nil || output.RejectedTasks == nil {
+				t.Error("Expected empty lists rather than null")
+			}
+			if len(output.ChosenTasks) != len(tt.expectedChosen) {
+				t.Fatalf("Expected %v chosen, got %+v", tt.expectedChosen, output.ChosenTasks)
+			}
+			for i, id := range tt.expectedChosen {
+				if output.ChosenTasks[i].ID != id {
+					t.Errorf("Expected task %d to be %s, got %s", i, id, output.ChosenTasks[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestScheduleFieldErrors(t *testing.T) {
+	body := `[{"id": "a", "start_time": "9am", "priority": "high"}]`
+	response := post(t, newTestHandler(), "/schedule", body)
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, response.Code)
+	}
+	var decoded errorResponse
+	if err := json.NewDecoder(response.Body).Decode(&decoded); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	fields := make([]string, 0)
+	for _, field := range decoded.Fields {
+		fields = append(fields, field.Field)
+	}
+	if strings.Join(fields, ",") != "start_time,end_time,priority" {
+		t.Errorf("Expected problems with start_time, end_time and priority, got %+v", decoded.Fields)
+	}
+}
+
+func TestScheduleMethodNotAllowed(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	newTestHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/schedule", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, recorder.Code)
+	}
+}
+
+func TestScheduleSpanParent(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	body := `[{"id": "a", "start_time": "2024-01-01T09:00:00Z", "end_time": "2024-01-01T10:00:00Z", "priority": 1}]`
+	if response := post(t, newTestHandler(), "/schedule", body); response.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", response.Code)
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	request, ok := spans["POST /schedule"]
+	if !ok {
+		t.Fatal("Expected a span for the request")
+	}
+	schedule, ok := spans["FindBestSchedule"]
+	if !ok {
+		t.Fatal("Expected a FindBestSchedule span")
+	}
+	if schedule.Parent().SpanID() != request.SpanContext().SpanID() {
+		t.Error("Expected FindBestSchedule to be a child of the request span")
+	}
+}
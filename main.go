@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"time"
@@ -12,22 +13,127 @@ import (
 
 	"github.com/uptrace/opentelemetry-go-extra/otelzap"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
 	"go.uber.org/zap"
 )
 
-func runTest(
-	shutdowner fx.Shutdowner,
+// cliFlags are the command line flags main parses
+type cliFlags struct {
+	// input is the file to read tasks from, empty runs the built-in demo tasks
+	input string
+	// output is where the schedule is written as JSON
+	output string
+	// format is the input file's format, json (a list of tasks) or csv (see LoadTasksCSV)
+	format string
+}
+
+// parseFlags reads the command line flags from args, which excludes the program name
+func parseFlags(args []string) (cliFlags, error) {
+	var flags cliFlags
+	set := flag.NewFlagSet("scheduler", flag.ContinueOnError)
+	set.StringVar(&flags.input, "input", "", "file to read tasks from, the built-in demo tasks are used if empty")
+	set.StringVar(&flags.output, "output", "output.json", "file to write the schedule to as JSON")
+	set.StringVar(&flags.format, "format", "json", "format of the input file, json or csv")
+	if err := set.Parse(args); err != nil {
+		return cliFlags{}, err
+	}
+	if flags.format != "json" && flags.format != "csv" {
+		return cliFlags{}, fmt.Errorf("unknown format %q, expected json or csv", flags.format)
+	}
+	return flags, nil
+}
+
+// readTasks loads tasks from path in the given format
+func readTasks(path, format string) ([]scheduler.Task, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if format == "csv" {
+		return scheduler.LoadTasksCSV(file)
+	}
+	var tasks []scheduler.Task
+	if err := json.NewDecoder(file).Decode(&tasks); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return tasks, nil
+}
+
+// runDemo schedules the tasks from flags (or the demo tasks) with the injected Scheduler,
+// prints a report (see scheduler.RenderText) and writes the schedule out. It runs inside a
+// span of its own, so the scheduler's spans, logs and metrics all show up under one trace for
+// the run.
+func runDemo(
 	cfg *config.Config,
-	logger *otelzap.Logger, schedulerGenerator *scheduler.Scheduler) {
-	ctx, span := otel.GetTracerProvider().Tracer("run_test").Start(context.Background(), "HelloHandler")
+	flags cliFlags,
+	logger *otelzap.Logger, schedulerGenerator *scheduler.Scheduler) error {
+	ctx, span := otel.Tracer("scheduler_demo").Start(context.Background(), "runDemo")
 	defer span.End()
+	span.SetAttributes(attribute.String("environment", cfg.Environment), attribute.String("input_file", flags.input))
+	loggerWithCtx := logger.Ctx(ctx)
+
+	tasks := demoTasks()
+	if flags.input != "" {
+		var err error
+		if tasks, err = readTasks(flags.input, flags.format); err != nil {
+			span.RecordError(err)
+			loggerWithCtx.Error("Failed to read tasks", zap.String("input_file", flags.input), zap.Error(err))
+			return err
+		}
+	}
+	loggerWithCtx.Info("Starting scheduler", zap.Int("num_tasks", len(tasks)))
+
+	chosenTasks, totalPriority, rejectedTasks, err := schedulerGenerator.FindBestScheduleContext(ctx, tasks)
+	if err != nil {
+		span.RecordError(err)
+		loggerWithCtx.Error("Scheduler failed", zap.Error(err))
+		return err
+	}
+	output := scheduler.NewScheduleOutput(tasks, chosenTasks, totalPriority, rejectedTasks)
+	span.SetAttributes(attribute.Int("num_chosen_tasks", len(chosenTasks)), attribute.Float64("total_priority", totalPriority))
+	for _, task := range chosenTasks {
+		loggerWithCtx.Debug("Scheduled task",
+			zap.String("task_id", task.ID),
+			zap.Time("start_time", task.StartTime),
+			zap.Time("end_time", task.EndTime),
+			zap.Float64("priority", task.Priority))
+	}
+
+	// Print results in a nice format
+	fmt.Println()
+	if err := scheduler.RenderText(output, os.Stdout); err != nil {
+		return fmt.Errorf("failed to print the schedule: %w", err)
+	}
+
+	// Convert to JSON
+	jsonData, err := json.MarshalIndent(output, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the schedule: %w", err)
+	}
+
+	// save to file
+	if err := os.WriteFile(flags.output, jsonData, 0644); err != nil {
+		err = fmt.Errorf("failed to write %s: %w", flags.output, err)
+		span.RecordError(err)
+		return err
+	}
+	loggerWithCtx.Info("Scheduler completed",
+		zap.String("output_file", flags.output),
+		zap.Int("num_chosen_tasks", len(chosenTasks)),
+		zap.Int("num_rejected_tasks", len(rejectedTasks)),
+		zap.Float64("total_priority", totalPriority))
+	return nil
+}
+
+// demoTasks is the built-in task list used when no -input is given, a working day of
+// overlapping tasks starting at 9:00
+func demoTasks() []scheduler.Task {
 	// Create a fixed start time for better readability
 	baseTime := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
-	loggerWithCtx := logger.Ctx(ctx)
-	// Log the start time
-	loggerWithCtx.Info("Starting scheduler", zap.String("start_time", baseTime.Format(time.RFC3339)))
-	tasks := []scheduler.Task{
+	return []scheduler.Task{
 		// Morning Tasks (9:00 - 12:00)
 		{
 			StartTime: baseTime,                    // 9:00
@@ -115,100 +221,25 @@ func runTest(
 			Priority:  7.0,                         // Instant task 2 (same time)
 		},
 	}
+}
 
-	chosenTasks, totalPriority, rejectedTasks := schedulerGenerator.FindBestSchedule(tasks)
-
-	// Print results in a nice format
-	// fmt.Println("\n🗓️  Optimal Schedule:")
-	// fmt.Println("------------------------------------------------")
-	// for _, task := range chosenTasks {
-	// 	fmt.Printf("   Start: %s\n", task.StartTime.Format("15:04"))
-	// 	fmt.Printf("   End: %s\n", task.EndTime.Format("15:04"))
-	// 	fmt.Printf("   Priority: %.1f\n", task.Priority)
-	// 	fmt.Println("------------------------------------------------")
-	// }
-	// fmt.Printf("\n📊 Total Priority Score: %.1f\n", totalPriority)
-
-	// // Print some statistics
-	// fmt.Printf("\n📈 Schedule Statistics:")
-	// fmt.Printf("\n   Total Tasks Available: %d", len(tasks))
-	// fmt.Printf("\n   Tasks Scheduled: %d", len(chosenTasks))
-	// fmt.Printf("\n   Time Span: %s - %s\n",
-	// 	baseTime.Format("15:04"),
-	// 	baseTime.Add(8*time.Hour).Format("15:04"))
-	// Create sets for easy lookup of chosen tasks
-	chosenMap := make(map[time.Time]bool)
-	for _, task := range chosenTasks {
-		chosenMap[task.StartTime] = true
-	}
-
-	// Prepare rejected tasks
-	rejectedTasksJSON := make([]scheduler.Task, 0)
-	for _, task := range rejectedTasks {
-		rejectedTasksJSON = append(rejectedTasksJSON, task.TaskRejected)
-	}
-
-	// Convert to output format
-	chosenOutput := make([]scheduler.TaskOutput, len(chosenTasks))
-	for i, task := range chosenTasks {
-		chosenOutput[i] = scheduler.TaskOutput{
-			StartTime:      task.StartTime.Format(time.RFC3339),
-			EndTime:        task.EndTime.Format(time.RFC3339),
-			Priority:       task.Priority,
-			DurationMins:   int(task.EndTime.Sub(task.StartTime).Minutes()),
-			IsZeroDuration: !task.EndTime.After(task.StartTime),
-		}
-	}
-
-	rejectedOutput := make([]scheduler.TaskOutput, len(rejectedTasksJSON))
-	for i, task := range rejectedTasksJSON {
-		rejectedOutput[i] = scheduler.TaskOutput{
-			StartTime:      task.StartTime.Format(time.RFC3339),
-			EndTime:        task.EndTime.Format(time.RFC3339),
-			Priority:       task.Priority,
-			DurationMins:   int(task.EndTime.Sub(task.StartTime).Minutes()),
-			IsZeroDuration: !task.EndTime.After(task.StartTime),
-		}
-	}
-	// Create final output structure
-	output := scheduler.ScheduleOutput{
-		ChosenTasks:   chosenOutput,
-		RejectedTasks: rejectedOutput,
-		TotalPriority: totalPriority,
-		Statistics: scheduler.Statistics{
-			TotalTasks:     len(tasks),
-			ScheduledTasks: len(chosenTasks),
-			RejectedTasks:  len(rejectedTasksJSON),
-		},
-		TimeRange: scheduler.TimeRange{
-			Start: baseTime.Format(time.RFC3339),
-			End:   baseTime.Add(8 * time.Hour).Format(time.RFC3339),
-		},
-	}
-
-	// Convert to JSON
-	jsonData, err := json.MarshalIndent(output, "", "    ")
-	if err != nil {
-		fmt.Printf("Error marshaling JSON: %v\n", err)
-		return
-	}
-
-	// save to file
-	err = os.WriteFile("output.json", jsonData, 0644)
+func main() {
+	flags, err := parseFlags(os.Args[1:])
 	if err != nil {
-		fmt.Printf("Error writing JSON to file: %v\n", err)
-		return
+		fmt.Printf("Invalid flags: %v\n", err)
+		os.Exit(2)
 	}
-	loggerWithCtx.Info("Scheduler completed", zap.String("output_file", "output.json"))
 
-}
-
-func main() {
 	app := fx.New(
+		// fx reports its own startup and shutdown through the app's logger too
+		fx.WithLogger(func(logger *otelzap.Logger) fxevent.Logger {
+			return &fxevent.ZapLogger{Logger: logger.Logger}
+		}),
+		fx.Supply(flags),
 		config.Module,
 		observability.Module,
 		scheduler.Module,
-		fx.Invoke(runTest),
+		fx.Invoke(runDemo),
 	)
 
 	startCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
@@ -2,28 +2,97 @@ package observability
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"time"
+	"io"
+	"net"
+	"net/url"
+	"strings"
 	"turionspace/nei-mission-planner/scheduler/config"
 
-	"github.com/davecgh/go-spew/spew"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/uptrace/opentelemetry-go-extra/otelzap"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/metric"
 	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
+// Version is the version of the build, reported as service.version. Set it at build time with
+// -ldflags "-X turionspace/nei-mission-planner/scheduler/observability.Version=v1.2.3".
+var Version = "dev"
+
+// InitStage is the step of setting up OpenTelemetry that an ObservabilityInitError came from
+type InitStage string
+
+const (
+	// InitStageEndpoint is parsing OtelEndpoint
+	InitStageEndpoint InitStage = "endpoint"
+	// InitStageProtocol is picking the exporters for OtelProtocol
+	InitStageProtocol InitStage = "protocol"
+	// InitStageDialProbe is the OtelProbe connection check, it's only ever logged
+	InitStageDialProbe InitStage = "dial probe"
+	// The exporter stages are creating each signal's exporter
+	InitStageTraceExporter  InitStage = "trace exporter"
+	InitStageLogExporter    InitStage = "log exporter"
+	InitStageMetricExporter InitStage = "metric exporter"
+	// InitStageResource is describing the service to the backend
+	InitStageResource InitStage = "resource"
+	// InitStageMetricsServer is listening on PrometheusAddr for /metrics
+	InitStageMetricsServer InitStage = "metrics server"
+)
+
+// ObservabilityInitError is returned by NewTelemetryProviders (and so fails fx startup) when
+// OpenTelemetry can't be set up, saying which stage failed and against which collector. The
+// underlying error is wrapped, so errors.Is and errors.As still see it.
+type ObservabilityInitError struct {
+	Stage    InitStage
+	Endpoint string
+	Protocol string
+	Err      error
+}
+
+func (e ObservabilityInitError) Error() string {
+	return fmt.Sprintf("telemetry %s failed for OTLP endpoint %q (%s): %v", e.Stage, e.Endpoint, e.Protocol, e.Err)
+}
+
+func (e ObservabilityInitError) Unwrap() error {
+	return e.Err
+}
+
+// initError wraps err from stage in an ObservabilityInitError for cfg's collector
+func initError(cfg *config.Config, stage InitStage, err error) error {
+	protocol := cfg.OtelProtocol
+	if protocol == "" {
+		protocol = config.OtelProtocolGRPC
+	}
+	return ObservabilityInitError{Stage: stage, Endpoint: cfg.OtelEndpoint, Protocol: protocol, Err: err}
+}
+
 type telemetryProviders struct {
-	tp      *sdktrace.TracerProvider
-	lp      *sdklog.LoggerProvider
-	cleanup func()
+	tp *sdktrace.TracerProvider
+	lp *sdklog.LoggerProvider
+	mp *sdkmetric.MeterProvider
+	// registry holds the metrics for the /metrics endpoint, nil unless they go to Prometheus
+	registry *prometheus.Registry
+	cleanup  func()
 }
 
 // NewLogging creates a new logging instance without any fx lifecycle bindings
@@ -40,74 +109,291 @@ func NewLogging(cfg *config.Config) (*otelzap.Logger, error) {
 	return otelLogger, nil
 }
 
-// NewTelemetryProviders initializes OpenTelemetry providers
-func NewTelemetryProviders(cfg *config.Config) (*telemetryProviders, error) {
-	spew.Dump(cfg)
-	cleanup, tp, lp, err := initOpenTelemetry(cfg)
+// configFields describes the config for logging, header values are left out since they're
+// usually credentials
+func configFields(cfg *config.Config) []zap.Field {
+	headers := make(map[string]string, len(cfg.OtelExporterOtlpHeaders))
+	for key := range cfg.OtelExporterOtlpHeaders {
+		headers[key] = "REDACTED"
+	}
+	return []zap.Field{
+		zap.String("environment", cfg.Environment),
+		zap.String("otel_endpoint", cfg.OtelEndpoint),
+		zap.String("otel_protocol", cfg.OtelProtocol),
+		zap.String("service_name", cfg.ServiceName),
+		zap.String("log_level", cfg.LogLevel),
+		zap.Int("batch_size", cfg.BatchSize),
+		zap.Duration("export_timeout", cfg.ExportTimeout),
+		zap.Bool("otel_insecure", cfg.OtelInsecure),
+		zap.Float64("otel_sample_ratio", cfg.OtelSampleRatio),
+		zap.Bool("otel_probe", cfg.OtelProbe),
+		zap.Any("otel_headers", headers),
+		zap.String("metrics_exporter", cfg.MetricsExporter),
+		zap.String("prometheus_addr", cfg.PrometheusAddr),
+	}
+}
+
+// NewTelemetryProviders initializes OpenTelemetry providers, failing with an
+// ObservabilityInitError
+func NewTelemetryProviders(cfg *config.Config, logger *otelzap.Logger) (*telemetryProviders, error) {
+	logger.Debug("Initializing telemetry", configFields(cfg)...)
+	return initOpenTelemetry(cfg, logger)
+}
+
+// NewMeterProvider exposes the meter provider so modules like the scheduler can record
+// metrics through the same OTLP exporter config
+func NewMeterProvider(providers *telemetryProviders) metric.MeterProvider {
+	return providers.mp
+}
+
+// probeEndpoint checks the OTLP endpoint at host can be reached, blocking for up to the export
+// timeout. gRPC gets a full connection, HTTP only a TCP one since there's nothing to ask the
+// collector before exporting. A failure comes back as an ObservabilityInitError for the caller
+// to log, the exporters retry on their own so a collector that isn't up yet is fine.
+func probeEndpoint(cfg *config.Config, host string, dialCredentials credentials.TransportCredentials) error {
+	var conn io.Closer
+	var err error
+	if cfg.OtelProtocol == config.OtelProtocolHTTP {
+		conn, err = net.DialTimeout("tcp", host, cfg.ExportTimeout)
+	} else {
+		conn, err = grpc.Dial(
+			host,
+			grpc.WithTransportCredentials(dialCredentials),
+			grpc.WithBlock(),
+			grpc.WithTimeout(cfg.ExportTimeout),
+		)
+	}
 	if err != nil {
+		return initError(cfg, InitStageDialProbe, err)
+	}
+	conn.Close()
+	return nil
+}
+
+// newResource describes this process to the telemetry backend: the service, its version and
+// environment, and the host and process it runs in. OTEL_RESOURCE_ATTRIBUTES can add more.
+func newResource(ctx context.Context, cfg *config.Config) (*sdkresource.Resource, error) {
+	res, err := sdkresource.New(ctx,
+		sdkresource.WithSchemaURL(semconv.SchemaURL),
+		sdkresource.WithAttributes(
+			semconv.ServiceName(cfg.ServiceName),
+			semconv.ServiceVersion(Version),
+			semconv.DeploymentEnvironment(cfg.Environment),
+		),
+		sdkresource.WithFromEnv(),
+		sdkresource.WithTelemetrySDK(),
+		sdkresource.WithHost(),
+		sdkresource.WithProcessPID(),
+		sdkresource.WithProcessExecutableName(),
+		sdkresource.WithProcessRuntimeName(),
+		sdkresource.WithProcessRuntimeVersion(),
+	)
+	// A detector that comes up short still leaves a usable resource with everything else
+	if err != nil && !errors.Is(err, sdkresource.ErrPartialResource) {
 		return nil, err
 	}
+	return res, nil
+}
 
-	return &telemetryProviders{
-		tp:      tp,
-		lp:      lp,
-		cleanup: cleanup,
-	}, nil
+// otlpTarget splits OtelEndpoint into the host:port the exporters connect to and any base path
+// in front of it, which the HTTP exporters put ahead of their /v1/<signal> paths. A bare
+// host:port has no base path.
+func otlpTarget(endpoint string) (host, basePath string, err error) {
+	if !strings.Contains(endpoint, "://") {
+		return endpoint, "", nil
+	}
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid OTLP endpoint %q: %w", endpoint, err)
+	}
+	return parsed.Host, strings.TrimSuffix(parsed.Path, "/"), nil
 }
 
-func initOpenTelemetry(cfg *config.Config) (cleanup func(), tp *sdktrace.TracerProvider, lp *sdklog.LoggerProvider, err error) {
-	ctx := context.Background()
-	// Test connection before creating exporter
-	conn, err := grpc.Dial(
-		cfg.OtelEndpoint,
-		grpc.WithInsecure(),
-		grpc.WithBlock(),
-		grpc.WithTimeout(5*time.Second),
+// otlpExporters are the exporters for each signal, sent to the same endpoint. metric is nil
+// when metrics are served to Prometheus instead.
+type otlpExporters struct {
+	trace  sdktrace.SpanExporter
+	log    sdklog.Exporter
+	metric sdkmetric.Exporter
+}
+
+// newGRPCExporters creates exporters speaking OTLP over gRPC
+func newGRPCExporters(ctx context.Context, cfg *config.Config, host string, dialCredentials credentials.TransportCredentials) (exporters otlpExporters, err error) {
+	// Plaintext is only for talking to a local collector, anything else gets TLS verified
+	// against the system's root certificates
+	traceSecurity := otlptracegrpc.WithInsecure()
+	logSecurity := otlploggrpc.WithInsecure()
+	metricSecurity := otlpmetricgrpc.WithInsecure()
+	if !cfg.OtelInsecure {
+		traceSecurity = otlptracegrpc.WithTLSCredentials(dialCredentials)
+		logSecurity = otlploggrpc.WithTLSCredentials(dialCredentials)
+		metricSecurity = otlpmetricgrpc.WithTLSCredentials(dialCredentials)
+	}
+	exporters.trace, err = otlptracegrpc.New(ctx,
+		traceSecurity,
+		otlptracegrpc.WithEndpoint(host),
+		otlptracegrpc.WithHeaders(cfg.OtelExporterOtlpHeaders),
 	)
 	if err != nil {
-		fmt.Printf("Warning: Failed to connect to OTLP endpoint: %v\n", err)
-	} else {
-		conn.Close()
-		fmt.Printf("Successfully connected to OTLP endpoint\n")
+		return otlpExporters{}, initError(cfg, InitStageTraceExporter, err)
 	}
-	// Initialize OTLP trace exporter
-	traceExporter, err := otlptracegrpc.New(ctx,
-		otlptracegrpc.WithInsecure(), // TODO: make secure for production
-		otlptracegrpc.WithEndpoint(cfg.OtelEndpoint),
+	exporters.log, err = otlploggrpc.New(ctx,
+		logSecurity,
+		otlploggrpc.WithEndpoint(host),
+		otlploggrpc.WithHeaders(cfg.OtelExporterOtlpHeaders),
 	)
 	if err != nil {
-		return nil, nil, nil, err
+		return otlpExporters{}, initError(cfg, InitStageLogExporter, err)
 	}
-
-	// Initialize OTLP log exporter
-	logExporter, err := otlploggrpc.New(ctx,
-		otlploggrpc.WithInsecure(),
-		otlploggrpc.WithEndpoint(cfg.OtelEndpoint),
+	if cfg.MetricsExporter == config.MetricsExporterPrometheus {
+		return exporters, nil
+	}
+	exporters.metric, err = otlpmetricgrpc.New(ctx,
+		metricSecurity,
+		otlpmetricgrpc.WithEndpoint(host),
+		otlpmetricgrpc.WithHeaders(cfg.OtelExporterOtlpHeaders),
 	)
 	if err != nil {
-		return nil, nil, nil, err
+		return otlpExporters{}, initError(cfg, InitStageMetricExporter, err)
+	}
+	return exporters, nil
+}
+
+// newHTTPExporters creates exporters speaking OTLP over HTTP with protobuf bodies, each
+// posting to its signal's path under the endpoint's base path
+func newHTTPExporters(ctx context.Context, cfg *config.Config, host, basePath string) (exporters otlpExporters, err error) {
+	// The HTTP exporters use TLS with the system's root certificates unless told otherwise
+	traceOptions := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(host),
+		otlptracehttp.WithURLPath(basePath + "/v1/traces"),
+		otlptracehttp.WithHeaders(cfg.OtelExporterOtlpHeaders),
+	}
+	logOptions := []otlploghttp.Option{
+		otlploghttp.WithEndpoint(host),
+		otlploghttp.WithURLPath(basePath + "/v1/logs"),
+		otlploghttp.WithHeaders(cfg.OtelExporterOtlpHeaders),
+	}
+	metricOptions := []otlpmetrichttp.Option{
+		otlpmetrichttp.WithEndpoint(host),
+		otlpmetrichttp.WithURLPath(basePath + "/v1/metrics"),
+		otlpmetrichttp.WithHeaders(cfg.OtelExporterOtlpHeaders),
+	}
+	if cfg.OtelInsecure {
+		traceOptions = append(traceOptions, otlptracehttp.WithInsecure())
+		logOptions = append(logOptions, otlploghttp.WithInsecure())
+		metricOptions = append(metricOptions, otlpmetrichttp.WithInsecure())
+	}
+	exporters.trace, err = otlptracehttp.New(ctx, traceOptions...)
+	if err != nil {
+		return otlpExporters{}, initError(cfg, InitStageTraceExporter, err)
+	}
+	exporters.log, err = otlploghttp.New(ctx, logOptions...)
+	if err != nil {
+		return otlpExporters{}, initError(cfg, InitStageLogExporter, err)
+	}
+	if cfg.MetricsExporter == config.MetricsExporterPrometheus {
+		return exporters, nil
+	}
+	exporters.metric, err = otlpmetrichttp.New(ctx, metricOptions...)
+	if err != nil {
+		return otlpExporters{}, initError(cfg, InitStageMetricExporter, err)
+	}
+	return exporters, nil
+}
+
+func initOpenTelemetry(cfg *config.Config, logger *otelzap.Logger) (*telemetryProviders, error) {
+	ctx := context.Background()
+	host, basePath, err := otlpTarget(cfg.OtelEndpoint)
+	if err != nil {
+		return nil, initError(cfg, InitStageEndpoint, err)
+	}
+	dialCredentials := insecure.NewCredentials()
+	if !cfg.OtelInsecure {
+		dialCredentials = credentials.NewClientTLSFromCert(nil, "")
+	}
+	if cfg.OtelProbe {
+		if err := probeEndpoint(cfg, host, dialCredentials); err != nil {
+			logger.Warn("Failed to connect to OTLP endpoint", zap.String("endpoint", cfg.OtelEndpoint), zap.Error(err))
+		} else {
+			logger.Info("Successfully connected to OTLP endpoint", zap.String("endpoint", cfg.OtelEndpoint))
+		}
+	}
+
+	var exporters otlpExporters
+	switch cfg.OtelProtocol {
+	case config.OtelProtocolHTTP:
+		exporters, err = newHTTPExporters(ctx, cfg, host, basePath)
+	case config.OtelProtocolGRPC, "":
+		exporters, err = newGRPCExporters(ctx, cfg, host, dialCredentials)
+	default:
+		err = initError(cfg, InitStageProtocol, fmt.Errorf("unsupported OTLP protocol %q", cfg.OtelProtocol))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := newResource(ctx, cfg)
+	if err != nil {
+		return nil, initError(cfg, InitStageResource, err)
+	}
+
+	metricReader, registry, err := newMetricReader(cfg, exporters)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create trace provider
-	tp = sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(traceExporter,
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithResource(res),
+		sdktrace.WithBatcher(exporters.trace,
 			sdktrace.WithMaxExportBatchSize(cfg.BatchSize),
 		),
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.OtelSampleRatio))),
 	)
 
 	// Create log provider
-	lp = sdklog.NewLoggerProvider(
+	lp := sdklog.NewLoggerProvider(
+		sdklog.WithResource(res),
 		sdklog.WithProcessor(
-			sdklog.NewBatchProcessor(logExporter),
+			sdklog.NewBatchProcessor(exporters.log),
 		),
 	)
 
+	// Create meter provider
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(res),
+		sdkmetric.WithReader(metricReader),
+	)
+
 	// Set global providers
 	otel.SetTracerProvider(tp)
+	otel.SetMeterProvider(mp)
 	global.SetLoggerProvider(lp)
 
-	return func() {}, tp, lp, nil
+	return &telemetryProviders{
+		tp:       tp,
+		lp:       lp,
+		mp:       mp,
+		registry: registry,
+		cleanup:  func() {},
+	}, nil
+}
+
+// newMetricReader picks how metrics leave the process: pushed to the OTLP endpoint every so
+// often, or collected into a registry of their own whenever Prometheus scrapes /metrics. The
+// registry is only returned for Prometheus.
+func newMetricReader(cfg *config.Config, exporters otlpExporters) (sdkmetric.Reader, *prometheus.Registry, error) {
+	if cfg.MetricsExporter != config.MetricsExporterPrometheus {
+		return sdkmetric.NewPeriodicReader(exporters.metric), nil, nil
+	}
+	// A registry of our own rather than the default one keeps the Go runtime collectors other
+	// libraries register there off the endpoint
+	registry := prometheus.NewRegistry()
+	exporter, err := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+	if err != nil {
+		return nil, nil, initError(cfg, InitStageMetricExporter, err)
+	}
+	return exporter, registry, nil
 }
 
 func initLogger(cfg *config.Config) (*zap.Logger, error) {
@@ -0,0 +1,190 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"turionspace/nei-mission-planner/scheduler/config"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestConfigFieldsRedactHeaders(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	cfg := &config.Config{
+		Environment:             "production",
+		OtelEndpoint:            "otlp.example.com:4317",
+		OtelExporterOtlpHeaders: map[string]string{"authorization": "Bearer secret-token"},
+	}
+	zap.New(core).Debug("Initializing telemetry", configFields(cfg)...)
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected one log entry, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["otel_endpoint"] != cfg.OtelEndpoint {
+		t.Errorf("Expected the endpoint to be logged, got %v", fields["otel_endpoint"])
+	}
+	headers, ok := fields["otel_headers"].(map[string]string)
+	if !ok || headers["authorization"] != "REDACTED" {
+		t.Errorf("Expected the header name with its value redacted, got %v", fields["otel_headers"])
+	}
+	if strings.Contains(fmt.Sprint(fields), "secret-token") {
+		t.Errorf("Expected the token to be left out, got %v", fields)
+	}
+}
+
+func TestNewResource(t *testing.T) {
+	previous := Version
+	defer func() { Version = previous }()
+	Version = "v1.2.3"
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "team=planning")
+
+	res, err := newResource(context.Background(), &config.Config{Environment: "staging", ServiceName: "planner"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	attributes := make(map[attribute.Key]string)
+	for _, kv := range res.Attributes() {
+		attributes[kv.Key] = kv.Value.Emit()
+	}
+	expected := map[attribute.Key]string{
+		semconv.ServiceNameKey:           "planner",
+		semconv.ServiceVersionKey:        "v1.2.3",
+		semconv.DeploymentEnvironmentKey: "staging",
+		"team":                           "planning",
+	}
+	for key, value := range expected {
+		if attributes[key] != value {
+			t.Errorf("Expected %s=%s, got %q", key, value, attributes[key])
+		}
+	}
+	for _, key := range []attribute.Key{semconv.HostNameKey, semconv.ProcessPIDKey} {
+		if attributes[key] == "" {
+			t.Errorf("Expected %s to be detected", key)
+		}
+	}
+}
+
+func TestOtlpTarget(t *testing.T) {
+	tests := []struct {
+		endpoint         string
+		expectedHost     string
+		expectedBasePath string
+	}{
+		{endpoint: "tempo:4317", expectedHost: "tempo:4317"},
+		{endpoint: "http://localhost:4318", expectedHost: "localhost:4318"},
+		{endpoint: "https://otlp.example.com/", expectedHost: "otlp.example.com"},
+		{endpoint: "https://otlp.example.com/otlp/", expectedHost: "otlp.example.com", expectedBasePath: "/otlp"},
+	}
+	for _, tt := range tests {
+		host, basePath, err := otlpTarget(tt.endpoint)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", tt.endpoint, err)
+		}
+		if host != tt.expectedHost || basePath != tt.expectedBasePath {
+			t.Errorf("Expected %q and %q for %q, got %q and %q", tt.expectedHost, tt.expectedBasePath, tt.endpoint, host, basePath)
+		}
+	}
+}
+
+func TestHTTPExporters(t *testing.T) {
+	paths := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths <- r.Method + " " + r.URL.Path + " " + r.Header.Get("Content-Type")
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	cfg := &config.Config{OtelEndpoint: server.URL + "/otlp", OtelProtocol: config.OtelProtocolHTTP, OtelInsecure: true}
+	host, basePath, err := otlpTarget(cfg.OtelEndpoint)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	exporters, err := newHTTPExporters(ctx, cfg, host, basePath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	span := tracetest.SpanStub{Name: "FindBestSchedule"}.Snapshot()
+	if err := exporters.trace.ExportSpans(ctx, []sdktrace.ReadOnlySpan{span}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got, expected := <-paths, "POST /otlp/v1/traces application/x-protobuf"; got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	for _, shutdown := range []func(context.Context) error{exporters.trace.Shutdown, exporters.log.Shutdown, exporters.metric.Shutdown} {
+		if err := shutdown(ctx); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+}
+
+func TestObservabilityInitError(t *testing.T) {
+	tests := []struct {
+		name          string
+		cfg           *config.Config
+		expectedStage InitStage
+	}{
+		{
+			name:          "Unsupported protocol",
+			cfg:           &config.Config{OtelEndpoint: "localhost:4317", OtelProtocol: "http/json"},
+			expectedStage: InitStageProtocol,
+		},
+		{
+			name:          "Malformed endpoint",
+			cfg:           &config.Config{OtelEndpoint: "http://[::1", OtelProtocol: config.OtelProtocolHTTP},
+			expectedStage: InitStageEndpoint,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := initOpenTelemetry(tt.cfg, otelzap.New(zap.NewNop()))
+			var initErr ObservabilityInitError
+			if !errors.As(err, &initErr) {
+				t.Fatalf("Expected ObservabilityInitError, got %v", err)
+			}
+			if initErr.Stage != tt.expectedStage || initErr.Endpoint != tt.cfg.OtelEndpoint || initErr.Protocol != tt.cfg.OtelProtocol {
+				t.Errorf("Expected stage %q for %q, got %+v", tt.expectedStage, tt.cfg.OtelEndpoint, initErr)
+			}
+			if initErr.Err == nil || !strings.Contains(err.Error(), initErr.Err.Error()) {
+				t.Errorf("Expected the message to include the underlying error, got %q", err)
+			}
+		})
+	}
+}
+
+func TestProbeEndpointError(t *testing.T) {
+	// Nothing listens on a port that was just closed
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	host := listener.Addr().String()
+	listener.Close()
+
+	cfg := &config.Config{OtelEndpoint: "http://" + host, OtelProtocol: config.OtelProtocolHTTP, ExportTimeout: time.Second}
+	err = probeEndpoint(cfg, host, nil)
+	var initErr ObservabilityInitError
+	if !errors.As(err, &initErr) || initErr.Stage != InitStageDialProbe {
+		t.Fatalf("Expected a dial probe ObservabilityInitError, got %v", err)
+	}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		t.Errorf("Expected the dial error to be wrapped, got %v", err)
+	}
+}
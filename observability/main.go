@@ -2,7 +2,7 @@ package observability
 
 import (
 	"context"
-	"time"
+	"turionspace/nei-mission-planner/scheduler/config"
 
 	"github.com/uptrace/opentelemetry-go-extra/otelzap"
 	"go.uber.org/fx"
@@ -10,10 +10,10 @@ import (
 )
 
 // RegisterHooks registers the lifecycle hooks for telemetry providers
-func RegisterHooks(lc fx.Lifecycle, providers *telemetryProviders, logging *otelzap.Logger) {
+func RegisterHooks(lc fx.Lifecycle, cfg *config.Config, providers *telemetryProviders, logging *otelzap.Logger) {
 	lc.Append(fx.Hook{
 		OnStop: func(ctx context.Context) error {
-			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			ctx, cancel := context.WithTimeout(ctx, cfg.ExportTimeout)
 			defer cancel()
 			err := providers.lp.ForceFlush(ctx)
 			if err != nil {
@@ -23,6 +23,10 @@ func RegisterHooks(lc fx.Lifecycle, providers *telemetryProviders, logging *otel
 			if err != nil {
 				logging.Logger.Error("failed to flush trace provider", zap.Error(err))
 			}
+			err = providers.mp.ForceFlush(ctx)
+			if err != nil {
+				logging.Logger.Error("failed to flush meter provider", zap.Error(err))
+			}
 			providers.cleanup()
 			if err := providers.tp.Shutdown(ctx); err != nil {
 				logging.Logger.Error("failed to shutdown trace provider", zap.Error(err))
@@ -30,6 +34,9 @@ func RegisterHooks(lc fx.Lifecycle, providers *telemetryProviders, logging *otel
 			if err := providers.lp.Shutdown(ctx); err != nil {
 				logging.Logger.Error("failed to shutdown log provider", zap.Error(err))
 			}
+			if err := providers.mp.Shutdown(ctx); err != nil {
+				logging.Logger.Error("failed to shutdown meter provider", zap.Error(err))
+			}
 			return nil
 		},
 	})
@@ -39,6 +46,8 @@ var Module = fx.Module("observability",
 	fx.Provide(
 		NewLogging,
 		NewTelemetryProviders,
+		NewMeterProvider,
+		NewMetricsServer,
 	),
-	fx.Invoke(RegisterHooks),
+	fx.Invoke(RegisterHooks, RegisterMetricsServer),
 )
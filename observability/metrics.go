@@ -0,0 +1,64 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+	"turionspace/nei-mission-planner/scheduler/config"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// metricsServer serves /metrics for Prometheus to scrape, server is nil when metrics go over
+// OTLP and there's nothing to serve
+type metricsServer struct {
+	server *http.Server
+}
+
+// NewMetricsServer sets up the /metrics endpoint on PrometheusAddr when MetricsExporter is
+// Prometheus, RegisterMetricsServer starts and stops it with the app
+func NewMetricsServer(cfg *config.Config, providers *telemetryProviders) *metricsServer {
+	if providers.registry == nil {
+		return &metricsServer{}
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(providers.registry, promhttp.HandlerOpts{}))
+	return &metricsServer{server: &http.Server{
+		Addr:              cfg.PrometheusAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}}
+}
+
+// RegisterMetricsServer listens for scrapes once the app starts, failing startup if the address
+// can't be bound, and shuts the server down when it stops
+func RegisterMetricsServer(lc fx.Lifecycle, cfg *config.Config, metrics *metricsServer, logging *otelzap.Logger) {
+	if metrics.server == nil {
+		return
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			listener, err := net.Listen("tcp", metrics.server.Addr)
+			if err != nil {
+				return initError(cfg, InitStageMetricsServer, err)
+			}
+			// The address actually bound, for a port of 0
+			metrics.server.Addr = listener.Addr().String()
+			logging.Logger.Info("Serving Prometheus metrics", zap.String("addr", metrics.server.Addr))
+			go func() {
+				if err := metrics.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					logging.Logger.Error("metrics server failed", zap.Error(err))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return metrics.server.Shutdown(ctx)
+		},
+	})
+}
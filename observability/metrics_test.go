@@ -0,0 +1,86 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"turionspace/nei-mission-planner/scheduler/config"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+)
+
+func TestMetricsServer(t *testing.T) {
+	cfg := &config.Config{
+		OtelEndpoint:    "localhost:4317",
+		OtelProtocol:    config.OtelProtocolGRPC,
+		OtelInsecure:    true,
+		MetricsExporter: config.MetricsExporterPrometheus,
+		PrometheusAddr:  "127.0.0.1:0",
+	}
+	logger := otelzap.New(zap.NewNop())
+	providers, err := initOpenTelemetry(cfg, logger)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	counter, err := providers.mp.Meter("scheduler").Int64Counter("scheduler_runs")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	counter.Add(context.Background(), 3)
+
+	metrics := NewMetricsServer(cfg, providers)
+	lifecycle := fxtest.NewLifecycle(t)
+	RegisterMetricsServer(lifecycle, cfg, metrics, logger)
+	lifecycle.RequireStart()
+	defer lifecycle.RequireStop()
+
+	response, err := http.Get("http://" + metrics.server.Addr + "/metrics")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.StatusCode != http.StatusOK || !strings.Contains(string(body), "scheduler_runs_total") {
+		t.Errorf("Expected the counter to be served, got %d: %s", response.StatusCode, body)
+	}
+}
+
+func TestMetricsServerOff(t *testing.T) {
+	cfg := &config.Config{OtelEndpoint: "localhost:4317", OtelProtocol: config.OtelProtocolGRPC, OtelInsecure: true, MetricsExporter: config.MetricsExporterOTLP}
+	providers, err := initOpenTelemetry(cfg, otelzap.New(zap.NewNop()))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if providers.registry != nil {
+		t.Error("Expected no Prometheus registry with OTLP metrics")
+	}
+	if metrics := NewMetricsServer(cfg, providers); metrics.server != nil {
+		t.Errorf("Expected no metrics server with OTLP metrics, got %+v", metrics.server)
+	}
+}
+
+func TestMetricsServerAddressInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer listener.Close()
+
+	cfg := &config.Config{MetricsExporter: config.MetricsExporterPrometheus, PrometheusAddr: listener.Addr().String()}
+	metrics := &metricsServer{server: &http.Server{Addr: cfg.PrometheusAddr}}
+	lifecycle := fxtest.NewLifecycle(t)
+	RegisterMetricsServer(lifecycle, cfg, metrics, otelzap.New(zap.NewNop()))
+	var initErr ObservabilityInitError
+	if err := lifecycle.Start(context.Background()); !errors.As(err, &initErr) || initErr.Stage != InitStageMetricsServer {
+		t.Errorf("Expected a metrics server ObservabilityInitError, got %v", err)
+	}
+}
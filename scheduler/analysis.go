@@ -0,0 +1,231 @@
+package scheduler
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Bounds parses the RFC3339 start and end of the range
+func (tr TimeRange) Bounds() (time.Time, time.Time, error) {
+	start, err := time.Parse(time.RFC3339, tr.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid time range start: %w", err)
+	}
+	end, err := time.Parse(time.RFC3339, tr.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid time range end: %w", err)
+	}
+	return start, end, nil
+}
+
+// newTimeRange formats a pair of times as a TimeRange
+func newTimeRange(start, end time.Time) TimeRange {
+	return TimeRange{Start: start.Format(time.RFC3339), End: end.Format(time.RFC3339)}
+}
+
+// busyIntervals clamps the tasks to [windowStart, windowEnd] and merges overlapping or
+// back-to-back ones, returning them in order. Zero duration tasks take up no time.
+func busyIntervals(tasks []Task, windowStart, windowEnd time.Time) [][2]time.Time {
+	intervals := make([][2]time.Time, 0, len(tasks))
+	for _, task := range tasks {
+		start, end := task.StartTime, task.EndTime
+		if start.Before(windowStart) {
+			start = windowStart
+		}
+		if end.After(windowEnd) {
+			end = windowEnd
+		}
+		if end.After(start) {
+			intervals = append(intervals, [2]time.Time{start, end})
+		}
+	}
+	sort.Slice(intervals, func(first, second int) bool {
+		return intervals[first][0].Before(intervals[second][0])
+	})
+
+	merged := make([][2]time.Time, 0, len(intervals))
+	for _, interval := range intervals {
+		if last := len(merged) - 1; last >= 0 && !interval[0].After(merged[last][1]) {
+			if interval[1].After(merged[last][1]) {
+				merged[last][1] = interval[1]
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+	return merged
+}
+
+// ComputeStatistics works out how packed a schedule is over window. UtilizedMinutes is the time
+// inside the window covered by at least one chosen task, so tasks overlapping on different
+// resources count once and zero duration tasks add nothing, and tasks hanging over either edge
+// are clamped to it. UtilizationRatio is UtilizedMinutes over WindowMinutes, zero for an empty
+// or unparseable window. The value and cost totals cover every chosen task whatever the
+// window. Only the chosen tasks are known here, so TotalTasks and ScheduledTasks are both
+// len(chosen) and callers that have the input fill in the rest.
+func ComputeStatistics(chosen []Task, window TimeRange) Statistics {
+	statistics := Statistics{TotalTasks: len(chosen), ScheduledTasks: len(chosen)}
+	for _, task := range chosen {
+		statistics.TotalValue += task.Value
+		statistics.TotalCost += task.Cost
+	}
+	statistics.NetValue = statistics.TotalValue - statistics.TotalCost
+	windowStart, windowEnd, err := window.Bounds()
+	if err != nil || !windowEnd.After(windowStart) {
+		return statistics
+	}
+	var utilized time.Duration
+	for _, busy := range busyIntervals(chosen, windowStart, windowEnd) {
+		utilized += busy[1].Sub(busy[0])
+	}
+	statistics.UtilizedMinutes = utilized.Minutes()
+	statistics.WindowMinutes = windowEnd.Sub(windowStart).Minutes()
+	statistics.UtilizationRatio = statistics.UtilizedMinutes / statistics.WindowMinutes
+	return statistics
+}
+
+// StatisticsByTag totals the chosen and rejected tasks' priority for every tag they carry, e.g.
+// to show how much scheduled priority each customer got. A task counts towards each of its tags
+// once, even if it lists one twice, and untagged tasks aren't counted anywhere.
+func StatisticsByTag(chosen []Task, rejected []RejectedTask) map[string]TagStats {
+	byTag := make(map[string]TagStats)
+	add := func(task Task, wasChosen bool) {
+		for i, tag := range task.Tags {
+			if slices.Contains(task.Tags[:i], tag) {
+				continue
+			}
+			stats := byTag[tag]
+			if wasChosen {
+				stats.ChosenTasks++
+				stats.ChosenPriority += task.Priority
+			} else {
+				stats.RejectedTasks++
+				stats.RejectedPriority += task.Priority
+			}
+			byTag[tag] = stats
+		}
+	}
+	for _, task := range chosen {
+		add(task, true)
+	}
+	for _, rejection := range rejected {
+		add(rejection.TaskRejected, false)
+	}
+	return byTag
+}
+
+// ScheduleGaps returns the idle intervals in the window that no chosen task covers, in order.
+// Back-to-back and overlapping tasks are coalesced, and tasks are clamped to the window so a
+// task hanging over either edge only counts for the part inside it. An unparseable window
+// has no gaps.
+func ScheduleGaps(chosen []Task, window TimeRange) []TimeRange {
+	windowStart, windowEnd, err := window.Bounds()
+	if err != nil || !windowEnd.After(windowStart) {
+		return nil
+	}
+
+	gaps := make([]TimeRange, 0)
+	cursor := windowStart
+	for _, busy := range busyIntervals(chosen, windowStart, windowEnd) {
+		if busy[0].After(cursor) {
+			gaps = append(gaps, newTimeRange(cursor, busy[0]))
+		}
+		cursor = busy[1]
+	}
+	if windowEnd.After(cursor) {
+		gaps = append(gaps, newTimeRange(cursor, windowEnd))
+	}
+	return gaps
+}
+
+// ConcurrencyPoint is a change in how many tasks are running, Count holds from Time until the
+// next point
+type ConcurrencyPoint struct {
+	Time  time.Time `json:"time"`
+	Count int       `json:"count"`
+}
+
+// concurrencyEvent is a task starting or ending in ConcurrencyProfile's sweep. kind orders
+// events at the same instant: ends, then instants, then starts.
+type concurrencyEvent struct {
+	at    time.Time
+	kind  int
+	delta int
+}
+
+const (
+	concurrencyEnd = iota
+	concurrencyInstant
+	concurrencyStart
+)
+
+// ConcurrencyProfile sweeps over the tasks' start and end times and returns every point where
+// the number of running tasks changes, in order, starting from the first task. A task ending
+// and another starting at the same instant don't overlap, and a zero duration task only
+// overlaps tasks strictly containing its instant and other instants at the same time. An instant that raises the count shows up as a
+// point with it counted followed by a point at the same time without it.
+func ConcurrencyProfile(tasks []Task) []ConcurrencyPoint {
+	events := make([]concurrencyEvent, 0, 2*len(tasks))
+	for _, task := range tasks {
+		if !task.EndTime.After(task.StartTime) {
+			events = append(events, concurrencyEvent{at: task.StartTime, kind: concurrencyInstant, delta: 1})
+			continue
+		}
+		events = append(events,
+			concurrencyEvent{at: task.StartTime, kind: concurrencyStart, delta: 1},
+			concurrencyEvent{at: task.EndTime, kind: concurrencyEnd, delta: -1},
+		)
+	}
+	sort.Slice(events, func(first, second int) bool {
+		if !events[first].at.Equal(events[second].at) {
+			return events[first].at.Before(events[second].at)
+		}
+		return events[first].kind < events[second].kind
+	})
+
+	profile := make([]ConcurrencyPoint, 0)
+	count := 0
+	for i := 0; i < len(events); {
+		// Everything at the same instant is applied together so simultaneous starts are one
+		// point and a task handing over to the next isn't a dip, only instants get a point of
+		// their own
+		at := events[i].at
+		instants := 0
+		for ; i < len(events) && events[i].at.Equal(at); i++ {
+			if events[i].kind == concurrencyInstant {
+				instants++
+				continue
+			}
+			if events[i].kind == concurrencyStart && instants > 0 {
+				profile = appendConcurrency(profile, at, count+instants)
+				instants = 0
+			}
+			count += events[i].delta
+		}
+		if instants > 0 {
+			profile = appendConcurrency(profile, at, count+instants)
+		}
+		profile = appendConcurrency(profile, at, count)
+	}
+	return profile
+}
+
+// appendConcurrency adds a point to the profile unless the count hasn't changed
+func appendConcurrency(profile []ConcurrencyPoint, at time.Time, count int) []ConcurrencyPoint {
+	if last := len(profile) - 1; last >= 0 && profile[last].Count == count {
+		return profile
+	}
+	return append(profile, ConcurrencyPoint{Time: at, Count: count})
+}
+
+// MaxConcurrency is the most tasks running at any one instant, counted like
+// ConcurrencyProfile, e.g. to check a schedule fits the number of ground stations
+func MaxConcurrency(tasks []Task) int {
+	peak := 0
+	for _, point := range ConcurrencyProfile(tasks) {
+		peak = max(peak, point.Count)
+	}
+	return peak
+}
@@ -0,0 +1,260 @@
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScheduleGaps(t *testing.T) {
+	window := newTimeRange(fixedTime(8), fixedTime(17))
+	tests := []struct {
+		name     string
+		chosen   []Task
+		expected []TimeRange
+	}{
+		{
+			name:     "Empty schedule is one big gap",
+			chosen:   nil,
+			expected: []TimeRange{newTimeRange(fixedTime(8), fixedTime(17))},
+		},
+		{
+			name: "Back-to-back tasks are coalesced",
+			chosen: []Task{
+				{StartTime: fixedTime(9), EndTime: fixedTime(10)},
+				{StartTime: fixedTime(10), EndTime: fixedTime(11)},
+				{StartTime: fixedTime(13), EndTime: fixedTime(14)},
+			},
+			expected: []TimeRange{
+				newTimeRange(fixedTime(8), fixedTime(9)),
+				newTimeRange(fixedTime(11), fixedTime(13)),
+				newTimeRange(fixedTime(14), fixedTime(17)),
+			},
+		},
+		{
+			name: "Tasks are clamped to the window",
+			chosen: []Task{
+				{StartTime: fixedTime(6), EndTime: fixedTime(9)},
+				{StartTime: fixedTime(16), EndTime: fixedTime(19)},
+			},
+			expected: []TimeRange{newTimeRange(fixedTime(9), fixedTime(16))},
+		},
+		{
+			name: "Fully booked window has no gaps",
+			chosen: []Task{
+				{StartTime: fixedTime(8), EndTime: fixedTime(12)},
+				{StartTime: fixedTime(12), EndTime: fixedTime(17)},
+			},
+			expected: []TimeRange{},
+		},
+		{
+			name: "Zero duration tasks take no time",
+			chosen: []Task{
+				{StartTime: fixedTime(12), EndTime: fixedTime(12)},
+			},
+			expected: []TimeRange{newTimeRange(fixedTime(8), fixedTime(17))},
+		},
+		{
+			name: "Unordered input",
+			chosen: []Task{
+				{StartTime: fixedTime(15), EndTime: fixedTime(16).Add(30 * time.Minute)},
+				{StartTime: fixedTime(8), EndTime: fixedTime(9)},
+			},
+			expected: []TimeRange{
+				newTimeRange(fixedTime(9), fixedTime(15)),
+				newTimeRange(fixedTime(16).Add(30*time.Minute), fixedTime(17)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gaps := ScheduleGaps(tt.chosen, window)
+			if !reflect.DeepEqual(gaps, tt.expected) {
+				t.Errorf("Expected gaps %v, got %v", tt.expected, gaps)
+			}
+		})
+	}
+
+	if gaps := ScheduleGaps(nil, TimeRange{Start: "nonsense", End: "2024-01-01T17:00:00Z"}); gaps != nil {
+		t.Errorf("Expected no gaps for an invalid window, got %v", gaps)
+	}
+}
+
+func TestComputeStatistics(t *testing.T) {
+	window := newTimeRange(fixedTime(8), fixedTime(18))
+	tests := []struct {
+		name     string
+		chosen   []Task
+		window   TimeRange
+		expected Statistics
+	}{
+		{
+			name:     "Empty schedule",
+			chosen:   nil,
+			window:   window,
+			expected: Statistics{WindowMinutes: 600},
+		},
+		{
+			name: "Overlapping and zero duration tasks",
+			chosen: []Task{
+				{StartTime: fixedTime(9), EndTime: fixedTime(11)},
+				{StartTime: fixedTime(10), EndTime: fixedTime(12), ResourceID: "antenna-a"},
+				{StartTime: fixedTime(14), EndTime: fixedTime(14)},
+			},
+			window:   window,
+			expected: Statistics{TotalTasks: 3, ScheduledTasks: 3, UtilizedMinutes: 180, WindowMinutes: 600, UtilizationRatio: 0.3},
+		},
+		{
+			name: "Clamped to the window",
+			chosen: []Task{
+				{StartTime: fixedTime(6), EndTime: fixedTime(9)},
+				{StartTime: fixedTime(17), EndTime: fixedTime(20)},
+			},
+			window:   window,
+			expected: Statistics{TotalTasks: 2, ScheduledTasks: 2, UtilizedMinutes: 120, WindowMinutes: 600, UtilizationRatio: 0.2},
+		},
+		{
+			name: "Value and cost totals",
+			chosen: []Task{
+				{StartTime: fixedTime(9), EndTime: fixedTime(10), Value: 300, Cost: 100},
+				{StartTime: fixedTime(20), EndTime: fixedTime(21), Value: 50, Cost: 75},
+			},
+			window:   window,
+			expected: Statistics{TotalTasks: 2, ScheduledTasks: 2, UtilizedMinutes: 60, WindowMinutes: 600, UtilizationRatio: 0.1, TotalValue: 350, TotalCost: 175, NetValue: 175},
+		},
+		{
+			name:     "Unparseable window",
+			chosen:   []Task{{StartTime: fixedTime(9), EndTime: fixedTime(10)}},
+			window:   TimeRange{Start: "9am", End: "5pm"},
+			expected: Statistics{TotalTasks: 1, ScheduledTasks: 1},
+		},
+		{
+			name:     "Empty window",
+			chosen:   []Task{{StartTime: fixedTime(9), EndTime: fixedTime(10)}},
+			window:   newTimeRange(fixedTime(9), fixedTime(9)),
+			expected: Statistics{TotalTasks: 1, ScheduledTasks: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if statistics := ComputeStatistics(tt.chosen, tt.window); !reflect.DeepEqual(statistics, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, statistics)
+			}
+		})
+	}
+}
+
+func TestStatisticsByTag(t *testing.T) {
+	chosen := []Task{
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 5, Tags: []string{"acme", "nei-7"}},
+		{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 2, Tags: []string{"globex", "globex"}},
+		{ID: "untagged", StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 9},
+	}
+	rejected := []RejectedTask{
+		{TaskRejected: Task{ID: "c", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 3, Tags: []string{"acme"}}, Reason: RejectionReasonConflict, CausedByID: "a"},
+	}
+	expected := map[string]TagStats{
+		"acme":   {ChosenTasks: 1, ChosenPriority: 5, RejectedTasks: 1, RejectedPriority: 3},
+		"nei-7":  {ChosenTasks: 1, ChosenPriority: 5},
+		"globex": {ChosenTasks: 1, ChosenPriority: 2},
+	}
+	if byTag := StatisticsByTag(chosen, rejected); !reflect.DeepEqual(byTag, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, byTag)
+	}
+
+	tasks := append([]Task{rejected[0].TaskRejected}, chosen...)
+	if output := NewScheduleOutput(tasks, chosen, 16, rejected); !reflect.DeepEqual(output.Statistics.ByTag, expected) {
+		t.Errorf("Expected the output statistics to break down by tag, got %+v", output.Statistics.ByTag)
+	}
+	if output := NewScheduleOutput(chosen[2:], chosen[2:], 9, nil); output.Statistics.ByTag != nil {
+		t.Errorf("Expected no tag breakdown without tags, got %+v", output.Statistics.ByTag)
+	}
+}
+
+func TestConcurrencyProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		tasks       []Task
+		expected    []ConcurrencyPoint
+		expectedMax int
+	}{
+		{
+			name:        "No tasks",
+			tasks:       nil,
+			expected:    []ConcurrencyPoint{},
+			expectedMax: 0,
+		},
+		{
+			name: "Overlapping and back to back",
+			tasks: []Task{
+				{StartTime: fixedTime(9), EndTime: fixedTime(11)},
+				{StartTime: fixedTime(10), EndTime: fixedTime(12)},
+				{StartTime: fixedTime(11), EndTime: fixedTime(13)},
+				{StartTime: fixedTime(10), EndTime: fixedTime(11)},
+			},
+			expected: []ConcurrencyPoint{
+				{Time: fixedTime(9), Count: 1},
+				{Time: fixedTime(10), Count: 3},
+				{Time: fixedTime(11), Count: 2},
+				{Time: fixedTime(12), Count: 1},
+				{Time: fixedTime(13), Count: 0},
+			},
+			expectedMax: 3,
+		},
+		{
+			name: "Instants",
+			tasks: []Task{
+				{StartTime: fixedTime(9), EndTime: fixedTime(11)},
+				{StartTime: fixedTime(10), EndTime: fixedTime(10)},
+				{StartTime: fixedTime(11), EndTime: fixedTime(11)},
+				{StartTime: fixedTime(11), EndTime: fixedTime(12)},
+			},
+			expected: []ConcurrencyPoint{
+				{Time: fixedTime(9), Count: 1},
+				{Time: fixedTime(10), Count: 2},
+				{Time: fixedTime(10), Count: 1},
+				{Time: fixedTime(12), Count: 0},
+			},
+			expectedMax: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := ConcurrencyProfile(tt.tasks)
+			if len(profile) != len(tt.expected) {
+				t.Fatalf("Expected %+v, got %+v", tt.expected, profile)
+			}
+			for i := range profile {
+				if !profile[i].Time.Equal(tt.expected[i].Time) || profile[i].Count != tt.expected[i].Count {
+					t.Errorf("Expected point %d to be %+v, got %+v", i, tt.expected[i], profile[i])
+				}
+			}
+			if peak := MaxConcurrency(tt.tasks); peak != tt.expectedMax {
+				t.Errorf("Expected a peak of %d, got %d", tt.expectedMax, peak)
+			}
+		})
+	}
+}
+
+func TestMaxConcurrencyMultiResource(t *testing.T) {
+	tasks := make([]Task, 0)
+	for i := 0; i < 12; i++ {
+		start := fixedTime(9).Add(time.Duration(i*20) * time.Minute)
+		tasks = append(tasks, Task{StartTime: start, EndTime: start.Add(time.Hour), Priority: float64(1 + i%4)})
+	}
+	tasks = append(tasks, Task{StartTime: fixedTime(10), EndTime: fixedTime(10), Priority: 10})
+	schedules, _, _, err := newTestScheduler().FindBestScheduleMulti(tasks, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	chosen := make([]Task, 0)
+	for _, schedule := range schedules {
+		chosen = append(chosen, schedule...)
+	}
+	if peak := MaxConcurrency(chosen); peak != 2 {
+		t.Errorf("Expected two resources to be fully used, got a peak of %d", peak)
+	}
+}
@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxBundles caps how many bundles (with more than one task) a schedule can have, every
+// combination of them is solved separately so the work doubles with every bundle
+const maxBundles = 10
+
+// sharedBundles returns the BundleIDs shared by more than one task, in the order they first
+// appear
+func sharedBundles(tasks []Task) []string {
+	sizes := make(map[string]int)
+	order := make([]string, 0)
+	for _, task := range tasks {
+		if task.BundleID == "" {
+			continue
+		}
+		if sizes[task.BundleID] == 0 {
+			order = append(order, task.BundleID)
+		}
+		sizes[task.BundleID]++
+	}
+	bundles := make([]string, 0)
+	for _, bundle := range order {
+		if sizes[bundle] > 1 {
+			bundles = append(bundles, bundle)
+		}
+	}
+	return bundles
+}
+
+// scheduleBundles is schedule for tasks with bundles: either every task in a bundle is chosen
+// or none of them are. That can't be expressed in the interval DP, so every combination of
+// bundles is tried, solving with the chosen bundles' tasks made mandatory and the others
+// left out, and the best feasible one wins. Ties go to the combination with fewer bundles.
+func (s *Scheduler) scheduleBundles(ctx context.Context, span trace.Span, tasks []Task, bundles []string) ([]Task, float64, []RejectedTask, error) {
+	span.SetAttributes(attribute.Int("num_bundles", len(bundles)))
+	if len(bundles) > maxBundles {
+		return nil, 0, nil, fmt.Errorf("%d bundles with more than one task, at most %d are supported", len(bundles), maxBundles)
+	}
+	bundleBit := make(map[string]int, len(bundles))
+	for i, bundle := range bundles {
+		bundleBit[bundle] = 1 << i
+	}
+	// A bundle with a mandatory task in it has to be chosen whole
+	required := 0
+	mandatory := make(map[string]bool)
+	for _, task := range tasks {
+		if task.Mandatory {
+			required |= bundleBit[task.BundleID]
+			mandatory[task.ID] = true
+		}
+	}
+
+	var bestChosen []Task
+	var bestRejected []RejectedTask
+	var bestValue scheduleValue
+	bestPriority := 0.0
+	bestMask := -1
+	var requiredErr error
+	for mask := 0; mask < 1<<len(bundles); mask++ {
+		if mask&required != required {
+			continue
+		}
+		candidates := make([]Task, 0, len(tasks))
+		for _, task := range tasks {
+			bit := bundleBit[task.BundleID]
+			if bit != 0 && mask&bit == 0 {
+				continue
+			}
+			task.Mandatory = task.Mandatory || bit != 0
+			candidates = append(candidates, task)
+		}
+		chosenTasks, totalPriority, rejectedTasks, err := s.scheduleUnbundled(ctx, span, candidates)
+		var infeasible ErrInfeasible
+		if errors.As(err, &infeasible) {
+			// The bundles in this combination don't fit together, unless they're the ones
+			// we have no choice about that's fine
+			if mask == required {
+				requiredErr = err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		value := scheduleValue{}
+		for _, task := range chosenTasks {
+			value = value.plus(s.taskValue(task))
+		}
+		if bestMask == -1 || s.betterValue(value, bestValue) {
+			bestChosen, bestPriority, bestRejected, bestValue, bestMask = chosenTasks, totalPriority, rejectedTasks, value, mask
+		}
+	}
+	if bestMask == -1 {
+		return nil, 0, nil, requiredErr
+	}
+
+	// Only tasks that were mandatory to begin with should come back marked that way
+	for i := range bestChosen {
+		bestChosen[i].Mandatory = mandatory[bestChosen[i].ID]
+	}
+	// Bundles that were left out are rejected whole, blaming a chosen task one of their tasks
+	// would have conflicted with if there is one
+	for _, task := range tasks {
+		bit := bundleBit[task.BundleID]
+		if bit == 0 || bestMask&bit != 0 {
+			continue
+		}
+		rejected := RejectedTask{TaskRejected: task, Reason: RejectionReasonBundleInfeasible}
+		if conflicting := s.findConflictingChosenLinear(bestChosen, task); conflicting != -1 {
+			rejected.CausedByID = bestChosen[conflicting].ID
+		}
+		span.AddEvent("task_rejected", trace.WithAttributes(attribute.String("reason", rejected.Reason.String())))
+		bestRejected = append(bestRejected, rejected)
+	}
+	return bestChosen, bestPriority, bestRejected, nil
+}
@@ -0,0 +1,180 @@
+package scheduler
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestBundles(t *testing.T) {
+	tests := []struct {
+		name          string
+		tasks         []Task
+		expectedIDs   []string
+		expectedTotal float64
+		rejected      map[string]RejectedTask
+	}{
+		{
+			name: "Bundle beats a task worth more than either half",
+			tasks: []Task{
+				{ID: "uplink", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 3, BundleID: "experiment"},
+				{ID: "downlink", StartTime: fixedTime(14), EndTime: fixedTime(15), Priority: 3, BundleID: "experiment"},
+				{ID: "imaging", StartTime: fixedTime(14), EndTime: fixedTime(15), Priority: 5},
+			},
+			expectedIDs:   []string{"uplink", "downlink"},
+			expectedTotal: 6,
+			rejected: map[string]RejectedTask{
+				"imaging": {Reason: RejectionReasonConflict, CausedByID: "downlink"},
+			},
+		},
+		{
+			name: "Half a bundle is worth nothing",
+			tasks: []Task{
+				{ID: "uplink", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 3, BundleID: "experiment"},
+				{ID: "downlink", StartTime: fixedTime(14), EndTime: fixedTime(15), Priority: 3, BundleID: "experiment"},
+				{ID: "imaging", StartTime: fixedTime(14), EndTime: fixedTime(15), Priority: 7},
+			},
+			expectedIDs:   []string{"imaging"},
+			expectedTotal: 7,
+			rejected: map[string]RejectedTask{
+				"uplink":   {Reason: RejectionReasonBundleInfeasible},
+				"downlink": {Reason: RejectionReasonBundleInfeasible, CausedByID: "imaging"},
+			},
+		},
+		{
+			name: "Bundle that conflicts with itself",
+			tasks: []Task{
+				{ID: "uplink", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 3, BundleID: "experiment"},
+				{ID: "downlink", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 3, BundleID: "experiment"},
+				{ID: "imaging", StartTime: fixedTime(14), EndTime: fixedTime(15), Priority: 1},
+			},
+			expectedIDs:   []string{"imaging"},
+			expectedTotal: 1,
+			rejected: map[string]RejectedTask{
+				"uplink":   {Reason: RejectionReasonBundleInfeasible},
+				"downlink": {Reason: RejectionReasonBundleInfeasible},
+			},
+		},
+		{
+			name: "Mandatory task brings its bundle",
+			tasks: []Task{
+				{ID: "uplink", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1, BundleID: "experiment", Mandatory: true},
+				{ID: "downlink", StartTime: fixedTime(14), EndTime: fixedTime(15), Priority: 1, BundleID: "experiment"},
+				{ID: "imaging", StartTime: fixedTime(14), EndTime: fixedTime(15), Priority: 7},
+			},
+			expectedIDs:   []string{"uplink", "downlink"},
+			expectedTotal: 2,
+			rejected: map[string]RejectedTask{
+				"imaging": {Reason: RejectionReasonConflict, CausedByID: "downlink"},
+			},
+		},
+		{
+			name: "Bundle with a missed deadline",
+			tasks: []Task{
+				{ID: "uplink", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 3, BundleID: "experiment"},
+				{ID: "downlink", StartTime: fixedTime(14), EndTime: fixedTime(15), Priority: 3, BundleID: "experiment", Deadline: fixedTime(12)},
+			},
+			expectedTotal: 0,
+			rejected: map[string]RejectedTask{
+				"uplink":   {Reason: RejectionReasonBundleInfeasible, CausedByID: "downlink"},
+				"downlink": {Reason: RejectionReasonDeadlineMissed},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chosen, total, rejected, err := newTestScheduler().FindBestSchedule(tt.tasks)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if total != tt.expectedTotal {
+				t.Errorf("Expected total %.2f, got %.2f", tt.expectedTotal, total)
+			}
+			if len(chosen) != len(tt.expectedIDs) {
+				t.Fatalf("Expected %v, got %+v", tt.expectedIDs, chosen)
+			}
+			for i, id := range tt.expectedIDs {
+				if chosen[i].ID != id {
+					t.Errorf("Expected task %d to be %s, got %s", i, id, chosen[i].ID)
+				}
+				if chosen[i].Mandatory != (id == "uplink" && tt.tasks[0].Mandatory) {
+					t.Errorf("Expected %s to keep its Mandatory flag", id)
+				}
+			}
+			if len(rejected) != len(tt.rejected) {
+				t.Fatalf("Expected %d rejections, got %+v", len(tt.rejected), rejected)
+			}
+			for _, rejection := range rejected {
+				expected := tt.rejected[rejection.TaskRejected.ID]
+				if rejection.Reason != expected.Reason || rejection.CausedByID != expected.CausedByID {
+					t.Errorf("Expected %s rejected as %s caused by %q, got %s caused by %q", rejection.TaskRejected.ID, expected.Reason, expected.CausedByID, rejection.Reason, rejection.CausedByID)
+				}
+			}
+		})
+	}
+}
+
+func TestBundlesInfeasible(t *testing.T) {
+	tasks := []Task{
+		{ID: "uplink", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 1, BundleID: "experiment", Mandatory: true},
+		{ID: "downlink", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 1, BundleID: "experiment"},
+	}
+	_, _, _, err := newTestScheduler().FindBestSchedule(tasks)
+	var infeasible ErrInfeasible
+	if !errors.As(err, &infeasible) {
+		t.Errorf("Expected ErrInfeasible for a mandatory bundle that conflicts with itself, got %v", err)
+	}
+}
+
+func TestBundlesGreedy(t *testing.T) {
+	tasks := []Task{
+		{ID: "uplink", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 3, BundleID: "experiment"},
+		{ID: "downlink", StartTime: fixedTime(14), EndTime: fixedTime(15), Priority: 3, BundleID: "experiment"},
+		{ID: "imaging", StartTime: fixedTime(14), EndTime: fixedTime(15), Priority: 5},
+	}
+	chosen, total := newTestScheduler().FindScheduleGreedy(tasks)
+	if total != 6 || len(chosen) != 2 || chosen[0].ID != "uplink" || chosen[1].ID != "downlink" {
+		t.Errorf("Expected greedy to take the bundle worth 6, got %+v worth %.2f", chosen, total)
+	}
+}
+
+func FuzzBundlesMatchBruteForce(f *testing.F) {
+	f.Add(byte(0), []byte{0, 6, 5, 0, 3, 6, 8, 0, 7, 6, 4, 0, 100, 0, 2, 0, 200, 12, 9, 1})
+	f.Add(byte(19), []byte{5, 40, 200, 2, 6, 3, 100, 2, 9, 3, 100, 2, 12, 0, 1, 3, 12, 3, 7, 3})
+	f.Fuzz(func(t *testing.T, mode byte, data []byte) {
+		tasks := fuzzTasks(data)
+		if len(tasks) == 0 || len(tasks) > 12 {
+			return
+		}
+		for i := range tasks {
+			tasks[i].BundleID = []string{"", "a", "b", "", "c"}[i%5]
+			tasks[i].GroupID = []string{"", "x"}[i%2]
+		}
+		// Bundles on their own, or combined with a cap and exclusive groups
+		maxTasks := -1
+		opts := []Option{}
+		if mode&1 != 0 {
+			maxTasks = int(mode>>1) % 8
+			opts = append(opts, WithMaxTasks(maxTasks))
+		}
+		exclusiveGroups := mode&16 != 0
+		if exclusiveGroups {
+			opts = append(opts, WithExclusiveGroups())
+		}
+
+		chosen, total, rejected, err := newTestScheduler().FindBestSchedule(tasks, opts...)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := ValidateSchedule(chosen); err != nil {
+			t.Errorf("Schedule has conflicts: %v", err)
+		}
+		if expected := bruteForceConstrained(tasks, maxTasks, exclusiveGroups); math.Abs(expected-total) > 1e-9 {
+			t.Errorf("Expected total %v, got %v", expected, total)
+		}
+		if len(chosen)+len(rejected) != len(tasks) {
+			t.Errorf("Expected every task to be chosen or rejected, got %d chosen and %d rejected", len(chosen), len(rejected))
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// dayLayout is the format of the keys FindBestScheduleByDay returns
+const dayLayout = "2006-01-02"
+
+// DayResult is the schedule for one day from FindBestScheduleByDay, the same values
+// FindBestSchedule returns
+type DayResult struct {
+	ChosenTasks   []Task
+	TotalPriority float64
+	RejectedTasks []RejectedTask
+}
+
+// FindBestScheduleByDay buckets tasks by the date they start on in loc (UTC if it's nil) and
+// runs FindBestSchedule with opts over each day on its own. The result is keyed by date as
+// "2006-01-02". A task running past midnight belongs to the day it starts on and isn't checked
+// against the next day's tasks, so if that matters schedule the days together instead.
+// Invalid tasks are reported as an ErrInvalidTask with their index in tasks before anything
+// is scheduled, any other error names the day it came from.
+func (s *Scheduler) FindBestScheduleByDay(tasks []Task, loc *time.Location, opts ...Option) (map[string]DayResult, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if err := s.withOptions(opts).validateTasks(tasks); err != nil {
+		return nil, err
+	}
+	byDay := make(map[string][]Task)
+	for _, task := range tasks {
+		day := task.StartTime.In(loc).Format(dayLayout)
+		byDay[day] = append(byDay[day], task)
+	}
+	// Days are scheduled in order so logs and traces read chronologically
+	days := make([]string, 0, len(byDay))
+	for day := range byDay {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	results := make(map[string]DayResult, len(days))
+	for _, day := range days {
+		chosenTasks, totalPriority, rejectedTasks, err := s.FindBestSchedule(byDay[day], opts...)
+		if err != nil {
+			return nil, fmt.Errorf("day %s: %w", day, err)
+		}
+		results[day] = DayResult{ChosenTasks: chosenTasks, TotalPriority: totalPriority, RejectedTasks: rejectedTasks}
+	}
+	return results, nil
+}
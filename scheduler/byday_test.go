@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindBestScheduleByDay(t *testing.T) {
+	tasks := []Task{
+		{ID: "monday", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 5},
+		{ID: "monday-clash", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 3},
+		// Starts on Monday and runs into Tuesday, it belongs to Monday
+		{ID: "overnight", StartTime: fixedTime(23), EndTime: fixedTime(26), Priority: 2},
+		{ID: "tuesday", StartTime: fixedTime(25), EndTime: fixedTime(27), Priority: 4},
+	}
+	results, err := newTestScheduler().FindBestScheduleByDay(tasks, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]struct {
+		chosen        []string
+		totalPriority float64
+		numRejected   int
+	}{
+		"2024-01-01": {chosen: []string{"monday", "overnight"}, totalPriority: 7, numRejected: 1},
+		"2024-01-02": {chosen: []string{"tuesday"}, totalPriority: 4, numRejected: 0},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d days, got %+v", len(expected), results)
+	}
+	for day, want := range expected {
+		result, ok := results[day]
+		if !ok {
+			t.Fatalf("Expected a result for %s", day)
+		}
+		if result.TotalPriority != want.totalPriority || len(result.RejectedTasks) != want.numRejected || len(result.ChosenTasks) != len(want.chosen) {
+			t.Fatalf("Unexpected result for %s: %+v", day, result)
+		}
+		for i, id := range want.chosen {
+			if result.ChosenTasks[i].ID != id {
+				t.Errorf("Expected %s task %d to be %s, got %s", day, i, id, result.ChosenTasks[i].ID)
+			}
+		}
+	}
+}
+
+func TestFindBestScheduleByDayLocation(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	tasks := []Task{
+		// 14:00 and 16:00 UTC on the 1st are 23:00 on the 1st and 01:00 on the 2nd in Tokyo
+		{ID: "late", StartTime: fixedTime(14), EndTime: fixedTime(15), Priority: 1},
+		{ID: "early", StartTime: fixedTime(16), EndTime: fixedTime(17), Priority: 1},
+	}
+	results, err := newTestScheduler().FindBestScheduleByDay(tasks, tokyo)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results["2024-01-01"].ChosenTasks) != 1 || results["2024-01-01"].ChosenTasks[0].ID != "late" {
+		t.Errorf("Expected late on the 1st, got %+v", results["2024-01-01"])
+	}
+	if len(results["2024-01-02"].ChosenTasks) != 1 || results["2024-01-02"].ChosenTasks[0].ID != "early" {
+		t.Errorf("Expected early on the 2nd, got %+v", results["2024-01-02"])
+	}
+}
+
+func TestFindBestScheduleByDayErrors(t *testing.T) {
+	tasks := []Task{
+		{ID: "fine", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1},
+		{ID: "other-day", StartTime: fixedTime(33), EndTime: fixedTime(34), Priority: 1},
+		{ID: "backwards", StartTime: fixedTime(35), EndTime: fixedTime(34), Priority: 1},
+	}
+	var invalid ErrInvalidTask
+	if _, err := newTestScheduler().FindBestScheduleByDay(tasks, nil); !errors.As(err, &invalid) || invalid.Index != 2 {
+		t.Errorf("Expected ErrInvalidTask at index 2 of the input, got %v", err)
+	}
+
+	tasks[2].EndTime = fixedTime(36)
+	tasks[1].Mandatory, tasks[2].Mandatory = true, true
+	tasks[2].StartTime = fixedTime(33)
+	var infeasible ErrInfeasible
+	_, err := newTestScheduler().FindBestScheduleByDay(tasks, nil)
+	if !errors.As(err, &infeasible) {
+		t.Fatalf("Expected ErrInfeasible, got %v", err)
+	}
+	if !strings.HasPrefix(err.Error(), "day 2024-01-02: ") {
+		t.Errorf("Expected the error to name the day, got %v", err)
+	}
+}
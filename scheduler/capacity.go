@@ -0,0 +1,204 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// capacityPoint is a position on a timeline in the capacity flow model. order breaks ties
+// between positions at the same instant so the half-open intervals tasks map to overlap
+// exactly when tasksConflict says they do, see capacityInterval.
+type capacityPoint struct {
+	at    int64
+	order int
+}
+
+func (p capacityPoint) before(other capacityPoint) bool {
+	if p.at != other.at {
+		return p.at < other.at
+	}
+	return p.order < other.order
+}
+
+// capacityInterval maps a task to the half-open interval [from, to) of positions it takes
+// up. A regular task runs from its start to its end, so back-to-back tasks don't meet. An
+// instant straddles its own time, reaching into both a task ending there and one starting
+// there, which matches tasksConflict counting the end points of a regular task. With a
+// minimum gap every task keeps its timeline busy for minGap after its sortKey instead.
+func (s *Scheduler) capacityInterval(task Task) (from, to capacityPoint) {
+	task = s.occupied(task)
+	if s.options.minGap > 0 {
+		return capacityPoint{task.StartTime.UnixNano(), 1}, capacityPoint{s.sortKey(task).Add(s.options.minGap).UnixNano(), 1}
+	}
+	if s.isZeroDuration(task) {
+		return capacityPoint{task.StartTime.UnixNano(), 0}, capacityPoint{task.StartTime.UnixNano(), 2}
+	}
+	return capacityPoint{task.StartTime.UnixNano(), 1}, capacityPoint{task.EndTime.UnixNano(), 1}
+}
+
+// FindBestScheduleCapacity finds the combination of tasks that gives us the highest total
+// priority when up to k tasks on the same timeline may overlap at any instant, as if each
+// ResourceID stood for k identical, interchangeable resources. The chosen tasks are returned
+// in chronological order. With k == 1 it agrees with FindBestSchedule.
+//
+// Conflicting tasks form an interval graph, so keeping every instant at k tasks or fewer is
+// the same as being able to share the tasks out over k resources. That makes it a min cost
+// flow over each timeline: k units of flow walk forward through the positions tasks start
+// and end at, riding a task's edge (capacity 1, cost the negated priority) or an idle edge
+// between neighbouring positions. Unlike FindBestScheduleMulti nothing has to work out which
+// resource runs what afterwards, a task is chosen if its edge carries flow.
+//
+// WithConflictFunc, WithSoftConflict, WithMaxTasks, WithExclusiveGroups, bundles and
+// dependencies aren't supported, they don't fit the flow model.
+func (s *Scheduler) FindBestScheduleCapacity(tasks []Task, k int, opts ...Option) ([]Task, float64, []RejectedTask, error) {
+	s = s.withOptions(opts)
+
+	ctx, span := s.tracer().Start(context.Background(), "FindBestScheduleCapacity")
+	defer span.End()
+	logger := s.logger.Ctx(ctx)
+	span.SetAttributes(attribute.Int("num_tasks", len(tasks)), attribute.Int("capacity", k))
+	logger.Info("Starting capacity scheduler", zap.Int("num_tasks", len(tasks)), zap.Int("capacity", k))
+
+	if k < 1 {
+		err := errors.New("FindBestScheduleCapacity needs a capacity of at least 1")
+		span.RecordError(err)
+		return nil, 0, nil, err
+	}
+	if s.options.conflictFunc != nil || s.options.softConflict != nil || s.options.overlapPolicy == ProRate {
+		err := errors.New("FindBestScheduleCapacity does not support WithConflictFunc, WithSoftConflict or the ProRate overlap policy")
+		span.RecordError(err)
+		return nil, 0, nil, err
+	}
+	if s.options.limitTasks || s.options.exclusiveGroups || len(sharedBundles(tasks)) > 0 || hasDependencies(tasks) {
+		err := errors.New("FindBestScheduleCapacity does not support WithMaxTasks, WithExclusiveGroups, bundles or dependencies")
+		span.RecordError(err)
+		return nil, 0, nil, err
+	}
+	if err := s.validateTasks(tasks); err != nil {
+		span.RecordError(err)
+		logger.Warn("Invalid task passed to capacity scheduler", zap.Error(err))
+		return nil, 0, nil, err
+	}
+	// Work on a copy so the caller's tasks don't get IDs filled in behind their back
+	tasks = append([]Task(nil), tasks...)
+	s.assignMissingIDs(tasks)
+	s.resolvePriorities(tasks)
+	tasks, rejectedTasks, err := s.rejectUnschedulable(span, tasks)
+	if err != nil {
+		span.RecordError(err)
+		logger.Warn("Capacity scheduler failed", zap.Error(err))
+		return nil, 0, nil, err
+	}
+
+	chosenTasks := make([]Task, 0)
+	totalPriority := 0.0
+	for _, timeline := range s.splitByResource(tasks) {
+		chosen, err := s.scheduleCapacity(timeline, k)
+		if err != nil {
+			span.RecordError(err)
+			logger.Warn("Capacity scheduler failed", zap.Error(err))
+			return nil, 0, nil, err
+		}
+		timelineChosen := make([]Task, 0)
+		for i, task := range timeline {
+			if chosen[i] {
+				timelineChosen = append(timelineChosen, task)
+				totalPriority += task.Priority
+			}
+		}
+		// Anything left out either had no room because k conflicting tasks were already
+		// chosen around it, or wasn't worth scheduling at all
+		for i, task := range timeline {
+			if chosen[i] {
+				continue
+			}
+			rejection := RejectedTask{TaskRejected: task, Reason: RejectionReasonLowPriority}
+			if task.Priority > 0 {
+				for _, other := range timelineChosen {
+					if s.tasksConflict(task, other) {
+						rejection.Reason = RejectionReasonConflict
+						rejection.CausedByID = other.ID
+						break
+					}
+				}
+			}
+			span.AddEvent("task_rejected", trace.WithAttributes(attribute.String("reason", rejection.Reason.String())))
+			rejectedTasks = append(rejectedTasks, rejection)
+		}
+		chosenTasks = append(chosenTasks, timelineChosen...)
+	}
+	sort.SliceStable(chosenTasks, func(first, second int) bool {
+		return chosenTasks[first].StartTime.Before(chosenTasks[second].StartTime)
+	})
+
+	span.AddEvent("scheduler_finished", trace.WithAttributes(attribute.Int("num_rejected_tasks", len(rejectedTasks))))
+	logger.Info("Capacity scheduler finished", zap.Float64("total_priority", totalPriority), zap.Int("num_rejected_tasks", len(rejectedTasks)))
+	return chosenTasks, totalPriority, rejectedTasks, nil
+}
+
+// scheduleCapacity solves one timeline for FindBestScheduleCapacity, returning which of tasks
+// are chosen. An ErrInfeasible is returned if the mandatory tasks need more than k at once.
+func (s *Scheduler) scheduleCapacity(tasks []Task, k int) ([]bool, error) {
+	chosen := make([]bool, len(tasks))
+	froms := make([]capacityPoint, len(tasks))
+	tos := make([]capacityPoint, len(tasks))
+	points := make([]capacityPoint, 0, 2*len(tasks))
+	for i, task := range tasks {
+		froms[i], tos[i] = s.capacityInterval(task)
+		points = append(points, froms[i], tos[i])
+	}
+	sort.Slice(points, func(first, second int) bool { return points[first].before(points[second]) })
+	pointIndex := make(map[capacityPoint]int, len(points))
+	for _, point := range points {
+		if _, ok := pointIndex[point]; !ok {
+			pointIndex[point] = len(pointIndex)
+		}
+	}
+	numPoints := len(pointIndex)
+
+	graph := newFlowGraph(numPoints)
+	for i := 0; i+1 < numPoints; i++ {
+		graph.addEdge(i, i+1, k, 0, -1)
+	}
+	// Mandatory tasks get a bonus bigger than every other priority combined, same as
+	// FindBestScheduleMulti, so the flow takes all of them whenever it can
+	mandatoryBonus := 1.0
+	for _, task := range tasks {
+		mandatoryBonus += math.Abs(task.Priority)
+	}
+	for i, task := range tasks {
+		cost := -task.Priority
+		if task.Mandatory {
+			cost -= mandatoryBonus
+		}
+		// Tasks that can't improve the total never need an edge
+		if cost >= 0 {
+			continue
+		}
+		graph.addEdge(pointIndex[froms[i]], pointIndex[tos[i]], 1, cost, i)
+	}
+	graph.minCostFlow(0, numPoints-1, k)
+
+	// Used flow shows up on the reverse of a forward (even indexed) edge
+	for edgeIndex := 0; edgeIndex < len(graph.edges); edgeIndex += 2 {
+		if task := graph.edges[edgeIndex].task; task != -1 && graph.edges[edgeIndex^1].capacity > 0 {
+			chosen[task] = true
+		}
+	}
+	missedMandatory := make([]string, 0)
+	for i, task := range tasks {
+		if task.Mandatory && !chosen[i] {
+			missedMandatory = append(missedMandatory, task.ID)
+		}
+	}
+	if len(missedMandatory) > 0 {
+		return nil, ErrInfeasible{TaskIDs: missedMandatory, Reason: "too many mandatory tasks overlap for the capacity"}
+	}
+	return chosen, nil
+}
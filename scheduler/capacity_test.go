@@ -0,0 +1,236 @@
+package scheduler
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// Helper function to find the largest set of tasks that all conflict with each other
+func largestConflictingSet(s *Scheduler, tasks []Task) int {
+	largest := 0
+	for subset := 0; subset < 1<<len(tasks); subset++ {
+		size, valid := 0, true
+		for i := range tasks {
+			if subset&(1<<i) == 0 {
+				continue
+			}
+			size++
+			for j := 0; j < i; j++ {
+				valid = valid && (subset&(1<<j) == 0 || s.tasksConflict(tasks[i], tasks[j]))
+			}
+		}
+		if valid {
+			largest = max(largest, size)
+		}
+	}
+	return largest
+}
+
+// Helper function to find the best total with at most k conflicting tasks at once by trying every subset
+func bruteForceCapacity(s *Scheduler, tasks []Task, k int) float64 {
+	best := 0.0
+	for subset := 0; subset < 1<<len(tasks); subset++ {
+		chosen := make([]Task, 0)
+		total := 0.0
+		for i := range tasks {
+			if subset&(1<<i) != 0 {
+				chosen = append(chosen, tasks[i])
+				total += tasks[i].Priority
+			}
+		}
+		if total > best && largestConflictingSet(s, chosen) <= k {
+			best = total
+		}
+	}
+	return best
+}
+
+// Helper function for small random tasks that often overlap, touch and sit on the same instant
+func randomCapacityTasks(random *rand.Rand, n int) []Task {
+	tasks := make([]Task, n)
+	for i := range tasks {
+		start := fixedTime(9).Add(time.Duration(random.Intn(8)) * 30 * time.Minute)
+		tasks[i] = Task{
+			StartTime:  start,
+			EndTime:    start.Add(time.Duration(random.Intn(4)) * 30 * time.Minute),
+			Priority:   float64(random.Intn(20)-2) / 3,
+			ResourceID: []string{"", "", "a"}[random.Intn(3)],
+		}
+	}
+	return tasks
+}
+
+func TestFindBestScheduleCapacity(t *testing.T) {
+	tests := []struct {
+		name             string
+		tasks            []Task
+		k                int
+		expectedChosen   []string
+		expectedPriority float64
+	}{
+		{
+			name: "Two of three overlapping tasks fit",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 5},
+				{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 3},
+				{ID: "c", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 4},
+			},
+			k:                2,
+			expectedChosen:   []string{"a", "c"},
+			expectedPriority: 9,
+		},
+		{
+			name: "Tasks that don't all overlap at once",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 5},
+				{ID: "b", StartTime: fixedTime(9), EndTime: fixedTime(12), Priority: 3},
+				{ID: "c", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 4},
+				{ID: "d", StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 2},
+			},
+			k:                2,
+			expectedChosen:   []string{"a", "b", "c", "d"},
+			expectedPriority: 14,
+		},
+		{
+			name: "Instant between two full back-to-back pairs",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 5},
+				{ID: "b", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 5},
+				{ID: "c", StartTime: fixedTime(10), EndTime: fixedTime(10), Priority: 1},
+			},
+			k:                2,
+			expectedChosen:   []string{"a", "b"},
+			expectedPriority: 10,
+		},
+		{
+			name: "Each resource gets its own capacity",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 5, ResourceID: "x"},
+				{ID: "b", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 4, ResourceID: "x"},
+				{ID: "c", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 3, ResourceID: "y"},
+			},
+			k:                1,
+			expectedChosen:   []string{"a", "c"},
+			expectedPriority: 8,
+		},
+		{
+			name: "Mandatory task beats higher priorities",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 5},
+				{ID: "b", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 4},
+				{ID: "c", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1, Mandatory: true},
+			},
+			k:                2,
+			expectedChosen:   []string{"a", "c"},
+			expectedPriority: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chosen, totalPriority, rejected, err := newTestScheduler().FindBestScheduleCapacity(tt.tasks, tt.k)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if totalPriority != tt.expectedPriority {
+				t.Errorf("Expected total priority %v, got %v", tt.expectedPriority, totalPriority)
+			}
+			ids := make(map[string]bool)
+			for _, task := range chosen {
+				ids[task.ID] = true
+			}
+			if len(chosen) != len(tt.expectedChosen) {
+				t.Fatalf("Expected %v chosen, got %+v", tt.expectedChosen, chosen)
+			}
+			for _, id := range tt.expectedChosen {
+				if !ids[id] {
+					t.Errorf("Expected %s to be chosen, got %+v", id, chosen)
+				}
+			}
+			if len(chosen)+len(rejected) != len(tt.tasks) {
+				t.Errorf("Expected every task to be chosen or rejected, got %d and %d", len(chosen), len(rejected))
+			}
+			for _, rejection := range rejected {
+				if rejection.Reason != RejectionReasonConflict || !ids[rejection.CausedByID] {
+					t.Errorf("Expected a conflict with a chosen task, got %+v", rejection)
+				}
+			}
+		})
+	}
+}
+
+func TestFindBestScheduleCapacityErrors(t *testing.T) {
+	task := Task{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1}
+	s := newTestScheduler()
+	if _, _, _, err := s.FindBestScheduleCapacity([]Task{task}, 0); err == nil {
+		t.Error("Expected an error for a capacity of 0")
+	}
+	if _, _, _, err := s.FindBestScheduleCapacity([]Task{task}, 2, WithMaxTasks(1)); err == nil {
+		t.Error("Expected FindBestScheduleCapacity to refuse WithMaxTasks")
+	}
+	if _, _, _, err := s.FindBestScheduleCapacity([]Task{task}, 2, WithSoftConflict(flatPenalty(1))); err == nil {
+		t.Error("Expected FindBestScheduleCapacity to refuse WithSoftConflict")
+	}
+	mandatory := Task{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1, Mandatory: true}
+	var infeasible ErrInfeasible
+	_, _, _, err := s.FindBestScheduleCapacity([]Task{mandatory, mandatory, mandatory}, 2)
+	if !errors.As(err, &infeasible) {
+		t.Errorf("Expected ErrInfeasible for three overlapping mandatory tasks, got %v", err)
+	}
+}
+
+func TestFindBestScheduleCapacityMatchesSingleResource(t *testing.T) {
+	optionSets := map[string][]Option{
+		"default":               nil,
+		"min gap":               {WithMinGap(20 * time.Minute)},
+		"instantaneous coexist": {WithInstantaneousCoexist()},
+	}
+	for name, opts := range optionSets {
+		t.Run(name, func(t *testing.T) {
+			random := rand.New(rand.NewSource(1))
+			for round := 0; round < 300; round++ {
+				tasks := randomCapacityTasks(random, 1+random.Intn(12))
+				if round%3 == 0 {
+					tasks[0].SetupBefore = 15 * time.Minute
+					tasks[0].TeardownAfter = 30 * time.Minute
+				}
+				_, expected, _, err := newTestScheduler().FindBestSchedule(tasks, opts...)
+				if err != nil {
+					t.Fatalf("Round %d: unexpected error: %v", round, err)
+				}
+				_, totalPriority, _, err := newTestScheduler().FindBestScheduleCapacity(tasks, 1, opts...)
+				if err != nil {
+					t.Fatalf("Round %d: unexpected error: %v", round, err)
+				}
+				if math.Abs(totalPriority-expected) > 1e-9 {
+					t.Fatalf("Round %d: expected %v, got %v for %+v", round, expected, totalPriority, tasks)
+				}
+			}
+		})
+	}
+}
+
+func TestFindBestScheduleCapacityMatchesBruteForce(t *testing.T) {
+	random := rand.New(rand.NewSource(2))
+	s := newTestScheduler()
+	for round := 0; round < 200; round++ {
+		tasks := randomCapacityTasks(random, 1+random.Intn(9))
+		k := 2 + random.Intn(2)
+		chosen, totalPriority, rejected, err := s.FindBestScheduleCapacity(tasks, k)
+		if err != nil {
+			t.Fatalf("Round %d: unexpected error: %v", round, err)
+		}
+		if expected := bruteForceCapacity(s, tasks, k); math.Abs(totalPriority-expected) > 1e-9 {
+			t.Fatalf("Round %d: expected %v, got %v for %+v", round, expected, totalPriority, tasks)
+		}
+		if largest := largestConflictingSet(s, chosen); largest > k {
+			t.Fatalf("Round %d: expected at most %d conflicting tasks at once, got %d", round, k, largest)
+		}
+		if len(chosen)+len(rejected) != len(tasks) {
+			t.Fatalf("Round %d: expected every task to be chosen or rejected, got %d and %d", round, len(chosen), len(rejected))
+		}
+	}
+}
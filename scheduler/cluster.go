@@ -0,0 +1,167 @@
+package scheduler
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// splitIntoClusters breaks tasks into groups that can be scheduled independently: sorted by
+// start time, a new cluster begins whenever a task starts after everything before it has
+// finished (plus the minimum gap), so no task in one cluster can conflict with another's.
+// Clusters come back in chronological order. A custom conflict check can make any two tasks
+// conflict, so with one set everything stays in a single cluster.
+func (s *Scheduler) splitIntoClusters(tasks []Task) [][]Task {
+	if len(tasks) == 0 {
+		return nil
+	}
+	if s.options.conflictFunc != nil {
+		return [][]Task{tasks}
+	}
+	if s.trustPreSorted() {
+		return s.splitPreSortedIntoClusters(tasks)
+	}
+	sorted := append([]Task(nil), tasks...)
+	sort.SliceStable(sorted, func(first, second int) bool {
+		return s.occupied(sorted[first]).StartTime.Before(s.occupied(sorted[second]).StartTime)
+	})
+
+	clusters := make([][]Task, 0)
+	clusterStart := 0
+	var reach time.Time
+	for i, task := range sorted {
+		task = s.occupied(task)
+		// Touching tasks stay together, a zero duration task can conflict with a task that
+		// ends exactly where it sits
+		if i > 0 && task.StartTime.After(reach) {
+			clusters = append(clusters, sorted[clusterStart:i])
+			clusterStart = i
+		}
+		if end := s.sortKey(task).Add(s.options.minGap); i == clusterStart || end.After(reach) {
+			reach = end
+		}
+	}
+	return append(clusters, sorted[clusterStart:])
+}
+
+// splitPreSortedIntoClusters is splitIntoClusters for tasks already in the order WithPreSorted
+// asks for, without sorting them by start time. In that order every cluster is a contiguous
+// run of tasks, and a new one begins wherever everything after a point starts after
+// everything before it has finished, which a pass from the back for the earliest start
+// finds in linear time. The clusters keep the tasks' order so the DP needn't sort them.
+func (s *Scheduler) splitPreSortedIntoClusters(tasks []Task) [][]Task {
+	if debugChecks && !s.inDPOrder(tasks) {
+		panic("scheduler: tasks passed with WithPreSorted aren't in the order it documents")
+	}
+	earliestStartFrom := make([]time.Time, len(tasks))
+	for i := len(tasks) - 1; i >= 0; i-- {
+		earliestStartFrom[i] = s.occupied(tasks[i]).StartTime
+		if i+1 < len(tasks) && earliestStartFrom[i+1].Before(earliestStartFrom[i]) {
+			earliestStartFrom[i] = earliestStartFrom[i+1]
+		}
+	}
+
+	clusters := make([][]Task, 0)
+	clusterStart := 0
+	var reach time.Time
+	for i, task := range tasks {
+		if i > 0 && earliestStartFrom[i].After(reach) {
+			clusters = append(clusters, tasks[clusterStart:i])
+			clusterStart = i
+		}
+		// Sorted by sortKey, so the latest task so far reaches furthest
+		reach = s.sortKey(s.occupied(task)).Add(s.options.minGap)
+	}
+	return append(clusters, tasks[clusterStart:])
+}
+
+// clusterResult is what scheduling one cluster produced
+type clusterResult struct {
+	chosenTasks   []Task
+	totalPriority float64
+	rejectedTasks []RejectedTask
+	err           error
+}
+
+// schedule finds the best schedule for tasks that have already been through
+// rejectUnschedulable, with whichever solver the tasks and options call for
+func (s *Scheduler) schedule(ctx context.Context, span trace.Span, tasks []Task) ([]Task, float64, []RejectedTask, error) {
+	if s.options.overlapPolicy == ProRate {
+		return s.scheduleProRate(ctx, span, tasks)
+	}
+	if s.options.softConflict != nil {
+		return s.scheduleSoftConflicts(ctx, span, tasks)
+	}
+	if hasDependencies(tasks) {
+		return s.scheduleDependencies(ctx, span, tasks)
+	}
+	return s.scheduleIndependent(ctx, span, tasks)
+}
+
+// scheduleIndependent is schedule once dependencies have been dealt with
+func (s *Scheduler) scheduleIndependent(ctx context.Context, span trace.Span, tasks []Task) ([]Task, float64, []RejectedTask, error) {
+	if bundles := sharedBundles(tasks); len(bundles) > 0 {
+		return s.scheduleBundles(ctx, span, tasks, bundles)
+	}
+	return s.scheduleUnbundled(ctx, span, tasks)
+}
+
+// scheduleUnbundled is schedule once bundles have been dealt with
+func (s *Scheduler) scheduleUnbundled(ctx context.Context, span trace.Span, tasks []Task) ([]Task, float64, []RejectedTask, error) {
+	if s.options.limitTasks || s.options.exclusiveGroups {
+		return s.scheduleConstrained(ctx, span, tasks)
+	}
+	return s.scheduleClusters(ctx, span, tasks)
+}
+
+// scheduleClusters finds the best schedule for tasks that have already been through
+// rejectUnschedulable by solving each cluster on its own and merging the results in
+// chronological order. With WithParallel the clusters are solved concurrently, the merge
+// is the same either way so the result doesn't depend on it.
+func (s *Scheduler) scheduleClusters(ctx context.Context, span trace.Span, tasks []Task) ([]Task, float64, []RejectedTask, error) {
+	clusters := s.splitIntoClusters(tasks)
+	span.SetAttributes(attribute.Int("num_clusters", len(clusters)))
+	results := make([]clusterResult, len(clusters))
+	solve := func(i int) {
+		chosenTasks, totalPriority, rejectedTasks, err := s.scheduleResources(ctx, span, clusters[i])
+		results[i] = clusterResult{chosenTasks: chosenTasks, totalPriority: totalPriority, rejectedTasks: rejectedTasks, err: err}
+	}
+
+	if s.options.parallel && len(clusters) > 1 {
+		// Bound the goroutines doing work to the number of CPUs we're allowed to use
+		slots := make(chan struct{}, runtime.GOMAXPROCS(0))
+		var wg sync.WaitGroup
+		for i := range clusters {
+			wg.Add(1)
+			slots <- struct{}{}
+			go func(i int) {
+				defer wg.Done()
+				defer func() { <-slots }()
+				solve(i)
+			}(i)
+		}
+		wg.Wait()
+	} else {
+		for i := range clusters {
+			solve(i)
+		}
+	}
+
+	chosenTasks := make([]Task, 0)
+	totalPriority := 0.0
+	rejectedTasks := []RejectedTask{}
+	for _, result := range results {
+		if result.err != nil {
+			return nil, 0, nil, result.err
+		}
+		chosenTasks = append(chosenTasks, result.chosenTasks...)
+		totalPriority += result.totalPriority
+		rejectedTasks = append(rejectedTasks, result.rejectedTasks...)
+	}
+	return chosenTasks, totalPriority, rejectedTasks, nil
+}
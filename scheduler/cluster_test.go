@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Helper function to turn fuzz input into tasks, every 4 bytes are a start offset, a
+// duration, a priority and a resource
+func fuzzTasks(data []byte) []Task {
+	tasks := make([]Task, 0, len(data)/4)
+	for i := 0; i+3 < len(data); i += 4 {
+		start := fixedTime(0).Add(time.Duration(data[i]) * 10 * time.Minute)
+		tasks = append(tasks, Task{
+			StartTime: start,
+			EndTime:   start.Add(time.Duration(data[i+1]%48) * 10 * time.Minute),
+			// Non-integer priorities so a different summation order would show up
+			Priority:   float64(data[i+2]) / 7,
+			ResourceID: []string{"", "", "a", "b"}[data[i+3]%4],
+		})
+	}
+	return tasks
+}
+
+func FuzzParallelMatchesSequential(f *testing.F) {
+	f.Add([]byte{0, 6, 5, 0, 3, 6, 8, 0, 7, 6, 4, 0, 100, 0, 2, 0, 200, 12, 9, 1})
+	f.Add([]byte{0, 1, 1, 0, 10, 1, 1, 0, 20, 1, 1, 0, 30, 1, 1, 0, 40, 1, 1, 0, 50, 1, 1, 0})
+	f.Add([]byte{5, 40, 200, 2, 6, 3, 100, 2, 9, 3, 100, 2, 12, 0, 1, 3, 12, 3, 7, 3})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tasks := fuzzTasks(data)
+		if len(tasks) == 0 {
+			return
+		}
+		s := newTestScheduler()
+		chosen, total, rejected, err := s.FindBestSchedule(tasks)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		parallelChosen, parallelTotal, parallelRejected, err := s.FindBestSchedule(tasks, WithParallel())
+		if err != nil {
+			t.Fatalf("Unexpected error in parallel: %v", err)
+		}
+		if total != parallelTotal {
+			t.Errorf("Total mismatch: sequential %v, parallel %v", total, parallelTotal)
+		}
+		if !reflect.DeepEqual(chosen, parallelChosen) {
+			t.Errorf("Chosen mismatch:\nsequential %+v\nparallel   %+v", chosen, parallelChosen)
+		}
+		if !reflect.DeepEqual(rejected, parallelRejected) {
+			t.Errorf("Rejected mismatch:\nsequential %+v\nparallel   %+v", rejected, parallelRejected)
+		}
+		if !s.IsOptimal(tasks, parallelChosen) {
+			t.Errorf("Parallel schedule isn't optimal")
+		}
+	})
+}
+
+func TestParallelManyClusters(t *testing.T) {
+	tasks := make([]Task, 0)
+	for day := 0; day < 50; day++ {
+		base := fixedTime(0).AddDate(0, 0, day)
+		tasks = append(tasks,
+			Task{StartTime: base.Add(9 * time.Hour), EndTime: base.Add(12 * time.Hour), Priority: 10},
+			Task{StartTime: base.Add(9 * time.Hour), EndTime: base.Add(10 * time.Hour), Priority: 6},
+			Task{StartTime: base.Add(10 * time.Hour), EndTime: base.Add(12 * time.Hour), Priority: 6},
+		)
+	}
+	s := newTestScheduler()
+	if clusters := s.splitIntoClusters(tasks); len(clusters) != 50 {
+		t.Fatalf("Expected 50 clusters, got %d", len(clusters))
+	}
+	chosen, total, rejected, err := s.FindBestSchedule(tasks, WithParallel())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if total != 600 || len(chosen) != 100 || len(rejected) != 50 {
+		t.Errorf("Expected 100 tasks worth 600 with 50 rejected, got %d worth %.2f with %d rejected", len(chosen), total, len(rejected))
+	}
+	for i := 1; i < len(chosen); i++ {
+		if chosen[i].StartTime.Before(chosen[i-1].StartTime) {
+			t.Errorf("Chosen tasks out of order at %d", i)
+		}
+	}
+}
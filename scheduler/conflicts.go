@@ -0,0 +1,91 @@
+package scheduler
+
+import "sort"
+
+// ConflictPairs returns every pair of tasks that conflict under tasksConflict (and the options
+// given), as indexes into tasks with the smaller one first, sorted. Nothing is solved, it's the
+// raw conflict graph for seeing where tasks contend before any priorities come into it.
+func (s *Scheduler) ConflictPairs(tasks []Task, opts ...Option) [][2]int {
+	s = s.withOptions(opts)
+	pairs := make([][2]int, 0)
+	// A custom conflict check can make any two tasks conflict, so every pair has to be asked
+	if s.options.conflictFunc != nil {
+		for i := range tasks {
+			for j := i + 1; j < len(tasks); j++ {
+				if s.tasksConflict(tasks[i], tasks[j]) {
+					pairs = append(pairs, [2]int{i, j})
+				}
+			}
+		}
+		return pairs
+	}
+
+	// Otherwise only tasks starting before one finishes (plus the gap) can conflict with it, so
+	// sweep in start order and stop looking as soon as the starts pass that point
+	order := make([]int, len(tasks))
+	for i := range order {
+		order[i] = i
+	}
+	occupied := make([]Task, len(tasks))
+	for i, task := range tasks {
+		occupied[i] = s.occupied(task)
+	}
+	sort.SliceStable(order, func(first, second int) bool {
+		return occupied[order[first]].StartTime.Before(occupied[order[second]].StartTime)
+	})
+	for position, i := range order {
+		latestStart := s.sortKey(occupied[i]).Add(s.options.minGap)
+		for _, j := range order[position+1:] {
+			if occupied[j].StartTime.After(latestStart) {
+				break
+			}
+			if s.tasksConflict(tasks[i], tasks[j]) {
+				pairs = append(pairs, [2]int{min(i, j), max(i, j)})
+			}
+		}
+	}
+	sort.Slice(pairs, func(first, second int) bool {
+		if pairs[first][0] != pairs[second][0] {
+			return pairs[first][0] < pairs[second][0]
+		}
+		return pairs[first][1] < pairs[second][1]
+	})
+	return pairs
+}
+
+// ConflictClusters groups tasks into the connected components of the ConflictPairs graph, so
+// tasks in different clusters never compete even indirectly. Every task is in exactly one
+// cluster, a task that conflicts with nothing is a cluster of its own. Each cluster lists its
+// indexes in ascending order and clusters are ordered by their first index.
+func (s *Scheduler) ConflictClusters(tasks []Task, opts ...Option) [][]int {
+	// Union find over the indexes, always keeping the smaller index as the root
+	parent := make([]int, len(tasks))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for _, pair := range s.ConflictPairs(tasks, opts...) {
+		first, second := find(pair[0]), find(pair[1])
+		parent[max(first, second)] = min(first, second)
+	}
+
+	clusters := make([][]int, 0)
+	clusterOf := make(map[int]int)
+	for i := range tasks {
+		root := find(i)
+		index, ok := clusterOf[root]
+		if !ok {
+			index = len(clusters)
+			clusterOf[root] = index
+			clusters = append(clusters, make([]int, 0))
+		}
+		clusters[index] = append(clusters[index], i)
+	}
+	return clusters
+}
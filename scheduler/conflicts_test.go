@@ -0,0 +1,52 @@
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConflictPairs(t *testing.T) {
+	tasks := []Task{
+		{ID: "late", StartTime: fixedTime(14), EndTime: fixedTime(15), Priority: 1},
+		{ID: "first", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 1},
+		{ID: "second", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 1},
+		{ID: "touching", StartTime: fixedTime(12), EndTime: fixedTime(13), Priority: 1},
+		{ID: "instant", StartTime: fixedTime(12), EndTime: fixedTime(12), Priority: 1},
+		{ID: "antenna", StartTime: fixedTime(9), EndTime: fixedTime(15), Priority: 1, ResourceID: "antenna"},
+	}
+	s := newTestScheduler()
+	expected := [][2]int{{1, 2}, {2, 4}, {3, 4}}
+	if pairs := s.ConflictPairs(tasks); !reflect.DeepEqual(pairs, expected) {
+		t.Errorf("Expected pairs %v, got %v", expected, pairs)
+	}
+	expectedClusters := [][]int{{0}, {1, 2, 3, 4}, {5}}
+	if clusters := s.ConflictClusters(tasks); !reflect.DeepEqual(clusters, expectedClusters) {
+		t.Errorf("Expected clusters %v, got %v", expectedClusters, clusters)
+	}
+
+	// Options change what conflicts, with a gap touching tasks clash too
+	expected = [][2]int{{1, 2}, {2, 3}, {2, 4}, {3, 4}}
+	if pairs := s.ConflictPairs(tasks, WithMinGap(fixedTime(2).Sub(fixedTime(1)))); !reflect.DeepEqual(pairs, expected) {
+		t.Errorf("Expected pairs with a gap %v, got %v", expected, pairs)
+	}
+}
+
+func FuzzConflictPairsMatchesAllPairs(f *testing.F) {
+	f.Add([]byte{0, 6, 5, 0, 3, 6, 8, 0, 7, 6, 4, 0, 100, 0, 2, 0, 200, 12, 9, 1})
+	f.Add([]byte{5, 40, 200, 2, 6, 3, 100, 2, 9, 3, 100, 2, 12, 0, 1, 3, 12, 3, 7, 3})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tasks := fuzzTasks(data)
+		s := newTestScheduler()
+		expected := make([][2]int, 0)
+		for i := range tasks {
+			for j := i + 1; j < len(tasks); j++ {
+				if s.tasksConflict(tasks[i], tasks[j]) {
+					expected = append(expected, [2]int{i, j})
+				}
+			}
+		}
+		if pairs := s.ConflictPairs(tasks); !reflect.DeepEqual(pairs, expected) {
+			t.Errorf("Expected pairs %v, got %v", expected, pairs)
+		}
+	})
+}
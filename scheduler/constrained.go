@@ -0,0 +1,367 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxExclusiveGroups caps how many exclusive groups (with more than one task) a schedule can
+// have, the DP tracks which groups are used so its size doubles with every group
+const maxExclusiveGroups = 10
+
+// constraints are the rules that tie tasks together beyond conflicts, WithMaxTasks and
+// WithExclusiveGroups. The DP state is how much of them has been used up: a count of tasks
+// (when capped) and a mask of the exclusive groups that already have a task.
+type constraints struct {
+	capped    bool
+	maxCount  int
+	groupBit  map[string]int
+	numGroups int
+}
+
+func (c constraints) groupStates() int {
+	return 1 << c.numGroups
+}
+
+// bit is the mask bit for a task's exclusive group, 0 if it isn't in one
+func (c constraints) bit(task Task) int {
+	if bit, ok := c.groupBit[task.GroupID]; ok {
+		return bit
+	}
+	return 0
+}
+
+// constrainedTimeline is the DP table for one timeline under constraints. Row i covers the
+// first i tasks (in sortsBefore order) and column state the best value using at most that
+// count and only the groups in that mask.
+type constrainedTimeline struct {
+	constraints
+	tasks       []Task
+	countStates int
+	best        []scheduleValue
+	included    []bool
+	// previousRow is the row of the latest task compatible with each task, 0 if there is none
+	previousRow []int32
+}
+
+func (t *constrainedTimeline) cell(row, count, mask int) int {
+	return (row*t.countStates+count)*t.groupStates() + mask
+}
+
+// bestWith is the best value this timeline can reach using at most count tasks from the
+// groups in mask
+func (t *constrainedTimeline) bestWith(count, mask int) scheduleValue {
+	return t.best[t.cell(len(t.tasks), min(count, t.countStates-1), mask)]
+}
+
+// solveConstrainedTimeline fills in the DP table for tasks that all share one timeline
+func (s *Scheduler) solveConstrainedTimeline(ctx context.Context, tasks []Task, c constraints) (*constrainedTimeline, error) {
+	s.sortForDP(tasks)
+	numTasks := len(tasks)
+	timeline := &constrainedTimeline{
+		constraints: c,
+		tasks:       tasks,
+		countStates: 1,
+		previousRow: make([]int32, numTasks),
+	}
+	if c.capped {
+		timeline.countStates = min(c.maxCount, numTasks) + 1
+	}
+	numCells := (numTasks + 1) * timeline.countStates * c.groupStates()
+	timeline.best = make([]scheduleValue, numCells)
+	timeline.included = make([]bool, numCells)
+
+	// Row 0 (no tasks) is all zero values, which make already gave us
+	for row := 1; row <= numTasks; row++ {
+		if err := checkCancelled(ctx, row); err != nil {
+			return nil, err
+		}
+		task := tasks[row-1]
+		previousRow := s.findBestPreviousTask(tasks, row-1) + 1
+		timeline.previousRow[row-1] = int32(previousRow)
+		value := s.taskValue(task)
+		bit := c.bit(task)
+		for count := 0; count < timeline.countStates; count++ {
+			for mask := 0; mask < c.groupStates(); mask++ {
+				cell := timeline.cell(row, count, mask)
+				valueIfExcluded := timeline.best[timeline.cell(row-1, count, mask)]
+				timeline.best[cell] = valueIfExcluded
+				// Taking the task uses up one of the count and its group
+				previousCount := count
+				if c.capped {
+					previousCount--
+				}
+				if previousCount < 0 || mask&bit != bit {
+					continue
+				}
+				valueIfIncluded := value.plus(timeline.best[timeline.cell(previousRow, previousCount, mask&^bit)])
+				if s.betterValue(valueIfIncluded, valueIfExcluded) {
+					timeline.best[cell] = valueIfIncluded
+					timeline.included[cell] = true
+				}
+			}
+		}
+	}
+	return timeline, nil
+}
+
+// chosen backtracks the best schedule using at most count tasks from the groups in mask, in
+// chronological order, along with which of the timeline's tasks it picked
+func (t *constrainedTimeline) chosen(count, mask int) ([]Task, []bool) {
+	count = min(count, t.countStates-1)
+	chosenIndexes := make([]bool, len(t.tasks))
+	numChosen := 0
+	for row := len(t.tasks); row > 0; {
+		if t.included[t.cell(row, count, mask)] {
+			chosenIndexes[row-1] = true
+			numChosen++
+			if t.capped {
+				count--
+			}
+			mask &^= t.bit(t.tasks[row-1])
+			row = int(t.previousRow[row-1])
+		} else {
+			row--
+		}
+	}
+	chosenTasks := make([]Task, 0, numChosen)
+	for i, task := range t.tasks {
+		if chosenIndexes[i] {
+			chosenTasks = append(chosenTasks, task)
+		}
+	}
+	return chosenTasks, chosenIndexes
+}
+
+// hasSharedGroup reports if more than one task has the same GroupID
+func hasSharedGroup(tasks []Task) bool {
+	seen := make(map[string]bool)
+	for _, task := range tasks {
+		if task.GroupID == "" {
+			continue
+		}
+		if seen[task.GroupID] {
+			return true
+		}
+		seen[task.GroupID] = true
+	}
+	return false
+}
+
+// groupConflict reports if two tasks can't both be chosen because they're in the same
+// exclusive group
+func (s *Scheduler) groupConflict(task1, task2 Task) bool {
+	return s.options.exclusiveGroups && task1.GroupID != "" && task1.GroupID == task2.GroupID
+}
+
+// scheduleConstrained is scheduleClusters for WithMaxTasks and WithExclusiveGroups. Both tie
+// tasks together no matter how far apart they are, so each resource is still its own timeline
+// but what each one gets has to be decided together: every timeline's table gives its best
+// value for each count and set of groups, and a knapsack over those picks the split with the
+// best total.
+func (s *Scheduler) scheduleConstrained(ctx context.Context, span trace.Span, tasks []Task) ([]Task, float64, []RejectedTask, error) {
+	// Groups of one can't break the rule, without any bigger ones the clusters are still
+	// independent and much cheaper to solve
+	if !s.options.limitTasks && !hasSharedGroup(tasks) {
+		return s.scheduleClusters(ctx, span, tasks)
+	}
+	mandatory := mandatoryTasks(tasks)
+	if err := s.checkMandatory(mandatory); err != nil {
+		return nil, 0, nil, err
+	}
+	for i := range mandatory {
+		for j := i + 1; j < len(mandatory); j++ {
+			if s.groupConflict(mandatory[i], mandatory[j]) {
+				return nil, 0, nil, ErrInfeasible{TaskIDs: []string{mandatory[i].ID, mandatory[j].ID}, Reason: "mandatory tasks share an exclusive group"}
+			}
+		}
+	}
+	if s.options.limitTasks && len(mandatory) > s.options.maxTasks {
+		taskIDs := make([]string, 0, len(mandatory))
+		for _, task := range mandatory {
+			taskIDs = append(taskIDs, task.ID)
+		}
+		return nil, 0, nil, ErrInfeasible{TaskIDs: taskIDs, Reason: "more mandatory tasks than WithMaxTasks allows"}
+	}
+
+	// Same as scheduleAroundMandatory, anything clashing with a mandatory task is out, and so
+	// is anything sharing its group
+	rejectedTasks := []RejectedTask{}
+	free := make([]Task, 0, len(tasks)-len(mandatory))
+	for _, task := range tasks {
+		if task.Mandatory {
+			continue
+		}
+		blocked := false
+		for _, committed := range mandatory {
+			reason := RejectionReasonConflict
+			if !s.tasksConflict(task, committed) {
+				if !s.groupConflict(task, committed) {
+					continue
+				}
+				reason = RejectionReasonGroupExclusive
+			}
+			span.AddEvent("task_rejected", trace.WithAttributes(attribute.String("reason", reason.String())))
+			rejectedTasks = append(rejectedTasks, RejectedTask{
+				TaskRejected: task,
+				CausedByID:   committed.ID,
+				Reason:       reason,
+			})
+			blocked = true
+			break
+		}
+		if !blocked {
+			free = append(free, task)
+		}
+	}
+
+	// Only groups with more than one task left actually constrain anything
+	c := constraints{capped: s.options.limitTasks, groupBit: make(map[string]int)}
+	if s.options.limitTasks {
+		c.maxCount = s.options.maxTasks - len(mandatory)
+		span.SetAttributes(attribute.Int("max_tasks", s.options.maxTasks))
+	}
+	if s.options.exclusiveGroups {
+		groupSizes := make(map[string]int)
+		groupOrder := make([]string, 0)
+		for _, task := range free {
+			if task.GroupID == "" {
+				continue
+			}
+			if groupSizes[task.GroupID] == 0 {
+				groupOrder = append(groupOrder, task.GroupID)
+			}
+			groupSizes[task.GroupID]++
+		}
+		for _, group := range groupOrder {
+			if groupSizes[group] > 1 {
+				c.groupBit[group] = 1 << c.numGroups
+				c.numGroups++
+			}
+		}
+		span.SetAttributes(attribute.Int("num_exclusive_groups", c.numGroups))
+		if c.numGroups > maxExclusiveGroups {
+			return nil, 0, nil, fmt.Errorf("%d exclusive groups with more than one task, at most %d are supported", c.numGroups, maxExclusiveGroups)
+		}
+	}
+
+	timelines := make([]*constrainedTimeline, 0)
+	if len(free) > 0 {
+		for _, group := range s.splitByResource(free) {
+			timeline, err := s.solveConstrainedTimeline(ctx, group, c)
+			if err != nil {
+				return nil, 0, nil, err
+			}
+			timelines = append(timelines, timeline)
+		}
+	}
+
+	// totals[state] is the best value over the timelines so far within that count and set of
+	// groups, splits records the count and groups the latest timeline got out of it so the
+	// split can be walked back
+	countStates := 1
+	if c.capped {
+		countStates = c.maxCount + 1
+	}
+	numStates := countStates * c.groupStates()
+	totals := make([]scheduleValue, numStates)
+	splits := make([][]int32, len(timelines))
+	for t, timeline := range timelines {
+		if err := checkCancelled(ctx, t); err != nil {
+			return nil, 0, nil, err
+		}
+		next := make([]scheduleValue, numStates)
+		splits[t] = make([]int32, numStates)
+		for count := 0; count < countStates; count++ {
+			maxUsed := 0
+			if c.capped {
+				maxUsed = min(count, timeline.countStates-1)
+			}
+			for mask := 0; mask < c.groupStates(); mask++ {
+				// Start from the timeline getting no count and no groups, without a cap that
+				// still leaves it all of its ungrouped tasks
+				state := count*c.groupStates() + mask
+				next[state] = totals[state].plus(timeline.bestWith(0, 0))
+				for used := 0; used <= maxUsed; used++ {
+					// Every non-empty subset of mask as the timeline's groups, plus no groups
+					// at all once it's using some of the count
+					for groups := mask; ; groups = (groups - 1) & mask {
+						if used > 0 || groups != 0 {
+							rest := (count-used)*c.groupStates() + mask&^groups
+							value := totals[rest].plus(timeline.bestWith(used, groups))
+							if s.betterValue(value, next[state]) {
+								next[state] = value
+								splits[t][state] = int32(used*c.groupStates() + groups)
+							}
+						}
+						if groups == 0 {
+							break
+						}
+					}
+				}
+			}
+		}
+		totals = next
+	}
+
+	chosenTasks := append(make([]Task, 0, len(mandatory)), mandatory...)
+	state := numStates - 1
+	totalPriority := totals[state].priority
+	for _, task := range mandatory {
+		totalPriority += task.Priority
+	}
+	// Walk the split back from the last timeline, then merge in timeline order so ties on start
+	// time come out the same way scheduleResources orders them
+	timelineChosen := make([][]Task, len(timelines))
+	chosenIndexes := make([][]bool, len(timelines))
+	for t := len(timelines) - 1; t >= 0; t-- {
+		split := int(splits[t][state])
+		used, groups := split/c.groupStates(), split%c.groupStates()
+		timelineChosen[t], chosenIndexes[t] = timelines[t].chosen(used, groups)
+		count, mask := state/c.groupStates(), state%c.groupStates()
+		state = (count-used)*c.groupStates() + mask&^groups
+	}
+	for _, timelineTasks := range timelineChosen {
+		chosenTasks = append(chosenTasks, timelineTasks...)
+	}
+	sort.SliceStable(chosenTasks, func(first, second int) bool {
+		return chosenTasks[first].StartTime.Before(chosenTasks[second].StartTime)
+	})
+
+	// A task left out that clashes with a chosen one lost a conflict, or its group went to
+	// another task. Otherwise it would have fitted, so if it would have added anything only
+	// the cap kept it out.
+	chosenInGroup := make(map[string]string)
+	for _, task := range chosenTasks {
+		if task.GroupID != "" {
+			chosenInGroup[task.GroupID] = task.ID
+		}
+	}
+	for t, timeline := range timelines {
+		for i, task := range timeline.tasks {
+			if err := checkCancelled(ctx, i); err != nil {
+				return nil, 0, nil, err
+			}
+			if chosenIndexes[t][i] {
+				continue
+			}
+			rejected := RejectedTask{TaskRejected: task, Reason: RejectionReasonLowPriority}
+			if conflicting := s.findConflictingChosen(timelineChosen[t], task); conflicting != -1 {
+				rejected.Reason = RejectionReasonConflict
+				rejected.CausedByID = timelineChosen[t][conflicting].ID
+			} else if chosenID, taken := chosenInGroup[task.GroupID]; taken && s.options.exclusiveGroups {
+				rejected.Reason = RejectionReasonGroupExclusive
+				rejected.CausedByID = chosenID
+			} else if c.capped && s.betterValue(s.taskValue(task), scheduleValue{}) {
+				rejected.Reason = RejectionReasonCapExceeded
+			}
+			span.AddEvent("task_rejected", trace.WithAttributes(attribute.String("reason", rejected.Reason.String())))
+			rejectedTasks = append(rejectedTasks, rejected)
+		}
+	}
+	return chosenTasks, totalPriority, rejectedTasks, nil
+}
@@ -0,0 +1,276 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// Helper function to find the best total of conflict-free tasks by trying every subset, with
+// at most maxTasks of them (if it isn't negative), if exclusiveGroups is set at most one per
+// group, and bundles either whole or not at all
+func bruteForceConstrained(tasks []Task, maxTasks int, exclusiveGroups bool) float64 {
+	bundleSizes := make(map[string]int)
+	for _, task := range tasks {
+		bundleSizes[task.BundleID]++
+	}
+	best := 0.0
+	for subset := 0; subset < 1<<len(tasks); subset++ {
+		chosen := make([]Task, 0)
+		groups := make(map[string]bool)
+		bundles := make(map[string]int)
+		total := 0.0
+		valid := true
+		for i := range tasks {
+			if subset&(1<<i) == 0 {
+				continue
+			}
+			if exclusiveGroups && tasks[i].GroupID != "" {
+				valid = valid && !groups[tasks[i].GroupID]
+				groups[tasks[i].GroupID] = true
+			}
+			bundles[tasks[i].BundleID]++
+			chosen = append(chosen, tasks[i])
+			total += tasks[i].Priority
+		}
+		for bundle, size := range bundles {
+			valid = valid && (bundle == "" || size == bundleSizes[bundle])
+		}
+		if !valid || (maxTasks >= 0 && len(chosen) > maxTasks) || total <= best {
+			continue
+		}
+		if ValidateSchedule(chosen) == nil {
+			best = total
+		}
+	}
+	return best
+}
+
+func TestWithMaxTasks(t *testing.T) {
+	tasks := []Task{
+		{ID: "long", StartTime: fixedTime(9), EndTime: fixedTime(12), Priority: 10},
+		{ID: "first", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 4},
+		{ID: "second", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 4},
+		{ID: "third", StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 4},
+		{ID: "other", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 3, ResourceID: "b"},
+		{ID: "worthless", StartTime: fixedTime(13), EndTime: fixedTime(14), Priority: 0},
+	}
+	tests := []struct {
+		name          string
+		maxTasks      int
+		expectedTotal float64
+		expectedIDs   []string
+		capExceeded   []string
+	}{
+		{name: "No room", maxTasks: 0, expectedTotal: 0, capExceeded: []string{"first", "second", "long", "third", "other"}},
+		{name: "One task", maxTasks: 1, expectedTotal: 10, expectedIDs: []string{"long"}, capExceeded: []string{"other"}},
+		{name: "Two tasks", maxTasks: 2, expectedTotal: 13, expectedIDs: []string{"long", "other"}},
+		{name: "Three tasks", maxTasks: 3, expectedTotal: 13, expectedIDs: []string{"long", "other"}},
+		{name: "Four tasks", maxTasks: 4, expectedTotal: 15, expectedIDs: []string{"first", "other", "second", "third"}},
+		{name: "Negative is zero", maxTasks: -1, expectedTotal: 0, capExceeded: []string{"first", "second", "long", "third", "other"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScheduler()
+			chosen, total, rejected, err := s.FindBestSchedule(tasks, WithMaxTasks(tt.maxTasks))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if total != tt.expectedTotal {
+				t.Errorf("Expected total %.2f, got %.2f", tt.expectedTotal, total)
+			}
+			if len(chosen) != len(tt.expectedIDs) {
+				t.Fatalf("Expected %v, got %+v", tt.expectedIDs, chosen)
+			}
+			for i, id := range tt.expectedIDs {
+				if chosen[i].ID != id {
+					t.Errorf("Expected task %d to be %s, got %s", i, id, chosen[i].ID)
+				}
+			}
+			if len(chosen)+len(rejected) != len(tasks) {
+				t.Errorf("Expected every task to be chosen or rejected, got %d chosen and %d rejected", len(chosen), len(rejected))
+			}
+			capExceeded := make([]string, 0)
+			for _, rejection := range rejected {
+				if rejection.Reason == RejectionReasonCapExceeded {
+					capExceeded = append(capExceeded, rejection.TaskRejected.ID)
+				}
+			}
+			if len(capExceeded) != len(tt.capExceeded) {
+				t.Fatalf("Expected %v rejected for the cap, got %v", tt.capExceeded, capExceeded)
+			}
+			for i, id := range tt.capExceeded {
+				if capExceeded[i] != id {
+					t.Errorf("Expected %v rejected for the cap, got %v", tt.capExceeded, capExceeded)
+				}
+			}
+		})
+	}
+}
+
+func TestWithMaxTasksMandatory(t *testing.T) {
+	tasks := []Task{
+		{ID: "mandatory", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1, Mandatory: true},
+		{ID: "clash", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 50},
+		{ID: "big", StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 20},
+		{ID: "small", StartTime: fixedTime(13), EndTime: fixedTime(14), Priority: 5},
+	}
+	s := newTestScheduler()
+	chosen, total, _, err := s.FindBestSchedule(tasks, WithMaxTasks(2))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if total != 21 || len(chosen) != 2 || chosen[0].ID != "mandatory" || chosen[1].ID != "big" {
+		t.Errorf("Expected mandatory and big worth 21, got %+v worth %.2f", chosen, total)
+	}
+
+	_, _, _, err = s.FindBestSchedule(tasks, WithMaxTasks(0))
+	var infeasible ErrInfeasible
+	if !errors.As(err, &infeasible) {
+		t.Errorf("Expected ErrInfeasible with more mandatory tasks than the cap, got %v", err)
+	}
+}
+
+func TestWithMaxTasksUnsupported(t *testing.T) {
+	s := newTestScheduler()
+	tasks := []Task{{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1}}
+	if _, _, _, err := s.ScheduleStream(context.Background(), tasks, WithMaxTasks(1)); err == nil {
+		t.Error("Expected ScheduleStream to refuse WithMaxTasks")
+	}
+	if _, _, _, err := s.FindBestScheduleMulti(tasks, 2, WithMaxTasks(1)); err == nil {
+		t.Error("Expected FindBestScheduleMulti to refuse WithMaxTasks")
+	}
+}
+
+func TestWithExclusiveGroups(t *testing.T) {
+	tasks := []Task{
+		{ID: "downlink-1", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 5, GroupID: "downlink"},
+		{ID: "downlink-2", StartTime: fixedTime(12), EndTime: fixedTime(13), Priority: 6, GroupID: "downlink"},
+		{ID: "downlink-3", StartTime: fixedTime(15), EndTime: fixedTime(16), Priority: 4, GroupID: "downlink", ResourceID: "b"},
+		{ID: "science", StartTime: fixedTime(12), EndTime: fixedTime(14), Priority: 4},
+		{ID: "imaging", StartTime: fixedTime(16), EndTime: fixedTime(17), Priority: 2, GroupID: "solo"},
+	}
+	s := newTestScheduler()
+
+	// Without the option every downlink is taken
+	_, total, _, err := s.FindBestSchedule(tasks)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if total != 17 {
+		t.Errorf("Expected total 17 without exclusive groups, got %.2f", total)
+	}
+
+	// With it only one downlink is, and the first one makes room for science
+	chosen, total, rejected, err := s.FindBestSchedule(tasks, WithExclusiveGroups())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectedIDs := []string{"downlink-1", "science", "imaging"}
+	if total != 11 || len(chosen) != len(expectedIDs) {
+		t.Fatalf("Expected %v worth 11, got %+v worth %.2f", expectedIDs, chosen, total)
+	}
+	for i, id := range expectedIDs {
+		if chosen[i].ID != id {
+			t.Errorf("Expected task %d to be %s, got %s", i, id, chosen[i].ID)
+		}
+	}
+	reasons := make(map[string]RejectedTask)
+	for _, rejection := range rejected {
+		reasons[rejection.TaskRejected.ID] = rejection
+	}
+	if reasons["downlink-3"].Reason != RejectionReasonGroupExclusive || reasons["downlink-3"].CausedByID != "downlink-1" {
+		t.Errorf("Expected downlink-3 rejected for downlink-1's group, got %+v", reasons["downlink-3"])
+	}
+	if reasons["downlink-2"].Reason != RejectionReasonConflict || reasons["downlink-2"].CausedByID != "science" {
+		t.Errorf("Expected downlink-2 rejected for conflicting with science, got %+v", reasons["downlink-2"])
+	}
+}
+
+func TestWithExclusiveGroupsMandatory(t *testing.T) {
+	tasks := []Task{
+		{ID: "planned", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1, GroupID: "downlink", Mandatory: true},
+		{ID: "backup", StartTime: fixedTime(12), EndTime: fixedTime(13), Priority: 50, GroupID: "downlink"},
+	}
+	s := newTestScheduler()
+	chosen, _, rejected, err := s.FindBestSchedule(tasks, WithExclusiveGroups())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(chosen) != 1 || chosen[0].ID != "planned" {
+		t.Errorf("Expected only the mandatory task, got %+v", chosen)
+	}
+	if len(rejected) != 1 || rejected[0].Reason != RejectionReasonGroupExclusive {
+		t.Errorf("Expected backup rejected for the group, got %+v", rejected)
+	}
+
+	tasks[1].Mandatory = true
+	_, _, _, err = s.FindBestSchedule(tasks, WithExclusiveGroups())
+	var infeasible ErrInfeasible
+	if !errors.As(err, &infeasible) {
+		t.Errorf("Expected ErrInfeasible for two mandatory tasks in one group, got %v", err)
+	}
+}
+
+func TestWithExclusiveGroupsTooMany(t *testing.T) {
+	tasks := make([]Task, 0)
+	for group := 0; group <= maxExclusiveGroups; group++ {
+		for member := 0; member < 2; member++ {
+			start := fixedTime(0).Add(time.Duration(2*group+member) * time.Hour)
+			tasks = append(tasks, Task{StartTime: start, EndTime: start.Add(time.Hour), Priority: 1, GroupID: strconv.Itoa(group)})
+		}
+	}
+	if _, _, _, err := newTestScheduler().FindBestSchedule(tasks, WithExclusiveGroups()); err == nil {
+		t.Errorf("Expected an error with more than %d exclusive groups", maxExclusiveGroups)
+	}
+}
+
+func FuzzConstrainedMatchesBruteForce(f *testing.F) {
+	f.Add(byte(2), []byte{0, 6, 5, 0, 3, 6, 8, 0, 7, 6, 4, 0, 100, 0, 2, 0, 200, 12, 9, 1})
+	f.Add(byte(3), []byte{5, 40, 200, 2, 6, 3, 100, 2, 9, 3, 100, 2, 12, 0, 1, 3, 12, 3, 7, 3})
+	f.Add(byte(12), []byte{0, 1, 1, 0, 10, 1, 1, 0, 20, 1, 1, 0, 30, 1, 1, 0, 40, 1, 1, 2, 50, 1, 1, 3})
+	f.Fuzz(func(t *testing.T, mode byte, data []byte) {
+		tasks := fuzzTasks(data)
+		// Brute force is exponential, keep it small
+		if len(tasks) == 0 || len(tasks) > 12 {
+			return
+		}
+		for i := range tasks {
+			tasks[i].GroupID = []string{"", "x", "y"}[i%3]
+		}
+		// The low bits pick the cap (8 meaning none), the next bit exclusive groups
+		maxTasks := int(mode % 9)
+		opts := []Option{}
+		if maxTasks == 8 {
+			maxTasks = -1
+		} else {
+			opts = append(opts, WithMaxTasks(maxTasks))
+		}
+		exclusiveGroups := mode&16 != 0
+		if exclusiveGroups {
+			opts = append(opts, WithExclusiveGroups())
+		}
+
+		s := newTestScheduler()
+		chosen, total, rejected, err := s.FindBestSchedule(tasks, opts...)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if maxTasks >= 0 && len(chosen) > maxTasks {
+			t.Errorf("Expected at most %d tasks, got %d", maxTasks, len(chosen))
+		}
+		if err := ValidateSchedule(chosen); err != nil {
+			t.Errorf("Schedule has conflicts: %v", err)
+		}
+		if expected := bruteForceConstrained(tasks, maxTasks, exclusiveGroups); math.Abs(expected-total) > 1e-9 {
+			t.Errorf("Expected total %v, got %v", expected, total)
+		}
+		if len(chosen)+len(rejected) != len(tasks) {
+			t.Errorf("Expected every task to be chosen or rejected, got %d chosen and %d rejected", len(chosen), len(rejected))
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package core
+
+// Objective is how the DP values schedules, V is whatever it accumulates (a total priority,
+// or that plus tie breakers)
+type Objective[V any] struct {
+	// Value is what task i adds to a schedule
+	Value func(i int) V
+	// Plus adds two values up
+	Plus func(first, second V) V
+	// Better reports if first is strictly better than second, the zero V is the empty schedule
+	Better func(first, second V) bool
+}
+
+// Table is the state Compute builds up, every slice is indexed like the tasks
+type Table[V any] struct {
+	// Best stores the best value we can get using the tasks up to a given one
+	Best []V
+	// Previous stores the index of the task chosen before the current one in that best
+	// schedule, int32 halves the memory and no timeline gets anywhere near 2 billion tasks
+	Previous []int32
+	// Included records whether the best schedule up to a task includes that task
+	Included []bool
+	// Beaten records the tasks left out in the forward pass because leaving them out was at
+	// least as good
+	Beaten []bool
+}
+
+// Compute runs the weighted interval scheduling recurrence over n tasks in the order the DP
+// visits them (see Compare), n must be at least 1. previous(i) is the latest task compatible
+// with everything before it in that order, usually LatestCompatible. check is called on
+// every iteration and stops the computation with its error, e.g. for cancellation, it may be
+// nil.
+func Compute[V any](n int, objective Objective[V], previous func(i int) int, check func(i int) error) (Table[V], error) {
+	table := Table[V]{
+		Best:     make([]V, n),
+		Previous: make([]int32, n),
+		Included: make([]bool, n),
+		Beaten:   make([]bool, n),
+	}
+
+	// Base case, the first task on its own unless an empty schedule beats it, which is the
+	// case for a negative priority
+	var empty V
+	table.Previous[0] = -1
+	if first := objective.Value(0); objective.Better(empty, first) {
+		table.Beaten[0] = true
+	} else {
+		table.Best[0] = first
+		table.Included[0] = true
+	}
+
+	for current := 1; current < n; current++ {
+		if check != nil {
+			if err := check(current); err != nil {
+				return Table[V]{}, err
+			}
+		}
+		// Including the current task adds its value to the best schedule of everything
+		// that finishes before it starts, excluding it keeps the best schedule so far
+		bestPrevious := previous(current)
+		valueIfIncluded := objective.Value(current)
+		if bestPrevious != -1 {
+			valueIfIncluded = objective.Plus(valueIfIncluded, table.Best[bestPrevious])
+		}
+		valueIfExcluded := table.Best[current-1]
+
+		if objective.Better(valueIfIncluded, valueIfExcluded) {
+			table.Best[current] = valueIfIncluded
+			table.Included[current] = true
+			table.Previous[current] = int32(bestPrevious)
+		} else {
+			// Ties keep the schedule we already had
+			table.Best[current] = valueIfExcluded
+			table.Previous[current] = table.Previous[current-1]
+			table.Beaten[current] = true
+		}
+	}
+	return table, nil
+}
+
+// Reconstruct backtracks through the table for the chosen task indexes in DP order, along
+// with which indexes were chosen
+func (table Table[V]) Reconstruct() ([]int, []bool) {
+	// Backtrack once to count the chosen tasks so the slices below are allocated exactly once,
+	// then again to fill them in, back to front so they come out in order
+	numTasks := len(table.Included)
+	numChosen := 0
+	for i := numTasks - 1; i >= 0; {
+		if table.Included[i] {
+			numChosen++
+			i = int(table.Previous[i])
+		} else {
+			i--
+		}
+	}
+	chosen := make([]int, numChosen)
+	chosenIndexes := make([]bool, numTasks)
+	for i, next := numTasks-1, numChosen-1; i >= 0; {
+		if table.Included[i] {
+			chosen[next] = i
+			chosenIndexes[i] = true
+			next--
+			i = int(table.Previous[i])
+		} else {
+			i--
+		}
+	}
+	return chosen, chosenIndexes
+}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"go/build"
+	"strings"
+	"testing"
+)
+
+// heavyImports are the dependencies the instrumented scheduler pulls in that core must stay
+// clear of, they don't build for the browser or would bloat the WebAssembly binary
+var heavyImports = []string{
+	"go.opentelemetry.io/",
+	"go.uber.org/",
+	"github.com/uptrace/",
+	"google.golang.org/grpc",
+	"google.golang.org/protobuf",
+}
+
+func TestBuildsForWebAssembly(t *testing.T) {
+	context := build.Default
+	context.GOOS = "js"
+	context.GOARCH = "wasm"
+	pkg, err := context.ImportDir(".", 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(pkg.GoFiles) == 0 {
+		t.Fatal("Expected core to have Go files for js/wasm")
+	}
+	for _, path := range pkg.Imports {
+		for _, heavy := range heavyImports {
+			if strings.HasPrefix(path, heavy) {
+				t.Errorf("Expected core not to import %s", path)
+			}
+		}
+		// Anything outside the standard library has a dot in its first path element
+		if first, _, _ := strings.Cut(path, "/"); strings.Contains(first, ".") || strings.HasPrefix(path, "turionspace/") {
+			t.Errorf("Expected core to only import the standard library, got %s", path)
+		}
+	}
+}
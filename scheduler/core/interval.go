@@ -0,0 +1,96 @@
+// Package core is the weighted interval scheduling algorithm behind scheduler.Scheduler with
+// none of its telemetry. It only needs the standard library, so it builds for
+// GOOS=js GOARCH=wasm, e.g. to preview a plan in the browser.
+package core
+
+import (
+	"sort"
+	"time"
+)
+
+// Interval is the stretch of a timeline a task ties up. One that doesn't end after it starts
+// behaves like an instant at Start.
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// IsInstant checks if the interval has zero (or negative) duration
+func (iv Interval) IsInstant() bool {
+	return !iv.End.After(iv.Start)
+}
+
+// SortKey is the instant an interval is ordered by, its end or for an instant its start
+func (iv Interval) SortKey() time.Time {
+	if iv.IsInstant() {
+		return iv.Start
+	}
+	return iv.End
+}
+
+// Gap returns the idle time between two intervals, negative if they overlap
+func Gap(first, second Interval) time.Duration {
+	gap := second.Start.Sub(first.SortKey())
+	if other := first.Start.Sub(second.SortKey()); other > gap {
+		gap = other
+	}
+	return gap
+}
+
+// Conflict checks if two intervals on the same timeline can't both be scheduled. With a
+// minimum gap they conflict when they're closer than minGap. Otherwise regular intervals
+// conflict when they overlap, touching end to start is fine, and an instant conflicts with
+// anything running at that instant, end points included.
+func Conflict(first, second Interval, minGap time.Duration) bool {
+	if minGap > 0 {
+		return Gap(first, second) < minGap
+	}
+	switch {
+	case first.IsInstant() && second.IsInstant():
+		return first.Start.Equal(second.Start)
+	case first.IsInstant():
+		return !first.Start.Before(second.Start) && !first.Start.After(second.End)
+	case second.IsInstant():
+		return !second.Start.Before(first.Start) && !second.Start.After(first.End)
+	}
+	return first.Start.Before(second.End) && second.Start.Before(first.End)
+}
+
+// Compare orders intervals the way the DP visits them: by SortKey, with a regular interval
+// before an instant when the keys tie. An interval starting at that instant is compatible
+// with the regular intervals but conflicts with the instants, so this keeps the compatible
+// ones a prefix for LatestCompatible. It returns 0 for intervals the DP doesn't mind the
+// order of.
+func Compare(first, second Interval) int {
+	key1, key2 := first.SortKey(), second.SortKey()
+	if !key1.Equal(key2) {
+		return key1.Compare(key2)
+	}
+	switch zero1, zero2 := first.IsInstant(), second.IsInstant(); {
+	case zero1 == zero2:
+		return 0
+	case zero2:
+		return -1
+	}
+	return 1
+}
+
+// FinishesBefore checks if an earlier interval in Compare order leaves room for current to
+// start. This is Conflict specialised to a pair where previous sorts before current.
+func FinishesBefore(previous, current Interval, minGap time.Duration) bool {
+	boundary := previous.SortKey().Add(minGap)
+	if !boundary.Equal(current.Start) {
+		return boundary.Before(current.Start)
+	}
+	// Touching is only allowed between regular intervals, instants conflict with anything
+	// at their instant
+	return minGap > 0 || (!previous.IsInstant() && !current.IsInstant())
+}
+
+// LatestCompatible finds the latest index before current that compatible accepts, the
+// classic weighted interval scheduling predecessor, or -1 if there's none. compatible must
+// hold for a prefix of the earlier indexes and not the rest, which FinishesBefore does for
+// intervals in Compare order, so it's a binary search.
+func LatestCompatible(current int, compatible func(previous int) bool) int {
+	return sort.Search(current, func(i int) bool { return !compatible(i) }) - 1
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+// Helper function to create a fixed time for testing
+func fixedTime(hour int) time.Time {
+	return time.Date(2024, 1, 1, hour, 0, 0, 0, time.UTC)
+}
+
+func TestConflict(t *testing.T) {
+	tests := []struct {
+		name     string
+		first    Interval
+		second   Interval
+		minGap   time.Duration
+		expected bool
+	}{
+		{name: "Overlapping", first: Interval{fixedTime(9), fixedTime(11)}, second: Interval{fixedTime(10), fixedTime(12)}, expected: true},
+		{name: "Back to back", first: Interval{fixedTime(9), fixedTime(10)}, second: Interval{fixedTime(10), fixedTime(11)}, expected: false},
+		{name: "Instant at an end", first: Interval{fixedTime(9), fixedTime(10)}, second: Interval{fixedTime(10), fixedTime(10)}, expected: true},
+		{name: "Same instant", first: Interval{fixedTime(10), fixedTime(10)}, second: Interval{fixedTime(10), fixedTime(10)}, expected: true},
+		{name: "Different instants", first: Interval{fixedTime(10), fixedTime(10)}, second: Interval{fixedTime(11), fixedTime(11)}, expected: false},
+		{name: "Too close for the gap", first: Interval{fixedTime(9), fixedTime(10)}, second: Interval{fixedTime(11), fixedTime(12)}, minGap: 2 * time.Hour, expected: true},
+		{name: "Exactly the gap", first: Interval{fixedTime(9), fixedTime(10)}, second: Interval{fixedTime(11), fixedTime(12)}, minGap: time.Hour, expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Conflict(tt.first, tt.second, tt.minGap); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+			if got := Conflict(tt.second, tt.first, tt.minGap); got != tt.expected {
+				t.Errorf("Expected %v the other way round, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	regular := Interval{fixedTime(9), fixedTime(10)}
+	instant := Interval{fixedTime(10), fixedTime(10)}
+	later := Interval{fixedTime(8), fixedTime(11)}
+	if Compare(regular, later) != -1 || Compare(later, regular) != 1 {
+		t.Error("Expected intervals to be ordered by end time")
+	}
+	if Compare(regular, instant) != -1 || Compare(instant, regular) != 1 {
+		t.Error("Expected a regular interval before an instant at its end")
+	}
+	if Compare(regular, Interval{fixedTime(7), fixedTime(10)}) != 0 {
+		t.Error("Expected regular intervals ending together to tie")
+	}
+}
+
+func TestLatestCompatible(t *testing.T) {
+	intervals := []Interval{
+		{fixedTime(8), fixedTime(9)},
+		{fixedTime(9), fixedTime(10)},
+		{fixedTime(10), fixedTime(10)},
+		{fixedTime(9), fixedTime(11)},
+		{fixedTime(10), fixedTime(12)},
+	}
+	expected := []int{-1, 0, 0, 0, 1}
+	for current, want := range expected {
+		got := LatestCompatible(current, func(previous int) bool {
+			return FinishesBefore(intervals[previous], intervals[current], 0)
+		})
+		if got != want {
+			t.Errorf("Expected interval %d to follow %d, got %d", current, want, got)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"sort"
+	"time"
+)
+
+// Task is a task for FindBestSchedule, the subset of scheduler.Task the plain DP looks at
+type Task struct {
+	StartTime time.Time
+	EndTime   time.Time
+	Priority  float64
+}
+
+// FindBestSchedule finds the combination of tasks on one timeline that gives the highest total
+// priority, keeping at least minGap of idle time between chosen tasks. The chosen tasks come
+// back in chronological order. It's what scheduler.Scheduler computes without options, minus
+// input validation, so tasks should have real times and finite priorities.
+func FindBestSchedule(tasks []Task, minGap time.Duration) ([]Task, float64) {
+	if len(tasks) == 0 {
+		return nil, 0
+	}
+	sorted := append([]Task(nil), tasks...)
+	sort.SliceStable(sorted, func(first, second int) bool {
+		task1, task2 := sorted[first], sorted[second]
+		if order := Compare(task1.interval(), task2.interval()); order != 0 {
+			return order < 0
+		}
+		if !task1.StartTime.Equal(task2.StartTime) {
+			return task1.StartTime.Before(task2.StartTime)
+		}
+		return task1.Priority > task2.Priority
+	})
+
+	objective := Objective[float64]{
+		Value:  func(i int) float64 { return sorted[i].Priority },
+		Plus:   func(first, second float64) float64 { return first + second },
+		Better: func(first, second float64) bool { return first > second },
+	}
+	previous := func(current int) int {
+		return LatestCompatible(current, func(i int) bool {
+			return FinishesBefore(sorted[i].interval(), sorted[current].interval(), minGap)
+		})
+	}
+	// Without a check function Compute can't fail
+	table, _ := Compute(len(sorted), objective, previous, nil)
+	chosen, _ := table.Reconstruct()
+	chosenTasks := make([]Task, len(chosen))
+	for i, index := range chosen {
+		chosenTasks[i] = sorted[index]
+	}
+	return chosenTasks, table.Best[len(sorted)-1]
+}
+
+func (t Task) interval() Interval {
+	return Interval{Start: t.StartTime, End: t.EndTime}
+}
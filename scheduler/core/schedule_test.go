@@ -0,0 +1,114 @@
+package core_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+	"turionspace/nei-mission-planner/scheduler/scheduler"
+	"turionspace/nei-mission-planner/scheduler/scheduler/core"
+)
+
+// Helper function to create a fixed time for testing
+func fixedTime(hour int) time.Time {
+	return time.Date(2024, 1, 1, hour, 0, 0, 0, time.UTC)
+}
+
+func TestFindBestSchedule(t *testing.T) {
+	tests := []struct {
+		name             string
+		tasks            []core.Task
+		minGap           time.Duration
+		expectedChosen   []float64
+		expectedPriority float64
+	}{
+		{
+			name:             "No tasks",
+			expectedPriority: 0,
+		},
+		{
+			name: "Overlap goes to the higher total",
+			tasks: []core.Task{
+				{StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 5},
+				{StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 3},
+				{StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 2},
+			},
+			expectedChosen:   []float64{5, 2},
+			expectedPriority: 7,
+		},
+		{
+			name: "Instant at the end of a task",
+			tasks: []core.Task{
+				{StartTime: fixedTime(10), EndTime: fixedTime(10), Priority: 4},
+				{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 3},
+				{StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 3},
+			},
+			expectedChosen:   []float64{3, 3},
+			expectedPriority: 6,
+		},
+		{
+			name: "Minimum gap",
+			tasks: []core.Task{
+				{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 3},
+				{StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 2},
+				{StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 3},
+			},
+			minGap:           time.Hour,
+			expectedChosen:   []float64{3, 3},
+			expectedPriority: 6,
+		},
+		{
+			name:             "Negative priority is left out",
+			tasks:            []core.Task{{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: -1}},
+			expectedPriority: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chosen, totalPriority := core.FindBestSchedule(tt.tasks, tt.minGap)
+			if totalPriority != tt.expectedPriority {
+				t.Errorf("Expected total priority %v, got %v", tt.expectedPriority, totalPriority)
+			}
+			if len(chosen) != len(tt.expectedChosen) {
+				t.Fatalf("Expected %d chosen tasks, got %+v", len(tt.expectedChosen), chosen)
+			}
+			for i, priority := range tt.expectedChosen {
+				if chosen[i].Priority != priority {
+					t.Errorf("Expected task %d to have priority %v, got %v", i, priority, chosen[i].Priority)
+				}
+				if i > 0 && chosen[i].StartTime.Before(chosen[i-1].StartTime) {
+					t.Errorf("Expected chronological order, got %+v", chosen)
+				}
+			}
+		})
+	}
+}
+
+func TestFindBestScheduleMatchesScheduler(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for round := 0; round < 300; round++ {
+		tasks := make([]scheduler.Task, 1+random.Intn(20))
+		coreTasks := make([]core.Task, len(tasks))
+		for i := range tasks {
+			start := fixedTime(9).Add(time.Duration(random.Intn(12)) * 30 * time.Minute)
+			tasks[i] = scheduler.Task{
+				StartTime: start,
+				EndTime:   start.Add(time.Duration(random.Intn(5)) * 30 * time.Minute),
+				Priority:  float64(random.Intn(20)-4) / 4,
+			}
+			coreTasks[i] = core.Task{StartTime: tasks[i].StartTime, EndTime: tasks[i].EndTime, Priority: tasks[i].Priority}
+		}
+		minGap := time.Duration(random.Intn(3)) * 20 * time.Minute
+		s := scheduler.NewScheduler(scheduler.SchedulerConfig{})
+		_, expected, _, err := s.FindBestSchedule(tasks, scheduler.WithMinGap(minGap))
+		if err != nil {
+			t.Fatalf("Round %d: unexpected error: %v", round, err)
+		}
+		// The scheduler drops duplicate tasks first, which never changes the best total
+		_, totalPriority := core.FindBestSchedule(coreTasks, minGap)
+		if math.Abs(totalPriority-expected) > 1e-9 {
+			t.Fatalf("Round %d: expected %v, got %v for %+v", round, expected, totalPriority, tasks)
+		}
+	}
+}
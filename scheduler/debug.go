@@ -0,0 +1,7 @@
+//go:build scheduler_debug
+
+package scheduler
+
+// debugChecks turns on internal consistency checks that are too costly for normal builds,
+// build or test with -tags scheduler_debug to enable them
+const debugChecks = true
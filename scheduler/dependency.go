@@ -0,0 +1,316 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxDependencyTasks caps how many tasks can take part in dependencies, either depending on
+// another task or being depended on. Every combination of them is solved separately, so the
+// work doubles with every one.
+const maxDependencyTasks = 12
+
+// ErrDependencyCycle is returned when tasks' DependsOn go round in a loop, which no schedule
+// could ever satisfy. TaskIDs follows the loop, starting and ending with the same task.
+type ErrDependencyCycle struct {
+	TaskIDs []string
+}
+
+func (e ErrDependencyCycle) Error() string {
+	return "tasks depend on each other in a cycle: " + strings.Join(e.TaskIDs, " -> ")
+}
+
+// hasDependencies checks if any task depends on another
+func hasDependencies(tasks []Task) bool {
+	for _, task := range tasks {
+		if len(task.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDependencyCycles returns an ErrDependencyCycle for the first loop in the tasks'
+// DependsOn, looking from each task in input order. Tasks sharing an ID share their
+// dependencies, so the check works on IDs.
+func checkDependencyCycles(tasks []Task) error {
+	dependsOn := make(map[string][]string)
+	order := make([]string, 0)
+	for _, task := range tasks {
+		if _, seen := dependsOn[task.ID]; !seen {
+			order = append(order, task.ID)
+		}
+		dependsOn[task.ID] = append(dependsOn[task.ID], task.DependsOn...)
+	}
+
+	// A depth first walk, anything reached again while still on the path closes a loop
+	const (
+		onPath = iota + 1
+		done
+	)
+	state := make(map[string]int, len(dependsOn))
+	path := make([]string, 0)
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		switch state[id] {
+		case onPath:
+			for i := range path {
+				if path[i] == id {
+					return append(append([]string(nil), path[i:]...), id)
+				}
+			}
+		case done:
+			return nil
+		}
+		state[id] = onPath
+		path = append(path, id)
+		for _, dependency := range dependsOn[id] {
+			if cycle := visit(dependency); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = done
+		return nil
+	}
+	for _, id := range order {
+		if cycle := visit(id); cycle != nil {
+			return ErrDependencyCycle{TaskIDs: cycle}
+		}
+	}
+	return nil
+}
+
+// precedes checks if dependency finishes in time for task to depend on it
+func precedes(dependency, task Task) bool {
+	return !dependency.EndTime.After(task.StartTime)
+}
+
+// dropUnmetDependencies removes the chosen tasks whose dependencies aren't met by the other
+// chosen tasks, including ones left unmet by an earlier removal, keeping the rest in order
+func dropUnmetDependencies(chosen []Task) []Task {
+	for {
+		byID := make(map[string][]int, len(chosen))
+		for i, task := range chosen {
+			byID[task.ID] = append(byID[task.ID], i)
+		}
+		unmet := unmetDependencies(chosen, byID)
+		if len(unmet) == 0 {
+			return chosen
+		}
+		kept := make([]Task, 0, len(chosen)-len(unmet))
+		for i, task := range chosen {
+			if _, found := unmet[i]; !found {
+				kept = append(kept, task)
+			}
+		}
+		chosen = kept
+	}
+}
+
+// unmetDependencies finds the tasks whose dependencies can't be met by any schedule, because
+// no task with a dependency's ID finishes before they start (or the only ones that do can't be
+// met themselves). It returns the dependency blamed for each of them, by index into tasks.
+func unmetDependencies(tasks []Task, byID map[string][]int) map[int]string {
+	unmet := make(map[int]string)
+	// There are no cycles, so this settles within one pass per link of the longest chain
+	for changed := true; changed; {
+		changed = false
+		for i, task := range tasks {
+			if _, found := unmet[i]; found {
+				continue
+			}
+			for _, dependency := range task.DependsOn {
+				met := false
+				for _, j := range byID[dependency] {
+					if _, found := unmet[j]; !found && precedes(tasks[j], task) {
+						met = true
+						break
+					}
+				}
+				if !met {
+					unmet[i] = dependency
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return unmet
+}
+
+// scheduleDependencies is schedule for tasks with DependsOn: a task is only chosen along with,
+// for each of its dependencies, a chosen task with that ID that finishes (by EndTime) at or
+// before its StartTime. Precedence between arbitrary tasks can't be expressed in the interval
+// DP, with it the problem is NP-hard in general, so like bundles every combination of the
+// tasks taking part in dependencies is tried, solving with the combination's tasks made
+// mandatory and the others left out, and the best one whose dependencies all hold wins. Ties
+// go to the combination tried first. Tasks whose dependencies could never be met are rejected
+// before the search, so they don't count against maxDependencyTasks.
+func (s *Scheduler) scheduleDependencies(ctx context.Context, span trace.Span, tasks []Task) ([]Task, float64, []RejectedTask, error) {
+	byID := make(map[string][]int, len(tasks))
+	for i, task := range tasks {
+		byID[task.ID] = append(byID[task.ID], i)
+	}
+	unmet := unmetDependencies(tasks, byID)
+	missedMandatory := make([]string, 0)
+	for i, task := range tasks {
+		if _, found := unmet[i]; found && task.Mandatory {
+			missedMandatory = append(missedMandatory, task.ID)
+		}
+	}
+	if len(missedMandatory) > 0 {
+		return nil, 0, nil, ErrInfeasible{TaskIDs: missedMandatory, Reason: "dependencies can't be met by any schedule"}
+	}
+
+	// The tasks taking part are the ones with dependencies that might still be met and every
+	// task they could be met by
+	bit := make(map[int]int)
+	involved := make([]int, 0)
+	for i, task := range tasks {
+		if _, found := unmet[i]; found || len(task.DependsOn) == 0 {
+			continue
+		}
+		for _, dependency := range task.DependsOn {
+			for _, j := range byID[dependency] {
+				if _, found := unmet[j]; !found {
+					bit[j] = 0
+				}
+			}
+		}
+		bit[i] = 0
+	}
+	for i := range tasks {
+		if _, found := bit[i]; found {
+			bit[i] = 1 << len(involved)
+			involved = append(involved, i)
+		}
+	}
+	span.SetAttributes(attribute.Int("num_dependency_tasks", len(involved)))
+	if len(involved) > maxDependencyTasks {
+		return nil, 0, nil, fmt.Errorf("%d tasks take part in dependencies, at most %d are supported", len(involved), maxDependencyTasks)
+	}
+	required := 0
+	mandatory := make(map[string]bool)
+	for i, task := range tasks {
+		if task.Mandatory {
+			required |= bit[i]
+			mandatory[task.ID] = true
+		}
+	}
+	// holds checks every dependency of the chosen tasks in mask is met by another in it
+	holds := func(mask int) bool {
+		for _, i := range involved {
+			if mask&bit[i] == 0 {
+				continue
+			}
+			for _, dependency := range tasks[i].DependsOn {
+				met := false
+				for _, j := range byID[dependency] {
+					if mask&bit[j] != 0 && precedes(tasks[j], tasks[i]) {
+						met = true
+						break
+					}
+				}
+				if !met {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	var bestChosen []Task
+	var bestRejected []RejectedTask
+	var bestValue scheduleValue
+	bestPriority := 0.0
+	bestMask := -1
+	var infeasibleErr error
+	for mask := 0; mask < 1<<len(involved); mask++ {
+		if mask&required != required || !holds(mask) {
+			continue
+		}
+		candidates := make([]Task, 0, len(tasks))
+		for i, task := range tasks {
+			if _, found := unmet[i]; found {
+				continue
+			}
+			if b, found := bit[i]; found {
+				if mask&b == 0 {
+					continue
+				}
+				task.Mandatory = true
+			}
+			candidates = append(candidates, task)
+		}
+		chosenTasks, totalPriority, rejectedTasks, err := s.scheduleIndependent(ctx, span, candidates)
+		var infeasible ErrInfeasible
+		if errors.As(err, &infeasible) {
+			// The tasks in this combination don't fit together, try the next
+			if infeasibleErr == nil {
+				infeasibleErr = err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		value := scheduleValue{}
+		for _, task := range chosenTasks {
+			value = value.plus(s.taskValue(task))
+		}
+		if bestMask == -1 || s.betterValue(value, bestValue) {
+			bestChosen, bestPriority, bestRejected, bestValue, bestMask = chosenTasks, totalPriority, rejectedTasks, value, mask
+		}
+	}
+	// Every involved task together always holds, so nothing was chosen only if it didn't fit
+	if bestMask == -1 {
+		return nil, 0, nil, infeasibleErr
+	}
+
+	// Only tasks that were mandatory to begin with should come back marked that way
+	for i := range bestChosen {
+		bestChosen[i].Mandatory = mandatory[bestChosen[i].ID]
+	}
+	chosenByID := make(map[string][]Task, len(bestChosen))
+	for _, task := range bestChosen {
+		chosenByID[task.ID] = append(chosenByID[task.ID], task)
+	}
+	for i, task := range tasks {
+		var rejected RejectedTask
+		if dependency, found := unmet[i]; found {
+			rejected = RejectedTask{TaskRejected: task, CausedByID: dependency, Reason: RejectionReasonUnmetDependency}
+		} else if b, found := bit[i]; found && bestMask&b == 0 {
+			rejected = s.leftOutRejection(task, bestChosen, chosenByID)
+		} else {
+			continue
+		}
+		span.AddEvent("task_rejected", trace.WithAttributes(attribute.String("reason", rejected.Reason.String())))
+		bestRejected = append(bestRejected, rejected)
+	}
+	return bestChosen, bestPriority, bestRejected, nil
+}
+
+// leftOutRejection says why a task taking part in dependencies was left out of the schedule:
+// the first of its dependencies the chosen tasks don't meet, otherwise a chosen task it
+// conflicts with, otherwise it just wasn't worth it
+func (s *Scheduler) leftOutRejection(task Task, chosen []Task, chosenByID map[string][]Task) RejectedTask {
+	for _, dependency := range task.DependsOn {
+		met := false
+		for _, other := range chosenByID[dependency] {
+			met = met || precedes(other, task)
+		}
+		if !met {
+			return RejectedTask{TaskRejected: task, CausedByID: dependency, Reason: RejectionReasonUnmetDependency}
+		}
+	}
+	if conflicting := s.findConflictingChosenLinear(chosen, task); conflicting != -1 {
+		return RejectedTask{TaskRejected: task, CausedByID: chosen[conflicting].ID, Reason: RejectionReasonConflict}
+	}
+	return RejectedTask{TaskRejected: task, Reason: RejectionReasonLowPriority}
+}
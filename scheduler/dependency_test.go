@@ -0,0 +1,292 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Helper function to find the best total with dependencies by trying every subset
+func bruteForceDependencies(s *Scheduler, tasks []Task) (float64, bool) {
+	best, found := 0.0, false
+	for subset := 0; subset < 1<<len(tasks); subset++ {
+		total, valid := 0.0, true
+		for i := range tasks {
+			if subset&(1<<i) == 0 {
+				valid = valid && !tasks[i].Mandatory
+				continue
+			}
+			total += tasks[i].Priority
+			for j := 0; j < i; j++ {
+				valid = valid && (subset&(1<<j) == 0 || !s.tasksConflict(tasks[i], tasks[j]))
+			}
+			for _, dependency := range tasks[i].DependsOn {
+				met := false
+				for j := range tasks {
+					met = met || (subset&(1<<j) != 0 && tasks[j].ID == dependency && precedes(tasks[j], tasks[i]))
+				}
+				valid = valid && met
+			}
+		}
+		if valid && (!found || total > best) {
+			best, found = total, true
+		}
+	}
+	return best, found
+}
+
+func TestDependencies(t *testing.T) {
+	tests := []struct {
+		name          string
+		tasks         []Task
+		expectedIDs   []string
+		expectedTotal float64
+		rejected      map[string]RejectedTask
+	}{
+		{
+			name: "Calibration before imaging",
+			tasks: []Task{
+				{ID: "calibrate", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1},
+				{ID: "image", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 5, DependsOn: []string{"calibrate"}},
+				{ID: "downlink", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 3},
+			},
+			expectedIDs:   []string{"calibrate", "image"},
+			expectedTotal: 6,
+			rejected: map[string]RejectedTask{
+				"downlink": {Reason: RejectionReasonConflict, CausedByID: "calibrate"},
+			},
+		},
+		{
+			name: "Not worth the dependency",
+			tasks: []Task{
+				{ID: "calibrate", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1},
+				{ID: "image", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 1, DependsOn: []string{"calibrate"}},
+				{ID: "downlink", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 3},
+			},
+			expectedIDs:   []string{"downlink"},
+			expectedTotal: 3,
+			rejected: map[string]RejectedTask{
+				"calibrate": {Reason: RejectionReasonConflict, CausedByID: "downlink"},
+				"image":     {Reason: RejectionReasonUnmetDependency, CausedByID: "calibrate"},
+			},
+		},
+		{
+			name: "Dependency finishes too late",
+			tasks: []Task{
+				{ID: "calibrate", StartTime: fixedTime(12), EndTime: fixedTime(13), Priority: 1},
+				{ID: "image", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 5, DependsOn: []string{"calibrate"}},
+			},
+			expectedIDs:   []string{"calibrate"},
+			expectedTotal: 1,
+			rejected: map[string]RejectedTask{
+				"image": {Reason: RejectionReasonUnmetDependency, CausedByID: "calibrate"},
+			},
+		},
+		{
+			name: "Missing dependency and its dependents",
+			tasks: []Task{
+				{ID: "image", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 5, DependsOn: []string{"calibrate"}},
+				{ID: "process", StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 5, DependsOn: []string{"image"}},
+				{ID: "downlink", StartTime: fixedTime(12), EndTime: fixedTime(13), Priority: 2},
+			},
+			expectedIDs:   []string{"downlink"},
+			expectedTotal: 2,
+			rejected: map[string]RejectedTask{
+				"image":   {Reason: RejectionReasonUnmetDependency, CausedByID: "calibrate"},
+				"process": {Reason: RejectionReasonUnmetDependency, CausedByID: "image"},
+			},
+		},
+		{
+			name: "Chain with one of two passes for the dependency",
+			tasks: []Task{
+				{ID: "calibrate", StartTime: fixedTime(8), EndTime: fixedTime(9), Priority: 1},
+				{ID: "uplink", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1, DependsOn: []string{"calibrate"}},
+				{ID: "uplink", StartTime: fixedTime(12), EndTime: fixedTime(13), Priority: 1, DependsOn: []string{"calibrate"}},
+				{ID: "image", StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 5, DependsOn: []string{"uplink"}},
+			},
+			expectedIDs:   []string{"calibrate", "uplink", "image", "uplink"},
+			expectedTotal: 8,
+		},
+		{
+			name: "Mandatory task brings its dependency",
+			tasks: []Task{
+				{ID: "calibrate", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1},
+				{ID: "downlink", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 3},
+				{ID: "image", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 1, Mandatory: true, DependsOn: []string{"calibrate"}},
+			},
+			expectedIDs:   []string{"calibrate", "image"},
+			expectedTotal: 2,
+			rejected: map[string]RejectedTask{
+				"downlink": {Reason: RejectionReasonConflict, CausedByID: "calibrate"},
+			},
+		},
+		{
+			name: "Dependency on another resource ending as the task starts",
+			tasks: []Task{
+				{ID: "command", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1, ResourceID: "ground"},
+				{ID: "image", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 5, DependsOn: []string{"command"}},
+			},
+			expectedIDs:   []string{"command", "image"},
+			expectedTotal: 6,
+		},
+		{
+			name: "Dependency that conflicts with the task",
+			tasks: []Task{
+				{ID: "command", StartTime: fixedTime(10), EndTime: fixedTime(10), Priority: 1},
+				{ID: "image", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 5, DependsOn: []string{"command"}},
+			},
+			expectedIDs:   []string{"command"},
+			expectedTotal: 1,
+			rejected: map[string]RejectedTask{
+				"image": {Reason: RejectionReasonConflict, CausedByID: "command"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chosen, total, rejected, err := newTestScheduler().FindBestSchedule(tt.tasks)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if total != tt.expectedTotal {
+				t.Errorf("Expected total %.2f, got %.2f", tt.expectedTotal, total)
+			}
+			ids := make([]string, len(chosen))
+			for i, task := range chosen {
+				ids[i] = task.ID
+				if task.Mandatory != (task.ID == "image" && tt.tasks[len(tt.tasks)-1].Mandatory) {
+					t.Errorf("Expected %s to keep its Mandatory flag", task.ID)
+				}
+			}
+			if !reflect.DeepEqual(ids, tt.expectedIDs) {
+				t.Errorf("Expected %v, got %v", tt.expectedIDs, ids)
+			}
+			if len(rejected) != len(tt.rejected) {
+				t.Fatalf("Expected %d rejections, got %+v", len(tt.rejected), rejected)
+			}
+			for _, rejection := range rejected {
+				expected := tt.rejected[rejection.TaskRejected.ID]
+				if rejection.Reason != expected.Reason || rejection.CausedByID != expected.CausedByID {
+					t.Errorf("Expected %s rejected as %s caused by %q, got %s caused by %q", rejection.TaskRejected.ID, expected.Reason, expected.CausedByID, rejection.Reason, rejection.CausedByID)
+				}
+			}
+		})
+	}
+}
+
+func TestDependencyErrors(t *testing.T) {
+	s := newTestScheduler()
+	cycle := []Task{
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(9), Priority: 1, DependsOn: []string{"c"}},
+		{ID: "b", StartTime: fixedTime(9), EndTime: fixedTime(9), Priority: 1, DependsOn: []string{"a"}},
+		{ID: "c", StartTime: fixedTime(9), EndTime: fixedTime(9), Priority: 1, DependsOn: []string{"b"}},
+	}
+	var cycleErr ErrDependencyCycle
+	_, _, _, err := s.FindBestSchedule(cycle)
+	if !errors.As(err, &cycleErr) || !reflect.DeepEqual(cycleErr.TaskIDs, []string{"a", "c", "b", "a"}) {
+		t.Errorf("Expected the cycle a -> c -> b -> a, got %v", err)
+	}
+	self := []Task{{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1, DependsOn: []string{"a"}}}
+	if _, _, _, err := s.FindBestSchedule(self); !errors.As(err, &cycleErr) {
+		t.Errorf("Expected a task depending on itself to be a cycle, got %v", err)
+	}
+
+	var infeasible ErrInfeasible
+	unmet := []Task{{ID: "image", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 1, Mandatory: true, DependsOn: []string{"calibrate"}}}
+	if _, _, _, err := s.FindBestSchedule(unmet); !errors.As(err, &infeasible) || infeasible.TaskIDs[0] != "image" {
+		t.Errorf("Expected ErrInfeasible for a mandatory task missing its dependency, got %v", err)
+	}
+	conflicting := []Task{
+		{ID: "calibrate", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1},
+		{ID: "downlink", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1, Mandatory: true},
+		{ID: "image", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 1, Mandatory: true, DependsOn: []string{"calibrate"}},
+	}
+	if _, _, _, err := s.FindBestSchedule(conflicting); !errors.As(err, &infeasible) {
+		t.Errorf("Expected ErrInfeasible for a mandatory dependency that conflicts with a mandatory task, got %v", err)
+	}
+
+	tooMany := make([]Task, 0, maxDependencyTasks+1)
+	for i := 0; i <= maxDependencyTasks; i++ {
+		task := Task{ID: fmt.Sprint(i), StartTime: fixedTime(9).Add(time.Duration(i) * time.Hour), EndTime: fixedTime(10).Add(time.Duration(i) * time.Hour), Priority: 1}
+		if i > 0 {
+			task.DependsOn = []string{fmt.Sprint(i - 1)}
+		}
+		tooMany = append(tooMany, task)
+	}
+	if _, _, _, err := s.FindBestSchedule(tooMany); err == nil {
+		t.Errorf("Expected an error for more than %d tasks in dependencies", maxDependencyTasks)
+	}
+	if _, _, _, err := s.FindBestScheduleCapacity(unmet, 2); err == nil {
+		t.Error("Expected FindBestScheduleCapacity to refuse dependencies")
+	}
+}
+
+func TestDependenciesGreedy(t *testing.T) {
+	tasks := []Task{
+		{ID: "calibrate", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1},
+		{ID: "downlink", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 3},
+		{ID: "image", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 2, DependsOn: []string{"calibrate"}},
+		{ID: "process", StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 2, DependsOn: []string{"image"}},
+	}
+	chosen, total := newTestScheduler().FindScheduleGreedy(tasks)
+	if total != 3 || len(chosen) != 1 || chosen[0].ID != "downlink" {
+		t.Errorf("Expected greedy to drop the chain missing its calibration, got %+v worth %.2f", chosen, total)
+	}
+}
+
+func TestDependenciesMatchBruteForce(t *testing.T) {
+	random := rand.New(rand.NewSource(4))
+	s := newTestScheduler()
+	for round := 0; round < 300; round++ {
+		tasks := make([]Task, 1+random.Intn(8))
+		for i := range tasks {
+			start := fixedTime(9).Add(time.Duration(random.Intn(8)) * 30 * time.Minute)
+			tasks[i] = Task{
+				ID:         fmt.Sprint("t", random.Intn(len(tasks)+1)),
+				StartTime:  start,
+				EndTime:    start.Add(time.Duration(random.Intn(4)) * 30 * time.Minute),
+				Priority:   float64(random.Intn(20)-2) / 3,
+				ResourceID: []string{"", "", "a"}[random.Intn(3)],
+				Mandatory:  random.Intn(12) == 0,
+			}
+		}
+		// Only on IDs of earlier tasks so there are no cycles, plus the odd missing one
+		for i := range tasks {
+			for dependencies := random.Intn(3); dependencies > 0 && i > 0; dependencies-- {
+				tasks[i].DependsOn = append(tasks[i].DependsOn, tasks[random.Intn(i)].ID)
+			}
+			if random.Intn(10) == 0 {
+				tasks[i].DependsOn = append(tasks[i].DependsOn, "missing")
+			}
+		}
+		if checkDependencyCycles(tasks) != nil {
+			continue
+		}
+
+		expected, feasible := bruteForceDependencies(s, tasks)
+		chosen, total, rejected, err := s.FindBestSchedule(tasks)
+		if !feasible {
+			if err == nil {
+				t.Fatalf("Round %d: expected an infeasible schedule for %+v", round, tasks)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Round %d: unexpected error: %v", round, err)
+		}
+		if math.Abs(total-expected) > 1e-9 {
+			t.Fatalf("Round %d: expected %v, got %v for %+v", round, expected, total, tasks)
+		}
+		if len(chosen)+len(rejected) != len(tasks) {
+			t.Fatalf("Round %d: expected every task to be chosen or rejected, got %d and %d", round, len(chosen), len(rejected))
+		}
+		if kept := dropUnmetDependencies(chosen); len(kept) != len(chosen) {
+			t.Fatalf("Round %d: expected every chosen task's dependencies to be met, got %+v", round, chosen)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"sort"
+	"time"
+)
+
+// ScheduleDiff is what changed between two schedules, each list in chronological order.
+// Unchanged holds the tasks as they are in the new schedule.
+type ScheduleDiff struct {
+	Added     []Task `json:"added"`
+	Removed   []Task `json:"removed"`
+	Unchanged []Task `json:"unchanged"`
+}
+
+// diffKey is what matches two tasks without IDs, times are in UTC so the location they were
+// given in doesn't matter
+type diffKey struct {
+	startTime, endTime time.Time
+	priority           float64
+}
+
+// DiffSchedules compares a previously committed schedule against a new one for change review.
+// Tasks are matched by ID first, then any left over by start time, end time and priority, so
+// a task that comes back with a different generated ID (they depend on its position in the
+// input) still counts as unchanged. Order doesn't matter, reordering either schedule gives
+// the same diff. Tasks only in new are Added and tasks only in old are Removed.
+func DiffSchedules(old, new []Task) ScheduleDiff {
+	diff := ScheduleDiff{Added: []Task{}, Removed: []Task{}, Unchanged: []Task{}}
+	oldByID := make(map[string][]int)
+	for i, task := range old {
+		if task.ID != "" {
+			oldByID[task.ID] = append(oldByID[task.ID], i)
+		}
+	}
+	matched := make([]bool, len(old))
+	unmatched := make([]Task, 0)
+	for _, task := range new {
+		if indexes := oldByID[task.ID]; task.ID != "" && len(indexes) > 0 {
+			matched[indexes[0]] = true
+			oldByID[task.ID] = indexes[1:]
+			diff.Unchanged = append(diff.Unchanged, task)
+			continue
+		}
+		unmatched = append(unmatched, task)
+	}
+
+	// Whatever's left pairs up on times and priority, copies pair off one for one
+	oldByKey := make(map[diffKey][]int)
+	for i, task := range old {
+		if !matched[i] {
+			key := diffKeyOf(task)
+			oldByKey[key] = append(oldByKey[key], i)
+		}
+	}
+	for _, task := range unmatched {
+		key := diffKeyOf(task)
+		if indexes := oldByKey[key]; len(indexes) > 0 {
+			matched[indexes[0]] = true
+			oldByKey[key] = indexes[1:]
+			diff.Unchanged = append(diff.Unchanged, task)
+			continue
+		}
+		diff.Added = append(diff.Added, task)
+	}
+	for i, task := range old {
+		if !matched[i] {
+			diff.Removed = append(diff.Removed, task)
+		}
+	}
+
+	for _, tasks := range [][]Task{diff.Added, diff.Removed, diff.Unchanged} {
+		sortChronologically(tasks)
+	}
+	return diff
+}
+
+// diffKeyOf is the key DiffSchedules matches a task on when its ID doesn't match
+func diffKeyOf(task Task) diffKey {
+	return diffKey{startTime: task.StartTime.UTC(), endTime: task.EndTime.UTC(), priority: task.Priority}
+}
+
+// sortChronologically orders tasks by start then end time, ties by ID so the order doesn't
+// depend on the input's
+func sortChronologically(tasks []Task) {
+	sort.SliceStable(tasks, func(first, second int) bool {
+		a, b := tasks[first], tasks[second]
+		if !a.StartTime.Equal(b.StartTime) {
+			return a.StartTime.Before(b.StartTime)
+		}
+		if !a.EndTime.Equal(b.EndTime) {
+			return a.EndTime.Before(b.EndTime)
+		}
+		return a.ID < b.ID
+	})
+}
@@ -0,0 +1,110 @@
+package scheduler
+
+import (
+	"testing"
+)
+
+// Helper function to list the IDs of tasks in order
+func taskIDs(tasks []Task) []string {
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+func TestDiffSchedules(t *testing.T) {
+	morning := Task{ID: "morning", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 2}
+	noon := Task{ID: "noon", StartTime: fixedTime(12), EndTime: fixedTime(13), Priority: 3}
+	evening := Task{ID: "evening", StartTime: fixedTime(18), EndTime: fixedTime(19), Priority: 1}
+	// The same task with the ID regenerated from a different input position
+	renamed := noon
+	renamed.ID = "noon-regenerated"
+	moved := noon
+	moved.ID = "moved"
+	moved.StartTime = fixedTime(14)
+	moved.EndTime = fixedTime(15)
+	tests := []struct {
+		name              string
+		old               []Task
+		new               []Task
+		expectedAdded     []string
+		expectedRemoved   []string
+		expectedUnchanged []string
+	}{
+		{
+			name:              "Identical",
+			old:               []Task{morning, noon},
+			new:               []Task{morning, noon},
+			expectedAdded:     []string{},
+			expectedRemoved:   []string{},
+			expectedUnchanged: []string{"morning", "noon"},
+		},
+		{
+			name:              "Reordered isn't a change",
+			old:               []Task{morning, noon, evening},
+			new:               []Task{evening, morning, noon},
+			expectedAdded:     []string{},
+			expectedRemoved:   []string{},
+			expectedUnchanged: []string{"morning", "noon", "evening"},
+		},
+		{
+			name:              "Regenerated ID matches on times and priority",
+			old:               []Task{noon, morning},
+			new:               []Task{morning, renamed},
+			expectedAdded:     []string{},
+			expectedRemoved:   []string{},
+			expectedUnchanged: []string{"morning", "noon-regenerated"},
+		},
+		{
+			name:              "Added and removed",
+			old:               []Task{morning, noon},
+			new:               []Task{evening, morning, moved},
+			expectedAdded:     []string{"moved", "evening"},
+			expectedRemoved:   []string{"noon"},
+			expectedUnchanged: []string{"morning"},
+		},
+		{
+			name:              "Copies pair off one for one",
+			old:               []Task{noon},
+			new:               []Task{renamed, noon},
+			expectedAdded:     []string{"noon-regenerated"},
+			expectedRemoved:   []string{},
+			expectedUnchanged: []string{"noon"},
+		},
+		{
+			name:              "Empty old schedule",
+			new:               []Task{noon},
+			expectedAdded:     []string{"noon"},
+			expectedRemoved:   []string{},
+			expectedUnchanged: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := DiffSchedules(tt.old, tt.new)
+			for _, check := range []struct {
+				kind     string
+				expected []string
+				got      []Task
+			}{
+				{"added", tt.expectedAdded, diff.Added},
+				{"removed", tt.expectedRemoved, diff.Removed},
+				{"unchanged", tt.expectedUnchanged, diff.Unchanged},
+			} {
+				got := taskIDs(check.got)
+				if len(got) != len(check.expected) {
+					t.Errorf("Expected %s %v, got %v", check.kind, check.expected, got)
+					continue
+				}
+				for i := range got {
+					if got[i] != check.expected[i] {
+						t.Errorf("Expected %s %v, got %v", check.kind, check.expected, got)
+						break
+					}
+				}
+			}
+		})
+	}
+}
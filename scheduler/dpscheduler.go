@@ -2,225 +2,842 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
 	"sort"
+	"time"
+	"turionspace/nei-mission-planner/scheduler/scheduler/core"
 
 	"github.com/uptrace/opentelemetry-go-extra/otelzap"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
 
+// DefaultInstrumentationName is the tracer name used when SchedulerConfig doesn't set one
+const DefaultInstrumentationName = "scheduler"
+
 type SchedulerConfig struct {
 	fx.In
-	Logger *otelzap.Logger
+	// Logger is optional, a Scheduler without one logs nowhere
+	Logger *otelzap.Logger `optional:"true"`
+	// InstrumentationName names the tracer (and any meters) so several schedulers in one
+	// binary can be told apart, it defaults to DefaultInstrumentationName
+	InstrumentationName string `name:"scheduler_instrumentation_name" optional:"true"`
+	// MeterProvider records the scheduler's metrics, the global one is used when it isn't set
+	MeterProvider metric.MeterProvider `optional:"true"`
 }
 
+// NewScheduler builds a Scheduler from cfg. The logger is optional, without one the scheduler
+// logs nowhere, and with no OpenTelemetry set up the global no-op tracer and meter are used.
 func NewScheduler(cfg SchedulerConfig) *Scheduler {
-	return &Scheduler{
-		logger: cfg.Logger,
+	s := &Scheduler{
+		logger:              cfg.Logger,
+		instrumentationName: cfg.InstrumentationName,
+	}
+	if s.logger == nil {
+		s.logger = newNopLogger()
+	}
+	meterProvider := cfg.MeterProvider
+	if meterProvider == nil {
+		meterProvider = otel.GetMeterProvider()
 	}
+	metrics, err := newSchedulerMetrics(meterProvider.Meter(s.name()))
+	if err != nil {
+		s.logger.Warn("Failed to create scheduler metrics", zap.Error(err))
+	}
+	s.metrics = metrics
+	return s
 }
 
 type Scheduler struct {
-	logger *otelzap.Logger
+	logger              *otelzap.Logger
+	instrumentationName string
+	metrics             *schedulerMetrics
+	// options are only set on the per-call copy made by withOptions
+	options scheduleOptions
+}
+
+// withOptions returns a copy of the scheduler configured for a single call so
+// concurrent calls with different options never share state. A Scheduler built
+// without NewScheduler (e.g. Scheduler{}) gets a no-op logger on the copy.
+func (s *Scheduler) withOptions(opts []Option) *Scheduler {
+	configured := *s
+	configured.options = newScheduleOptions(opts)
+	if configured.logger == nil {
+		configured.logger = newNopLogger()
+	}
+	return &configured
+}
+
+// newNopLogger is the logger used when a Scheduler isn't given one, it discards everything
+func newNopLogger() *otelzap.Logger {
+	return otelzap.New(zap.NewNop())
+}
+
+// tracer returns the tracer spans are started from, looked up on every call so a tracer
+// provider registered after the scheduler was built is still picked up
+func (s *Scheduler) tracer() trace.Tracer {
+	return otel.GetTracerProvider().Tracer(s.name())
+}
+
+// name is the instrumentation name used for the scheduler's tracer and meter
+func (s *Scheduler) name() string {
+	if s.instrumentationName == "" {
+		return DefaultInstrumentationName
+	}
+	return s.instrumentationName
+}
+
+// cancellationCheckInterval is how many loop iterations run between checks of the
+// context, checking every iteration would be noticeable on large inputs
+const cancellationCheckInterval = 1024
+
+// checkCancelled returns a wrapped context error if the computation should stop
+func checkCancelled(ctx context.Context, iteration int) error {
+	if iteration%cancellationCheckInterval != 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("scheduling cancelled: %w", err)
+	}
+	return nil
+}
+
+// interval is the task's own times as a core.Interval, the DP's rules all live in core
+func interval(task Task) core.Interval {
+	return core.Interval{Start: task.StartTime, End: task.EndTime}
 }
 
 // isZeroDuration checks if a task has zero duration
 func (s *Scheduler) isZeroDuration(task Task) bool {
-	return !task.EndTime.After(task.StartTime)
+	return interval(task).IsInstant()
+}
+
+// occupied is the task stretched over the time it ties its timeline up for, from SetupBefore
+// ahead of its start to TeardownAfter past its end. Conflicts and the DP's ordering all work
+// on this interval, the task itself keeps its own times. A zero duration task with setup or
+// teardown occupies a real interval, so it then behaves like a regular task.
+func (s *Scheduler) occupied(task Task) Task {
+	if task.SetupBefore == 0 && task.TeardownAfter == 0 {
+		return task
+	}
+	if s.isZeroDuration(task) {
+		task.EndTime = task.StartTime
+	}
+	task.StartTime = task.StartTime.Add(-task.SetupBefore)
+	task.EndTime = task.EndTime.Add(task.TeardownAfter)
+	return task
+}
+
+// gapBetween returns the idle time between two tasks, negative if they overlap.
+// Zero duration tasks are treated as an instant at their start time.
+func (s *Scheduler) gapBetween(task1, task2 Task) time.Duration {
+	return core.Gap(interval(task1), interval(task2))
 }
 
-// tasksConflict checks if two tasks overlap, treating zero duration tasks as regular tasks
+// tasksConflict checks if two tasks overlap, treating zero duration tasks as regular tasks.
+// Tasks pinned to different resources never conflict, and with WithInstantaneousCoexist
+// neither do a zero duration task and a regular one. Setup and teardown count as part of
+// the task, see occupied.
 func (s *Scheduler) tasksConflict(task1, task2 Task) bool {
-	// For zero duration tasks, they conflict if they happen at the same instant
-	if s.isZeroDuration(task1) && s.isZeroDuration(task2) {
-		return task1.StartTime.Equal(task2.StartTime)
+	if s.options.conflictFunc != nil {
+		return s.options.conflictFunc(task1, task2)
+	}
+	task1, task2 = s.occupied(task1), s.occupied(task2)
+	if task1.ResourceID != task2.ResourceID {
+		return false
+	}
+	if s.options.instantaneousCoexist && s.isZeroDuration(task1) != s.isZeroDuration(task2) {
+		return false
 	}
 
-	// If one task is zero duration, it conflicts if it occurs during the other task
-	if s.isZeroDuration(task1) {
-		return !task1.StartTime.Before(task2.StartTime) && !task1.StartTime.After(task2.EndTime)
+	// Overlap, instants and the minimum gap follow the same rules as the plain DP
+	return core.Conflict(interval(task1), interval(task2), s.options.minGap)
+}
+
+// sortKey is the instant a task is ordered by, its end time or for zero duration
+// tasks (which behave like an instant) their start time
+func (s *Scheduler) sortKey(task Task) time.Time {
+	return interval(task).SortKey()
+}
+
+// sortsBefore orders tasks by the sortKey of the interval they occupy. When keys tie, regular
+// tasks go before zero duration ones, see core.Compare. Remaining ties are broken by start time
+// then priority so equal schedules come out the same every run, anything still tied keeps its
+// input order (we sort stably).
+func (s *Scheduler) sortsBefore(task1, task2 Task) bool {
+	task1, task2 = s.occupied(task1), s.occupied(task2)
+	if order := core.Compare(interval(task1), interval(task2)); order != 0 {
+		return order < 0
 	}
-	if s.isZeroDuration(task2) {
-		return !task2.StartTime.Before(task1.StartTime) && !task2.StartTime.After(task1.EndTime)
+	if !task1.StartTime.Equal(task2.StartTime) {
+		return task1.StartTime.Before(task2.StartTime)
 	}
+	return task1.Priority > task2.Priority
+}
 
-	// Regular overlap check for non-zero duration tasks
-	return task1.StartTime.Before(task2.EndTime) && task2.StartTime.Before(task1.EndTime)
+// finishesBefore checks if an earlier sorted task leaves room for the current task to start.
+// This is tasksConflict specialised to a pair where previous sorts before current.
+func (s *Scheduler) finishesBefore(previous, current Task) bool {
+	return core.FinishesBefore(interval(s.occupied(previous)), interval(s.occupied(current)), s.options.minGap)
 }
 
-// findBestPreviousTask finds the most recent task that doesn't overlap with our current task
-// this should work with our dp solution because (need shri to check this)
-// We've sorted by end time
-// When we use this result, we then look up that task's accumulated best priority in bestPriorityUpToTask
-// The dynamic programming table has already calculated the optimal priority for all previous positions
+// findBestPreviousTask finds the latest task (in sorted order) that finishes before our current
+// task starts, the classic weighted interval scheduling predecessor. Tasks must be sorted with
+// sortsBefore, which makes finishesBefore true for a prefix of the earlier tasks and false for
+// the rest, so we can binary search the boundary. Everything up to the returned index is
+// compatible with the current task, which is what lets the DP reuse bestValueUpToTask there.
 func (s *Scheduler) findBestPreviousTask(tasks []Task, currentTaskIndex int) int {
 	currentTask := tasks[currentTaskIndex]
 
-	// Binary search through previous tasks
-	startSearch := 0
-	endSearch := currentTaskIndex - 1
-	bestPreviousTask := -1
+	// A custom conflict check gives no ordering to search on, walk back to the latest
+	// task it's happy with instead
+	if s.options.conflictFunc != nil {
+		for previous := currentTaskIndex - 1; previous >= 0; previous-- {
+			if !s.tasksConflict(tasks[previous], currentTask) {
+				return previous
+			}
+		}
+		return -1
+	}
 
-	for startSearch <= endSearch {
-		middleTask := (startSearch + endSearch) / 2
+	return core.LatestCompatible(currentTaskIndex, func(previous int) bool {
+		return s.finishesBefore(tasks[previous], currentTask)
+	})
+}
 
-		// Check for conflict
-		if !s.tasksConflict(tasks[middleTask], currentTask) {
-			bestPreviousTask = middleTask
-			startSearch = middleTask + 1 // Look for an even later task
-		} else {
-			endSearch = middleTask - 1 // This task overlaps, look earlier
+// findConflictingChosen returns the index of a task in chosen that conflicts with task, or -1.
+// chosen is a schedule in DP order, so every chosen task ends (plus the gap) before the next
+// one starts. That means a binary search finds the first chosen task that's still running when
+// task starts, and only the few after it that start before task ends can conflict.
+func (s *Scheduler) findConflictingChosen(chosen []Task, task Task) int {
+	if s.options.conflictFunc != nil {
+		return s.findConflictingChosenLinear(chosen, task)
+	}
+	occupied := s.occupied(task)
+	first := sort.Search(len(chosen), func(i int) bool {
+		return !s.sortKey(s.occupied(chosen[i])).Add(s.options.minGap).Before(occupied.StartTime)
+	})
+	// Step back one so the inclusive boundary rules for zero duration tasks are covered
+	if first > 0 {
+		first--
+	}
+	latestStart := s.sortKey(occupied).Add(s.options.minGap)
+	for i := first; i < len(chosen) && !s.occupied(chosen[i]).StartTime.After(latestStart); i++ {
+		if s.tasksConflict(task, chosen[i]) {
+			return i
 		}
 	}
+	return -1
+}
 
-	return bestPreviousTask
+// findConflictingChosenLinear is findConflictingChosen checking every chosen task, for
+// conflict checks that don't follow the timeline
+func (s *Scheduler) findConflictingChosenLinear(chosen []Task, task Task) int {
+	for i := range chosen {
+		if s.tasksConflict(task, chosen[i]) {
+			return i
+		}
+	}
+	return -1
 }
 
-// FindBestSchedule finds the combination of tasks that gives us the highest total priority
-func (s *Scheduler) FindBestSchedule(tasks []Task) ([]Task, float64, []RejectedTask) {
-	ctx, span := otel.GetTracerProvider().Tracer("scheduler").Start(context.Background(), "FindBestSchedule")
+// instantTimelineSuffix marks the timeline zero duration tasks get to themselves with
+// WithInstantaneousCoexist, the NUL keeps it from clashing with a real ResourceID
+const instantTimelineSuffix = "\x00instants"
+
+// timelineKey is the timeline a task is scheduled on, the one splitByResource puts it in
+func (s *Scheduler) timelineKey(task Task) string {
+	// A custom conflict check puts everything on one shared timeline
+	if s.options.conflictFunc != nil {
+		return ""
+	}
+	// Instants that coexist with regular tasks only conflict with each other, so they're
+	// scheduled as a timeline of their own next to the resource's regular one
+	if s.options.instantaneousCoexist && s.isZeroDuration(s.occupied(task)) {
+		return task.ResourceID + instantTimelineSuffix
+	}
+	return task.ResourceID
+}
+
+// chosenByTimeline splits a schedule that may span resources back into timelines in DP order,
+// keyed by timelineKey, so findConflictingChosen can search the one a task is on
+func (s *Scheduler) chosenByTimeline(chosen []Task) map[string][]Task {
+	byTimeline := make(map[string][]Task)
+	for _, timeline := range s.splitByResource(append([]Task(nil), chosen...)) {
+		s.sortTasks(timeline)
+		byTimeline[s.timelineKey(timeline[0])] = timeline
+	}
+	return byTimeline
+}
+
+// FindBestSchedule finds the combination of tasks that gives us the highest total priority.
+// An ErrInvalidTask is returned if any task fails validation, which includes a NaN or
+// infinite priority (with a PriorityFunc, the priority at the task's start).
+//
+// Tasks with DependsOn are only chosen after the tasks they depend on. Without them the
+// problem is interval scheduling and solved in O(n log n), but arbitrary precedence makes it
+// NP-hard, so the tasks taking part in dependencies are searched exhaustively on top of the DP
+// and at most 12 of them are supported. An ErrDependencyCycle is returned if dependencies loop.
+func (s *Scheduler) FindBestSchedule(tasks []Task, opts ...Option) ([]Task, float64, []RejectedTask, error) {
+	return s.FindBestScheduleContext(context.Background(), tasks, opts...)
+}
+
+// FindBestSchedule is a convenience wrapper around (*Scheduler).FindBestSchedule for callers
+// that only want the schedule. It runs on a Scheduler with a no-op logger and drops the
+// rejected tasks, an error (e.g. an invalid task) gives a nil schedule and a total of 0. Use
+// a Scheduler for options, rejections and errors.
+func FindBestSchedule(tasks []Task) ([]Task, float64) {
+	s := NewScheduler(SchedulerConfig{})
+	chosenTasks, totalPriority, _, err := s.FindBestSchedule(tasks)
+	if err != nil {
+		return nil, 0
+	}
+	return chosenTasks, totalPriority
+}
+
+// FindBestScheduleContext is FindBestSchedule with a caller supplied context, the span is
+// started from ctx and the computation stops early with a wrapped ctx.Err() if it's cancelled.
+// The mission_id and plan_id members of ctx's baggage, or whichever WithBaggageKeys picks, are
+// copied onto the span and every log line so a run can be traced back to the plan it was for.
+func (s *Scheduler) FindBestScheduleContext(ctx context.Context, tasks []Task, opts ...Option) ([]Task, float64, []RejectedTask, error) {
+	s = s.withOptions(opts)
+	ctx, span := s.tracer().Start(ctx, "FindBestSchedule")
 	defer span.End()
-	logger := s.logger.Ctx(ctx)
+	baggageAttributes, baggageFields := s.baggageMembers(ctx)
+	span.SetAttributes(baggageAttributes...)
+	logger := s.logger.Ctx(ctx).WithOptions(zap.Fields(baggageFields...))
 	span.SetAttributes(attribute.Int("num_tasks", len(tasks)))
 	logger.Info("Starting scheduler", zap.Int("num_tasks", len(tasks)))
-	rejectedTasks := []RejectedTask{}
 	// if there are no tasks, return nil
 	if len(tasks) == 0 {
-		return nil, 0, nil
+		s.metrics.record(ctx, nil, 0, nil)
+		return nil, 0, nil, nil
+	}
+	tasks, rejectedTasks, totalAvailablePriority, err := s.prepareTasks(span, logger, tasks, 1)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	computeStart := time.Now()
+	chosenTasks, totalPriority, clusterRejected, err := s.schedule(ctx, span, tasks)
+	computeDuration := time.Since(computeStart)
+	span.SetAttributes(attribute.Float64("compute_duration_ms", durationMillis(computeDuration)))
+	s.metrics.recordDuration(ctx, computeDuration)
+	if err != nil {
+		span.RecordError(err)
+		logger.Warn("Scheduler failed", zap.Error(err))
+		return nil, 0, nil, err
+	}
+	rejectedTasks = append(rejectedTasks, clusterRejected...)
+	achievedPriority, err := checkTotals(totalPriority, chosenTasks)
+	logger.Debug("Schedule totals", zap.Float64("optimal_priority", totalPriority), zap.Float64("achieved_priority", achievedPriority))
+	if err != nil && s.options.reconstructionCheck && s.options.softConflict == nil {
+		span.RecordError(err)
+		logger.Error("Scheduler returned tasks that don't add up to its total", zap.Error(err))
+		return nil, 0, nil, err
+	}
+	if s.options.outputOrder == PriorityDesc {
+		sort.SliceStable(chosenTasks, func(first, second int) bool {
+			return chosenTasks[first].Priority > chosenTasks[second].Priority
+		})
 	}
 
-	// Sort tasks by end time - zero duration tasks are sorted by their start time
-	sort.Slice(tasks, func(first, second int) bool {
-		// For zero duration tasks, use their start time
-		firstTime := tasks[first].EndTime
-		secondTime := tasks[second].EndTime
-		if s.isZeroDuration(tasks[first]) {
-			firstTime = tasks[first].StartTime
+	if totalAvailablePriority != 0 {
+		span.SetAttributes(attribute.Float64("chosen_priority_ratio", totalPriority/totalAvailablePriority))
+	}
+	span.AddEvent("scheduler_finished", trace.WithAttributes(attribute.Int("num_chosen_tasks", len(chosenTasks)), attribute.Int("num_rejected_tasks", len(rejectedTasks))))
+	logger.Info("Scheduler finished", zap.Int("num_chosen_tasks", len(chosenTasks)), zap.Int("num_rejected_tasks", len(rejectedTasks)))
+	s.metrics.record(ctx, chosenTasks, totalPriority, rejectedTasks)
+	return chosenTasks, totalPriority, rejectedTasks, nil
+}
+
+// prepareTasks runs the input through everything that comes before the DP, shared by
+// FindBestScheduleContext and ScheduleStream so both refuse and drop the same tasks. It works
+// on a copy, refuses invalid tasks, ambiguous ties under WithStrictTies and dependency cycles,
+// fills in IDs and priorities, then drops duplicates and tasks that can never be scheduled.
+// Copies of a task are only duplicates past one per resource, see rejectDuplicates. It
+// returns what's left, what was dropped and the total priority on offer, errors are already
+// recorded on span and logged.
+func (s *Scheduler) prepareTasks(span trace.Span, logger otelzap.LoggerWithCtx, tasks []Task, resources int) ([]Task, []RejectedTask, float64, error) {
+	// Work on a copy, filling in IDs and sorting would otherwise scramble the caller's slice
+	tasks = append([]Task(nil), tasks...)
+	// Bad input sorts and conflicts in surprising ways, so refuse it up front
+	if err := s.validateTasks(tasks); err != nil {
+		span.RecordError(err)
+		logger.Warn("Invalid task passed to scheduler", zap.Error(err))
+		return nil, nil, 0, err
+	}
+	if s.options.strictTies {
+		if err := s.checkStrictTies(tasks); err != nil {
+			span.RecordError(err)
+			logger.Warn("Ambiguous tie passed to scheduler", zap.Error(err))
+			return nil, nil, 0, err
 		}
-		if s.isZeroDuration(tasks[second]) {
-			secondTime = tasks[second].StartTime
+	}
+	s.assignMissingIDs(tasks)
+	if err := checkDependencyCycles(tasks); err != nil {
+		span.RecordError(err)
+		logger.Warn("Invalid task passed to scheduler", zap.Error(err))
+		return nil, nil, 0, err
+	}
+	s.resolvePriorities(tasks)
+	totalAvailablePriority := 0.0
+	if len(tasks) > 0 {
+		totalAvailablePriority = s.setInputAttributes(span, tasks)
+	}
+
+	// Drop duplicates and anything that can never be scheduled before it takes part in the DP
+	tasks, duplicates := s.rejectDuplicates(span, tasks, resources)
+	tasks, rejectedTasks, err := s.rejectUnschedulable(span, tasks)
+	if err != nil {
+		span.RecordError(err)
+		logger.Warn("Scheduler failed", zap.Error(err))
+		return nil, nil, 0, err
+	}
+	return tasks, append(duplicates, rejectedTasks...), totalAvailablePriority, nil
+}
+
+// defaultBaggageKeys are the baggage members FindBestScheduleContext copies when
+// WithBaggageKeys isn't given, the ones set on requests coming from mission planning
+var defaultBaggageKeys = []string{"mission_id", "plan_id"}
+
+// baggageMembers picks the configured members out of ctx's baggage as span attributes and
+// log fields under the member's own key, members that aren't there are left out
+func (s *Scheduler) baggageMembers(ctx context.Context) ([]attribute.KeyValue, []zap.Field) {
+	keys := s.options.baggageKeys
+	if keys == nil {
+		keys = defaultBaggageKeys
+	}
+	bag := baggage.FromContext(ctx)
+	attributes := make([]attribute.KeyValue, 0, len(keys))
+	fields := make([]zap.Field, 0, len(keys))
+	for _, key := range keys {
+		member := bag.Member(key)
+		if member.Key() == "" {
+			continue
 		}
-		return firstTime.Before(secondTime)
-	})
-	// Initialize our dynamic programming arrays
-	numTasks := len(tasks)
-	// bestPriorityUpToTask stores the best priority we can get up to a given task
-	bestPriorityUpToTask := make([]float64, numTasks)
-	// previousTaskChosen stores the index of the task that was chosen before the current task
-	previousTaskChosen := make([]int, numTasks)
-
-	// Base case
-	bestPriorityUpToTask[0] = tasks[0].Priority
-	previousTaskChosen[0] = -1
-
-	// For each task, figure out the best way to include it
-	for currentTask := 1; currentTask < numTasks; currentTask++ {
-		// Find the index of the latest task that finishes before the current task starts
-		// and does not overlap with it. This is the best candidate to have been
-		// included in the schedule *before* the current task.
-		bestPrevious := s.findBestPreviousTask(tasks, currentTask)
-
-		// Calculate the total priority if we *include* the current task.
-		priorityIfIncluded := tasks[currentTask].Priority
-		// If there's a compatible previous task, add its accumulated priority to the
-		// priority we get by including the current task. This is the core of the DP logic:
-		// we're reusing previously calculated optimal solutions for subproblems.
-		if bestPrevious != -1 {
-			priorityIfIncluded += bestPriorityUpToTask[bestPrevious]
-		}
-
-		// Calculate the total priority if we *exclude* the current task.
-		// In this case, the best priority we can achieve is simply the best priority
-		// we could achieve up to the *previous* task (currentTask - 1).
-		priorityIfExcluded := bestPriorityUpToTask[currentTask-1]
-
-		// Now, we make the optimal choice: do we include the current task or not?
-		if priorityIfIncluded > priorityIfExcluded {
-			// Including the current task gives us a higher total priority.
-			// So, we update the bestPriorityUpToTask for the current task to reflect this.
-			bestPriorityUpToTask[currentTask] = priorityIfIncluded
-			// We also record the index of the previous task that was part of this optimal
-			// solution. This is crucial for reconstructing the actual schedule later.
-			previousTaskChosen[currentTask] = bestPrevious
-		} else {
-			// Excluding the current task gives us a higher or equal total priority.
-			// We keep the best priority we had up to the previous task.
-			bestPriorityUpToTask[currentTask] = priorityIfExcluded
-			// If we exclude the current task, the previous task chosen remains the same
-			// as the one chosen for the previous iteration. This maintains the chain
-			// of chosen tasks for backtracking.
-			previousTaskChosen[currentTask] = previousTaskChosen[currentTask-1]
-			// Record low priority rejection
-			span.AddEvent("task_rejected", trace.WithAttributes(attribute.String("reason", "low_priority")))
+		attributes = append(attributes, attribute.String(key, member.Value()))
+		fields = append(fields, zap.String(key, member.Value()))
+	}
+	return attributes, fields
+}
+
+// setInputAttributes records the time span the input covers and the total priority on offer,
+// which is enough to spot a degenerate input from the trace alone. It returns the total.
+func (s *Scheduler) setInputAttributes(span trace.Span, tasks []Task) float64 {
+	minStart, maxEnd := tasks[0].StartTime, tasks[0].StartTime
+	totalAvailablePriority := 0.0
+	for _, task := range tasks {
+		if task.StartTime.Before(minStart) {
+			minStart = task.StartTime
+		}
+		// Zero and negative duration tasks end at their start time
+		if end := s.sortKey(task); end.After(maxEnd) {
+			maxEnd = end
+		}
+		totalAvailablePriority += task.Priority
+	}
+	span.SetAttributes(
+		attribute.String("min_start_time", minStart.Format(time.RFC3339)),
+		attribute.String("max_end_time", maxEnd.Format(time.RFC3339)),
+		attribute.Float64("total_available_priority", totalAvailablePriority),
+	)
+	return totalAvailablePriority
+}
+
+// unschedulableReason checks constraints that rule a task out on its own, regardless of
+// which other tasks get chosen
+func (s *Scheduler) unschedulableReason(task Task) (RejectionReason, bool) {
+	if s.outsideWindow(task) {
+		return RejectionReasonOutsideWindow, true
+	}
+	// A zero duration task finishes at its start time, which is what sortKey gives us
+	if !task.Deadline.IsZero() && s.sortKey(task).After(task.Deadline) {
+		return RejectionReasonDeadlineMissed, true
+	}
+	// Tasks have fixed windows, so starting early can't be fixed by sliding the task later
+	if !task.NotBefore.IsZero() && task.StartTime.Before(task.NotBefore) {
+		return RejectionReasonNotReady, true
+	}
+	return "", false
+}
+
+// outsideWindow checks if a task isn't wholly inside the WithWindow planning window. With
+// WithClampToWindow tasks have already been clamped, so only ones that were entirely outside
+// it are left sticking out.
+func (s *Scheduler) outsideWindow(task Task) bool {
+	if !s.options.window {
+		return false
+	}
+	return task.StartTime.Before(s.options.windowStart) || s.sortKey(task).After(s.options.windowEnd)
+}
+
+// snap rounds a task's start and end to the nearest multiple of the WithSnap granularity,
+// leaving it as it was without one. Rounding never reorders two times, so a task can't come
+// out ending before it starts, but a short one can come out zero duration.
+func (s *Scheduler) snap(task Task) Task {
+	if s.options.snap <= 0 {
+		return task
+	}
+	task.StartTime = task.StartTime.Round(s.options.snap)
+	task.EndTime = task.EndTime.Round(s.options.snap)
+	return task
+}
+
+// clampToWindow cuts a task that's partly outside the planning window down to the part inside
+// it under WithClampToWindow. Anything else, including a task with no part inside the window
+// (touching an edge from outside doesn't count), comes back as it was.
+func (s *Scheduler) clampToWindow(task Task) Task {
+	if !s.options.window || !s.options.clampToWindow || !s.outsideWindow(task) {
+		return task
+	}
+	end := s.sortKey(task)
+	overlaps := task.StartTime.Before(s.options.windowEnd) && end.After(s.options.windowStart)
+	if !overlaps {
+		return task
+	}
+	if task.StartTime.Before(s.options.windowStart) {
+		task.StartTime = s.options.windowStart
+	}
+	if end.After(s.options.windowEnd) {
+		task.EndTime = s.options.windowEnd
+	}
+	return task
+}
+
+// duplicateKey is everything about a task that affects how it's scheduled, two tasks with the
+// same key are interchangeable whatever their IDs. Times are in UTC so the location they were
+// given in doesn't matter.
+type duplicateKey struct {
+	startTime, endTime, deadline, notBefore time.Time
+	setupBefore, teardownAfter              time.Duration
+	priority                                float64
+	resourceID, groupID                     string
+	mandatory                               bool
+}
+
+// rejectDuplicates drops every task that's an exact copy of earlier ones it conflicts with
+// once there are already copies of them, one for each resource that could run one (so just
+// the first on a single timeline). Each copy is rejected as a duplicate of the first, so it
+// isn't reported as conflicting with its own twin. Tasks in bundles are left alone
+// (a bundle with two identical tasks in it can't be chosen whole), as are tasks in dependencies
+// since the copy might be the one with the ID depended on, and so is everything with
+// WithSoftConflict since copies can then both be chosen.
+func (s *Scheduler) rejectDuplicates(span trace.Span, tasks []Task, copies int) ([]Task, []RejectedTask) {
+	rejectedTasks := []RejectedTask{}
+	if s.options.softConflict != nil {
+		return tasks, rejectedTasks
+	}
+	dependedOn := make(map[string]bool)
+	for _, task := range tasks {
+		for _, dependency := range task.DependsOn {
+			dependedOn[dependency] = true
+		}
+	}
+	firstOf := make(map[duplicateKey]int, len(tasks))
+	keptOf := make(map[duplicateKey]int, len(tasks))
+	unique := make([]Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task.BundleID != "" || len(task.DependsOn) > 0 || dependedOn[task.ID] {
+			unique = append(unique, task)
+			continue
+		}
+		key := duplicateKey{
+			startTime:     task.StartTime.UTC(),
+			endTime:       task.EndTime.UTC(),
+			deadline:      task.Deadline.UTC(),
+			notBefore:     task.NotBefore.UTC(),
+			setupBefore:   task.SetupBefore,
+			teardownAfter: task.TeardownAfter,
+			priority:      task.Priority,
+			resourceID:    task.ResourceID,
+			groupID:       task.GroupID,
+			mandatory:     task.Mandatory,
+		}
+		if first, seen := firstOf[key]; seen && keptOf[key] >= copies && s.tasksConflict(unique[first], task) {
+			span.AddEvent("task_rejected", trace.WithAttributes(attribute.String("reason", RejectionReasonDuplicate.String())))
 			rejectedTasks = append(rejectedTasks, RejectedTask{
-				TaskRejected: tasks[currentTask],
-				Reason:       RejectionReasonLowPriority,
+				TaskRejected: task,
+				CausedByID:   unique[first].ID,
+				Reason:       RejectionReasonDuplicate,
 			})
+			continue
 		}
+		if _, seen := firstOf[key]; !seen {
+			firstOf[key] = len(unique)
+		}
+		keptOf[key]++
+		unique = append(unique, task)
+	}
+	// Nothing dropped, keep working on the caller's slice
+	if len(rejectedTasks) == 0 {
+		return tasks, rejectedTasks
 	}
+	return unique, rejectedTasks
+}
 
-	// Build our list of chosen tasks
-	chosenTasks := make([]Task, 0)
-	// Build chosen tasks list and track conflicts
-	chosenIndexes := make(map[int]bool)
-
-	for i := numTasks - 1; i >= 0; {
-		if i == 0 || bestPriorityUpToTask[i] != bestPriorityUpToTask[i-1] {
-			chosenTasks = append(chosenTasks, tasks[i])
-			chosenIndexes[i] = true
-			i = previousTaskChosen[i]
-		} else {
-			i--
-		}
-	}
-
-	// Find conflict rejections
-	for i := 0; i < numTasks; i++ {
-		if !chosenIndexes[i] {
-			// Check if already rejected for low priority
-			alreadyRejected := false
-			for _, rejected := range rejectedTasks {
-				if rejected.TaskRejected == tasks[i] {
-					alreadyRejected = true
-					break
-				}
+// rejectUnschedulable splits out the tasks that can't be scheduled at all, returning the
+// remaining tasks and a rejection for each one dropped. A mandatory task that can't be
+// scheduled makes the whole schedule infeasible. Every task is snapped to WithSnap's
+// granularity and clamped to the window first, and the tasks returned carry those times.
+func (s *Scheduler) rejectUnschedulable(span trace.Span, tasks []Task) ([]Task, []RejectedTask, error) {
+	rejectedTasks := []RejectedTask{}
+	schedulable := make([]Task, 0, len(tasks))
+	for _, task := range tasks {
+		task = s.clampToWindow(s.snap(task))
+		if reason, rejected := s.unschedulableReason(task); rejected {
+			if task.Mandatory {
+				return nil, nil, ErrInfeasible{TaskIDs: []string{task.ID}, Reason: "mandatory task rejected as " + reason.String()}
 			}
+			span.AddEvent("task_rejected", trace.WithAttributes(attribute.String("reason", reason.String())))
+			rejectedTasks = append(rejectedTasks, RejectedTask{
+				TaskRejected: task,
+				Reason:       reason,
+			})
+			continue
+		}
+		schedulable = append(schedulable, task)
+	}
+	// Nothing dropped, keep working on the caller's slice
+	if len(rejectedTasks) == 0 && !s.options.clampToWindow && s.options.snap <= 0 {
+		return tasks, rejectedTasks, nil
+	}
 
-			if !alreadyRejected {
-				// Find conflicting task
-				for j := 0; j < numTasks; j++ {
-					if chosenIndexes[j] && s.tasksConflict(tasks[i], tasks[j]) {
-						span.AddEvent("task_rejected", trace.WithAttributes(attribute.String("reason", "conflict")))
-						rejectedTasks = append(rejectedTasks, RejectedTask{
-							TaskRejected: tasks[i],
-							CausedBy:     &tasks[j],
-							Reason:       RejectionReasonConflict,
-						})
-						break
-					}
-				}
-			}
+	// A bundle missing a task can't be chosen whole, so the rest of it goes too
+	brokenBundles := make(map[string]string)
+	for _, rejected := range rejectedTasks {
+		if rejected.TaskRejected.BundleID != "" {
+			brokenBundles[rejected.TaskRejected.BundleID] = rejected.TaskRejected.ID
 		}
 	}
-	for i := numTasks - 1; i >= 0; {
-		if i == 0 || bestPriorityUpToTask[i] != bestPriorityUpToTask[i-1] {
-			chosenTasks = append(chosenTasks, tasks[i])
-			i = previousTaskChosen[i]
-		} else {
-			i--
+	if len(brokenBundles) == 0 {
+		return schedulable, rejectedTasks, nil
+	}
+	complete := schedulable[:0]
+	for _, task := range schedulable {
+		rejectedID, broken := brokenBundles[task.BundleID]
+		if !broken {
+			complete = append(complete, task)
+			continue
+		}
+		if task.Mandatory {
+			return nil, nil, ErrInfeasible{TaskIDs: []string{task.ID, rejectedID}, Reason: "mandatory task's bundle can't be scheduled whole"}
 		}
+		span.AddEvent("task_rejected", trace.WithAttributes(attribute.String("reason", RejectionReasonBundleInfeasible.String())))
+		rejectedTasks = append(rejectedTasks, RejectedTask{
+			TaskRejected: task,
+			CausedByID:   rejectedID,
+			Reason:       RejectionReasonBundleInfeasible,
+		})
 	}
+	return complete, rejectedTasks, nil
+}
 
-	// Put tasks in chronological order
-	for i := 0; i < len(chosenTasks)/2; i++ {
-		chosenTasks[i], chosenTasks[len(chosenTasks)-1-i] = chosenTasks[len(chosenTasks)-1-i], chosenTasks[i]
+// scheduleResources finds the best schedule for tasks that have already been through
+// rejectUnschedulable. Tasks on different resources can never conflict, so every resource is
+// its own independent timeline and the best schedule is just the best of each combined.
+func (s *Scheduler) scheduleResources(ctx context.Context, span trace.Span, tasks []Task) ([]Task, float64, []RejectedTask, error) {
+	timelines := s.splitByResource(tasks)
+	chosenTasks := make([]Task, 0)
+	totalPriority := 0.0
+	rejectedTasks := []RejectedTask{}
+	for _, timeline := range timelines {
+		timelineChosen, timelinePriority, timelineRejected, err := s.scheduleAroundMandatory(ctx, span, timeline)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		chosenTasks = append(chosenTasks, timelineChosen...)
+		totalPriority += timelinePriority
+		rejectedTasks = append(rejectedTasks, timelineRejected...)
 	}
-	span.AddEvent("scheduler_finished", trace.WithAttributes(attribute.Int("num_chosen_tasks", len(chosenTasks)), attribute.Int("num_rejected_tasks", len(rejectedTasks))))
-	logger.Info("Scheduler finished", zap.Int("num_chosen_tasks", len(chosenTasks)), zap.Int("num_rejected_tasks", len(rejectedTasks)))
-	return chosenTasks, bestPriorityUpToTask[numTasks-1], rejectedTasks
+	if len(timelines) > 1 {
+		sort.SliceStable(chosenTasks, func(first, second int) bool {
+			return chosenTasks[first].StartTime.Before(chosenTasks[second].StartTime)
+		})
+	}
+	return chosenTasks, totalPriority, rejectedTasks, nil
+}
+
+// splitByResource groups tasks by their ResourceID (their timelineKey, which also separates
+// coexisting instants), keeping the input order within each group
+func (s *Scheduler) splitByResource(tasks []Task) [][]Task {
+	// A custom conflict check may well make tasks on different resources conflict
+	if s.options.conflictFunc != nil {
+		return [][]Task{tasks}
+	}
+	groupIndex := make(map[string]int)
+	groups := make([][]Task, 0)
+	for _, task := range tasks {
+		key := s.timelineKey(task)
+		index, ok := groupIndex[key]
+		if !ok {
+			index = len(groups)
+			groupIndex[key] = index
+			groups = append(groups, make([]Task, 0))
+		}
+		groups[index] = append(groups[index], task)
+	}
+	// Keep the common single timeline case working on the caller's slice
+	if len(groups) == 1 {
+		return [][]Task{tasks}
+	}
+	return groups
+}
+
+// scheduleTimeline runs the dynamic programming solution over tasks that all share one
+// timeline, one phase at a time: sort, fill in the DP table, walk it back for the chosen tasks,
+// then work out why every other task was left out
+func (s *Scheduler) scheduleTimeline(ctx context.Context, span trace.Span, tasks []Task) ([]Task, float64, []RejectedTask, error) {
+	s.sortForDP(tasks)
+	table, err := s.computeDP(ctx, tasks)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	s.traceTimeline(tasks, table)
+	chosenTasks, chosenIndexes := table.reconstruct(tasks)
+	rejectedTasks, err := s.classifyRejections(ctx, span, tasks, table, chosenTasks, chosenIndexes)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return chosenTasks, table.bestValueUpToTask[len(tasks)-1].priority, rejectedTasks, nil
+}
+
+// sortTasks puts tasks in the order the DP visits them, by end time with zero duration tasks
+// at their start time, see sortsBefore
+func (s *Scheduler) sortTasks(tasks []Task) {
+	sort.SliceStable(tasks, func(first, second int) bool {
+		return s.sortsBefore(tasks[first], tasks[second])
+	})
+}
+
+// sortForDP is sortTasks for the DP's input, skipped when WithPreSorted says the tasks
+// already come in an order the DP can use (checked in scheduler_debug builds)
+func (s *Scheduler) sortForDP(tasks []Task) {
+	if !s.trustPreSorted() {
+		s.sortTasks(tasks)
+		return
+	}
+	if debugChecks && !s.inDPOrder(tasks) {
+		panic("scheduler: tasks passed with WithPreSorted aren't in the order it documents")
+	}
+}
+
+// trustPreSorted is whether the tasks reaching the solvers are still in the caller's order
+// under WithPreSorted. Snapping and clamping move tasks' times after the caller sorted them,
+// so with either of them the tasks are sorted anyway.
+func (s *Scheduler) trustPreSorted() bool {
+	return s.options.preSorted && s.options.snap <= 0 && !s.options.clampToWindow
+}
+
+// inDPOrder checks tasks are in the order WithPreSorted asks for: by the sortKey of the
+// interval they occupy, regular tasks before zero duration ones on the same key. It's the
+// part of sortsBefore the DP's correctness depends on, the rest only picks between ties.
+func (s *Scheduler) inDPOrder(tasks []Task) bool {
+	return sort.SliceIsSorted(tasks, func(first, second int) bool {
+		return core.Compare(interval(s.occupied(tasks[first])), interval(s.occupied(tasks[second]))) < 0
+	})
+}
+
+// dpTable is the state computeDP builds up, every slice is indexed like the sorted tasks
+type dpTable struct {
+	// bestValueUpToTask stores the best value (priority plus any tie breaking totals
+	// the objective cares about) we can get up to a given task
+	bestValueUpToTask []scheduleValue
+	// previousTaskChosen stores the index of the task that was chosen before the current task,
+	// int32 halves the memory and no timeline gets anywhere near 2 billion tasks
+	previousTaskChosen []int32
+	// taskIncluded records whether the best schedule up to a task includes that task
+	taskIncluded []bool
+	// lowPriority records the tasks already rejected in the forward pass, by index since two
+	// different tasks can have identical fields
+	lowPriority []bool
+	// predecessors keeps every findBestPreviousTask result, only WithTrace needs them
+	predecessors []int
+}
+
+// computeDP runs the weighted interval scheduling recurrence over tasks sorted with sortTasks,
+// through core.Compute with the objective's values, the predecessor search the options call for
+// and cancellation. tasks must not be empty.
+func (s *Scheduler) computeDP(ctx context.Context, tasks []Task) (dpTable, error) {
+	var predecessors []int
+	if s.options.trace != nil {
+		predecessors = make([]int, len(tasks))
+		predecessors[0] = -1
+	}
+	objective := core.Objective[scheduleValue]{
+		Value:  func(i int) scheduleValue { return s.taskValue(tasks[i]) },
+		Plus:   scheduleValue.plus,
+		Better: s.betterValue,
+	}
+	previous := func(current int) int {
+		bestPrevious := s.findBestPreviousTask(tasks, current)
+		if predecessors != nil {
+			predecessors[current] = bestPrevious
+		}
+		return bestPrevious
+	}
+	table, err := core.Compute(len(tasks), objective, previous, func(i int) error {
+		return checkCancelled(ctx, i)
+	})
+	if err != nil {
+		return dpTable{}, err
+	}
+	// Tasks beaten in the forward pass get their low priority rejection in classifyRejections
+	// once we know how many there are
+	return dpTable{
+		bestValueUpToTask:  table.Best,
+		previousTaskChosen: table.Previous,
+		taskIncluded:       table.Included,
+		lowPriority:        table.Beaten,
+		predecessors:       predecessors,
+	}, nil
+}
+
+// reconstruct backtracks through the table for the chosen tasks in chronological order, along
+// with which indexes they were
+func (table dpTable) reconstruct(tasks []Task) ([]Task, []bool) {
+	chosen, chosenIndexes := core.Table[scheduleValue]{Previous: table.previousTaskChosen, Included: table.taskIncluded}.Reconstruct()
+	chosenTasks := make([]Task, len(chosen))
+	for i, index := range chosen {
+		chosenTasks[i] = tasks[index]
+	}
+	return chosenTasks, chosenIndexes
+}
+
+// classifyRejections builds a rejection for every task that wasn't chosen, either beaten in
+// the forward pass or pushed out by a chosen task it conflicts with
+func (s *Scheduler) classifyRejections(ctx context.Context, span trace.Span, tasks []Task, table dpTable, chosenTasks []Task, chosenIndexes []bool) ([]RejectedTask, error) {
+	rejectedTasks := make([]RejectedTask, 0, len(tasks)-len(chosenTasks))
+	for i := range tasks {
+		if err := checkCancelled(ctx, i); err != nil {
+			return nil, err
+		}
+		if chosenIndexes[i] {
+			continue
+		}
+		if table.lowPriority[i] {
+			span.AddEvent("task_rejected", trace.WithAttributes(attribute.String("reason", RejectionReasonLowPriority.String())))
+			rejectedTasks = append(rejectedTasks, RejectedTask{
+				TaskRejected: tasks[i],
+				Reason:       RejectionReasonLowPriority,
+			})
+			continue
+		}
+		if conflicting := s.findConflictingChosen(chosenTasks, tasks[i]); conflicting != -1 {
+			span.AddEvent("task_rejected", trace.WithAttributes(attribute.String("reason", RejectionReasonConflict.String())))
+			rejectedTasks = append(rejectedTasks, RejectedTask{
+				TaskRejected: tasks[i],
+				CausedByID:   chosenTasks[conflicting].ID,
+				Reason:       RejectionReasonConflict,
+			})
+		}
+	}
+	return rejectedTasks, nil
 }
 
 var Module = fx.Provide(NewScheduler)
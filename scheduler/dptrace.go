@@ -0,0 +1,55 @@
+package scheduler
+
+import "sync"
+
+// ScheduleTrace collects the DP tables behind a schedule so a UI can explain why a task was or
+// wasn't chosen, pass one to WithTrace to have it filled in
+type ScheduleTrace struct {
+	mu sync.Mutex
+	// Timelines has one entry per run of the interval DP, one for each cluster and resource
+	Timelines []TimelineTrace `json:"timelines"`
+}
+
+// TimelineTrace is the DP table for one timeline. Every slice is indexed the same way as Tasks,
+// which is the order the DP visited them in (by end time), not the input order.
+type TimelineTrace struct {
+	Tasks []Task `json:"tasks"`
+	// BestPriorityUpToTask is the best total priority using only Tasks[0] to Tasks[i]
+	BestPriorityUpToTask []float64 `json:"best_priority_up_to_task"`
+	// PreviousTaskChosen is the task chosen before Tasks[i] in the best schedule up to it, -1
+	// if there isn't one
+	PreviousTaskChosen []int `json:"previous_task_chosen"`
+	// Predecessor is the latest task Tasks[i] could follow without conflicting, -1 if none
+	// can, including Tasks[i] is worth its priority plus BestPriorityUpToTask there
+	Predecessor []int `json:"predecessor"`
+	// Included records whether the best schedule up to Tasks[i] includes it, if it doesn't the
+	// task lost to BestPriorityUpToTask[i-1]
+	Included []bool `json:"included"`
+}
+
+// add appends a timeline, the DP can run for several clusters at once with WithParallel
+func (t *ScheduleTrace) add(timeline TimelineTrace) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Timelines = append(t.Timelines, timeline)
+}
+
+// traceTimeline records a finished DP table when WithTrace asked for it, copying the tables so
+// the trace doesn't hold on to the DP's working memory
+func (s *Scheduler) traceTimeline(tasks []Task, table dpTable) {
+	if s.options.trace == nil {
+		return
+	}
+	timeline := TimelineTrace{
+		Tasks:                append([]Task(nil), tasks...),
+		BestPriorityUpToTask: make([]float64, len(tasks)),
+		PreviousTaskChosen:   make([]int, len(tasks)),
+		Predecessor:          table.predecessors,
+		Included:             append([]bool(nil), table.taskIncluded...),
+	}
+	for i := range tasks {
+		timeline.BestPriorityUpToTask[i] = table.bestValueUpToTask[i].priority
+		timeline.PreviousTaskChosen[i] = int(table.previousTaskChosen[i])
+	}
+	s.options.trace.add(timeline)
+}
@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithTrace(t *testing.T) {
+	tasks := []Task{
+		{ID: "c", StartTime: fixedTime(11), EndTime: fixedTime(13), Priority: 4},
+		{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 3},
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 5},
+		{ID: "other", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1, ResourceID: "antenna"},
+	}
+	trace := &ScheduleTrace{}
+	if _, _, _, err := newTestScheduler().FindBestSchedule(tasks, WithTrace(trace)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(trace.Timelines) != 2 {
+		t.Fatalf("Expected a timeline per resource, got %d", len(trace.Timelines))
+	}
+	timeline := trace.Timelines[0]
+	ids := make([]string, len(timeline.Tasks))
+	for i, task := range timeline.Tasks {
+		ids[i] = task.ID
+	}
+	if !reflect.DeepEqual(ids, []string{"a", "b", "c"}) {
+		t.Errorf("Expected tasks in DP order, got %v", ids)
+	}
+	if !reflect.DeepEqual(timeline.BestPriorityUpToTask, []float64{5, 5, 9}) {
+		t.Errorf("Unexpected best priorities %v", timeline.BestPriorityUpToTask)
+	}
+	if !reflect.DeepEqual(timeline.Predecessor, []int{-1, -1, 0}) {
+		t.Errorf("Unexpected predecessors %v", timeline.Predecessor)
+	}
+	if !reflect.DeepEqual(timeline.PreviousTaskChosen, []int{-1, -1, 0}) {
+		t.Errorf("Unexpected previous tasks chosen %v", timeline.PreviousTaskChosen)
+	}
+	if !reflect.DeepEqual(timeline.Included, []bool{true, false, true}) {
+		t.Errorf("Unexpected included flags %v", timeline.Included)
+	}
+}
@@ -0,0 +1,235 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// DefaultPlacementStep is how far apart FindBestScheduleFlex tries start times when
+// WithPlacementStep isn't given
+const DefaultPlacementStep = time.Minute
+
+// maxPlacements caps how many start times a single FlexTask can expand into, same idea as
+// maxRecurrences
+const maxPlacements = 100000
+
+// FlexTask is a task that can run anywhere in a window instead of at a fixed time, e.g. a
+// calibration that needs an hour sometime this afternoon
+type FlexTask struct {
+	ID       string        `json:"id"`
+	Duration time.Duration `json:"duration"`
+	// EarliestStart and LatestStart bound where the task can start, both inclusive
+	EarliestStart time.Time `json:"earliest_start"`
+	LatestStart   time.Time `json:"latest_start"`
+	Priority      float64   `json:"priority"`
+	ResourceID    string    `json:"resource_id,omitempty"`
+	// PriorityFunc optionally makes the priority depend on where the task is placed, see
+	// Task.PriorityFunc
+	PriorityFunc func(placedStart time.Time) float64 `json:"-"`
+}
+
+// placements expands a FlexTask into a fixed task for every start it could have
+func (f FlexTask) placements(step time.Duration) ([]Task, error) {
+	placements := make([]Task, 0)
+	for start := f.EarliestStart; ; start = start.Add(step) {
+		// The last step usually overshoots, try the latest start instead so a task that only
+		// fits right at the end of its window still gets the chance
+		if start.After(f.LatestStart) {
+			start = f.LatestStart
+		}
+		if len(placements) >= maxPlacements {
+			return nil, fmt.Errorf("expands to more than %d placements, use a larger WithPlacementStep", maxPlacements)
+		}
+		placements = append(placements, Task{
+			StartTime:    start,
+			EndTime:      start.Add(f.Duration),
+			Priority:     f.Priority,
+			ResourceID:   f.ResourceID,
+			PriorityFunc: f.PriorityFunc,
+		})
+		if !start.Before(f.LatestStart) {
+			return placements, nil
+		}
+	}
+}
+
+// FindBestScheduleFlex finds the best schedule for a mix of fixed tasks and FlexTasks, sliding
+// each FlexTask to whichever start gives the best total. A placed FlexTask comes back as a
+// Task with the FlexTask's ID. FlexTasks that can't be placed anywhere without conflicting
+// with the chosen tasks are rejected with RejectionReasonNoFeasiblePlacement. The fixed tasks
+// are checked and dropped just like FindBestSchedule's, a placement the window or a deadline
+// rules out is simply not tried, and a FlexTask with none left is rejected for that reason.
+//
+// Every start from EarliestStart in steps of WithPlacementStep (plus LatestStart) becomes a
+// candidate task for the interval DP. The DP doesn't know two candidates are the same task,
+// but when a FlexTask's window is shorter than its duration all of its candidates overlap, so
+// it picks at most one and the result is optimal over those starts. With wider windows a
+// FlexTask can come back placed more than once, it's then pinned to its best placement and
+// the rest are re-solved, which is a good schedule but not necessarily the best one.
+func (s *Scheduler) FindBestScheduleFlex(fixed []Task, flex []FlexTask, opts ...Option) ([]Task, float64, []RejectedTask, error) {
+	s = s.withOptions(opts)
+	ctx, span := s.tracer().Start(context.Background(), "FindBestScheduleFlex")
+	defer span.End()
+	logger := s.logger.Ctx(ctx)
+	span.SetAttributes(attribute.Int("num_tasks", len(fixed)), attribute.Int("num_flex_tasks", len(flex)))
+	logger.Info("Starting flex scheduler", zap.Int("num_tasks", len(fixed)), zap.Int("num_flex_tasks", len(flex)))
+
+	fixed, rejectedTasks, _, err := s.prepareTasks(span, logger, fixed, 1)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	step := s.options.placementStep
+	if step <= 0 {
+		step = DefaultPlacementStep
+	}
+	// Candidates get IDs of their own so the DP can tell them apart, placementOf maps them
+	// back to the FlexTask they came from
+	placementOf := make(map[string]int)
+	placementsByFlex := make([][]Task, len(flex))
+	// unschedulable is the rejection of a FlexTask none of whose placements could be tried
+	unschedulable := make(map[int]RejectedTask)
+	flexIDs := make([]string, len(flex))
+	candidates := fixed
+	for i, f := range flex {
+		if f.Duration < 0 || f.LatestStart.Before(f.EarliestStart) || f.EarliestStart.IsZero() {
+			err := fmt.Errorf("flex task %d: needs a non-negative duration and a window where EarliestStart is set and not after LatestStart", i)
+			span.RecordError(err)
+			return nil, 0, nil, err
+		}
+		placements, err := f.placements(step)
+		if err != nil {
+			err = fmt.Errorf("flex task %d: %w", i, err)
+			span.RecordError(err)
+			return nil, 0, nil, err
+		}
+		flexIDs[i] = f.ID
+		if flexIDs[i] == "" {
+			flexIDs[i] = fmt.Sprintf("flex-%d", i)
+		}
+		for k := range placements {
+			placements[k].ID = fmt.Sprintf("%s@%d", flexIDs[i], k)
+			placementOf[placements[k].ID] = i
+		}
+		s.resolvePriorities(placements)
+		for _, placement := range placements {
+			if math.IsNaN(placement.Priority) || math.IsInf(placement.Priority, 0) {
+				err := fmt.Errorf("flex task %d: priority is %v when starting at %s", i, placement.Priority, placement.StartTime.Format(time.RFC3339))
+				span.RecordError(err)
+				return nil, 0, nil, err
+			}
+		}
+		// Placements aren't tasks of their own, the ones ruled out are dropped without
+		// recording a rejection for each
+		placements, dropped, err := s.rejectUnschedulable(trace.SpanFromContext(context.Background()), placements)
+		if err != nil {
+			err = fmt.Errorf("flex task %d: %w", i, err)
+			span.RecordError(err)
+			return nil, 0, nil, err
+		}
+		if len(placements) == 0 {
+			unschedulable[i] = dropped[0]
+		}
+		placementsByFlex[i] = placements
+		candidates = append(candidates, placements...)
+	}
+	span.SetAttributes(attribute.Int("num_candidate_tasks", len(candidates)))
+
+	var chosenTasks []Task
+	var scheduleRejected []RejectedTask
+	for round := 1; ; round++ {
+		chosenTasks, _, scheduleRejected, err = s.schedule(ctx, span, append([]Task(nil), candidates...))
+		if err != nil {
+			span.RecordError(err)
+			logger.Warn("Flex scheduler failed", zap.Error(err))
+			return nil, 0, nil, err
+		}
+		// Pin every FlexTask placed more than once to its best placement, ties going to the
+		// earliest, then solve again with the other placements gone
+		timesPlaced := make(map[int]int)
+		bestPlacement := make(map[int]Task)
+		for _, task := range chosenTasks {
+			i, ok := placementOf[task.ID]
+			if !ok {
+				continue
+			}
+			timesPlaced[i]++
+			if best, seen := bestPlacement[i]; !seen || task.Priority > best.Priority {
+				bestPlacement[i] = task
+			}
+		}
+		repinned := false
+		kept := make([]Task, 0, len(candidates))
+		for _, task := range candidates {
+			if i, ok := placementOf[task.ID]; ok && timesPlaced[i] > 1 && bestPlacement[i].ID != task.ID {
+				repinned = true
+				continue
+			}
+			kept = append(kept, task)
+		}
+		if !repinned {
+			span.SetAttributes(attribute.Int("num_rounds", round))
+			break
+		}
+		candidates = kept
+	}
+
+	// Report placements under their FlexTask's ID, including as the cause of a conflict
+	totalPriority := 0.0
+	placed := make([]bool, len(flex))
+	for j := range chosenTasks {
+		if i, ok := placementOf[chosenTasks[j].ID]; ok {
+			placed[i] = true
+			chosenTasks[j].ID = flexIDs[i]
+		}
+		totalPriority += chosenTasks[j].Priority
+	}
+	for _, rejected := range scheduleRejected {
+		if _, ok := placementOf[rejected.TaskRejected.ID]; ok {
+			continue
+		}
+		if i, ok := placementOf[rejected.CausedByID]; ok {
+			rejected.CausedByID = flexIDs[i]
+		}
+		rejectedTasks = append(rejectedTasks, rejected)
+	}
+	// A FlexTask that wasn't placed is rejected once, for having nowhere to go unless one of
+	// its placements would have fitted around the chosen tasks
+	chosenByTimeline := s.chosenByTimeline(chosenTasks)
+	for i := range flex {
+		if placed[i] {
+			continue
+		}
+		rejected, ruledOut := unschedulable[i]
+		if !ruledOut {
+			rejected = RejectedTask{TaskRejected: placementsByFlex[i][0], Reason: RejectionReasonNoFeasiblePlacement}
+		}
+		for _, placement := range placementsByFlex[i] {
+			if s.findConflictingChosen(chosenByTimeline[s.timelineKey(placement)], placement) == -1 {
+				rejected = RejectedTask{TaskRejected: placement, Reason: RejectionReasonLowPriority}
+				if s.options.limitTasks && s.betterValue(s.taskValue(placement), scheduleValue{}) {
+					rejected.Reason = RejectionReasonCapExceeded
+				}
+				break
+			}
+		}
+		rejected.TaskRejected.ID = flexIDs[i]
+		span.AddEvent("task_rejected", trace.WithAttributes(attribute.String("reason", rejected.Reason.String())))
+		rejectedTasks = append(rejectedTasks, rejected)
+	}
+	sort.SliceStable(chosenTasks, func(first, second int) bool {
+		return chosenTasks[first].StartTime.Before(chosenTasks[second].StartTime)
+	})
+
+	span.AddEvent("scheduler_finished", trace.WithAttributes(attribute.Int("num_chosen_tasks", len(chosenTasks)), attribute.Int("num_rejected_tasks", len(rejectedTasks))))
+	logger.Info("Flex scheduler finished", zap.Int("num_chosen_tasks", len(chosenTasks)), zap.Int("num_rejected_tasks", len(rejectedTasks)))
+	s.metrics.record(ctx, chosenTasks, totalPriority, rejectedTasks)
+	return chosenTasks, totalPriority, rejectedTasks, nil
+}
@@ -0,0 +1,208 @@
+package scheduler
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFindBestScheduleFlex(t *testing.T) {
+	tests := []struct {
+		name          string
+		fixed         []Task
+		flex          []FlexTask
+		opts          []Option
+		expected      []Task
+		expectedTotal float64
+		rejected      map[string]RejectionReason
+	}{
+		{
+			name:  "Slides out of the way",
+			fixed: []Task{{ID: "pass", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 5}},
+			flex: []FlexTask{
+				{ID: "calibration", Duration: time.Hour, EarliestStart: fixedTime(9), LatestStart: fixedTime(10).Add(30 * time.Minute), Priority: 3},
+			},
+			expected: []Task{
+				{ID: "pass", StartTime: fixedTime(9), EndTime: fixedTime(10)},
+				{ID: "calibration", StartTime: fixedTime(10), EndTime: fixedTime(11)},
+			},
+			expectedTotal: 8,
+		},
+		{
+			name:  "Nowhere to go",
+			fixed: []Task{{ID: "pass", StartTime: fixedTime(9), EndTime: fixedTime(12), Priority: 100}},
+			flex: []FlexTask{
+				{ID: "calibration", Duration: time.Hour, EarliestStart: fixedTime(9), LatestStart: fixedTime(11), Priority: 3},
+			},
+			expected:      []Task{{ID: "pass", StartTime: fixedTime(9), EndTime: fixedTime(12)}},
+			expectedTotal: 100,
+			rejected:      map[string]RejectionReason{"calibration": RejectionReasonNoFeasiblePlacement},
+		},
+		{
+			// Every placement fits on its own, it still only runs once
+			name: "Wide window is placed once",
+			flex: []FlexTask{
+				{ID: "calibration", Duration: time.Hour, EarliestStart: fixedTime(9), LatestStart: fixedTime(17), Priority: 3},
+			},
+			opts:          []Option{WithPlacementStep(30 * time.Minute)},
+			expected:      []Task{{ID: "calibration", StartTime: fixedTime(9), EndTime: fixedTime(10)}},
+			expectedTotal: 3,
+		},
+		{
+			name:  "Only the latest start fits",
+			fixed: []Task{{ID: "pass", StartTime: fixedTime(8), EndTime: fixedTime(9).Add(45 * time.Minute), Priority: 5}},
+			flex: []FlexTask{
+				{ID: "calibration", Duration: time.Hour, EarliestStart: fixedTime(9), LatestStart: fixedTime(9).Add(50 * time.Minute), Priority: 3},
+			},
+			opts: []Option{WithPlacementStep(30 * time.Minute)},
+			expected: []Task{
+				{ID: "pass", StartTime: fixedTime(8), EndTime: fixedTime(9).Add(45 * time.Minute)},
+				{ID: "calibration", StartTime: fixedTime(9).Add(50 * time.Minute), EndTime: fixedTime(10).Add(50 * time.Minute)},
+			},
+			expectedTotal: 8,
+		},
+		{
+			// The window is shorter than the duration so every placement overlaps the others,
+			// which keeps the result optimal
+			name: "Decaying priority runs as early as it can",
+			fixed: []Task{
+				{ID: "pass", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 5},
+			},
+			flex: []FlexTask{
+				{ID: "science", Duration: 3 * time.Hour, EarliestStart: fixedTime(9), LatestStart: fixedTime(11), PriorityFunc: func(placedStart time.Time) float64 {
+					return 10 - placedStart.Sub(fixedTime(9)).Hours()
+				}},
+			},
+			opts: []Option{WithPlacementStep(time.Hour)},
+			expected: []Task{
+				{ID: "pass", StartTime: fixedTime(9), EndTime: fixedTime(11)},
+				{ID: "science", StartTime: fixedTime(11), EndTime: fixedTime(14)},
+			},
+			expectedTotal: 13,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chosen, total, rejected, err := newTestScheduler().FindBestScheduleFlex(tt.fixed, tt.flex, tt.opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if total != tt.expectedTotal {
+				t.Errorf("Expected total %.2f, got %.2f", tt.expectedTotal, total)
+			}
+			if len(chosen) != len(tt.expected) {
+				t.Fatalf("Expected %d tasks, got %+v", len(tt.expected), chosen)
+			}
+			for i, expected := range tt.expected {
+				if chosen[i].ID != expected.ID || !chosen[i].StartTime.Equal(expected.StartTime) || !chosen[i].EndTime.Equal(expected.EndTime) {
+					t.Errorf("Expected task %d to be %s at %s, got %s at %s", i, expected.ID, expected.StartTime.Format(time.Kitchen), chosen[i].ID, chosen[i].StartTime.Format(time.Kitchen))
+				}
+			}
+			for _, rejection := range rejected {
+				if reason, ok := tt.rejected[rejection.TaskRejected.ID]; ok && rejection.Reason != reason {
+					t.Errorf("Expected %s to be rejected as %s, got %s", rejection.TaskRejected.ID, reason, rejection.Reason)
+				}
+			}
+			if len(chosen)+len(rejected) != len(tt.fixed)+len(tt.flex) {
+				t.Errorf("Expected every task to be chosen or rejected once, got %d chosen and %d rejected", len(chosen), len(rejected))
+			}
+		})
+	}
+}
+
+func TestFindBestScheduleFlexWindow(t *testing.T) {
+	// Only the last placements of "cal" fit in the window, and none of "late"
+	flex := []FlexTask{
+		{ID: "cal", Duration: time.Hour, EarliestStart: fixedTime(8), LatestStart: fixedTime(10), Priority: 3},
+		{ID: "late", Duration: time.Hour, EarliestStart: fixedTime(13), LatestStart: fixedTime(14), Priority: 3},
+	}
+	chosen, _, rejected, err := newTestScheduler().FindBestScheduleFlex(nil, flex, WithPlacementStep(time.Hour), WithWindow(fixedTime(9), fixedTime(12)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(chosen) != 1 || chosen[0].ID != "cal" {
+		t.Errorf("Expected cal to be placed, got %+v", chosen)
+	}
+	if len(rejected) != 1 || rejected[0].TaskRejected.ID != "late" || rejected[0].Reason != RejectionReasonOutsideWindow {
+		t.Errorf("Expected only late to be rejected as outside the window, got %+v", rejected)
+	}
+}
+
+func TestFindBestScheduleFlexDependencyCycle(t *testing.T) {
+	fixed := []Task{
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1, DependsOn: []string{"b"}},
+		{ID: "b", StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 1, DependsOn: []string{"a"}},
+	}
+	flex := []FlexTask{{ID: "cal", Duration: time.Hour, EarliestStart: fixedTime(13), LatestStart: fixedTime(14), Priority: 1}}
+	_, _, _, err := newTestScheduler().FindBestScheduleFlex(fixed, flex)
+	var cycle ErrDependencyCycle
+	if !errors.As(err, &cycle) {
+		t.Errorf("Expected ErrDependencyCycle, got %v", err)
+	}
+}
+
+func TestFindBestScheduleFlexInvalid(t *testing.T) {
+	flex := []FlexTask{{ID: "backwards", Duration: time.Hour, EarliestStart: fixedTime(10), LatestStart: fixedTime(9), Priority: 1}}
+	if _, _, _, err := newTestScheduler().FindBestScheduleFlex(nil, flex); err == nil {
+		t.Error("Expected an error for a window that ends before it starts")
+	}
+	flex = []FlexTask{{ID: "fine-grained", Duration: time.Hour, EarliestStart: fixedTime(0), LatestStart: fixedTime(0).AddDate(1, 0, 0), Priority: 1}}
+	if _, _, _, err := newTestScheduler().FindBestScheduleFlex(nil, flex, WithPlacementStep(time.Second)); err == nil {
+		t.Error("Expected an error for a window with too many placements")
+	}
+	flex = []FlexTask{{ID: "nan-late", Duration: time.Hour, EarliestStart: fixedTime(9), LatestStart: fixedTime(11), PriorityFunc: func(start time.Time) float64 {
+		if start.After(fixedTime(10)) {
+			return math.NaN()
+		}
+		return 1
+	}}}
+	if _, _, _, err := newTestScheduler().FindBestScheduleFlex(nil, flex, WithPlacementStep(time.Hour)); err == nil {
+		t.Error("Expected an error for a placement with a NaN priority")
+	}
+}
+
+func FuzzFindBestScheduleFlex(f *testing.F) {
+	f.Add([]byte{0, 6, 5, 0, 3, 6, 8, 0, 7, 6, 4, 0, 100, 0, 2, 0, 200, 12, 9, 1})
+	f.Add([]byte{5, 40, 200, 2, 6, 3, 100, 2, 9, 3, 100, 2, 12, 0, 1, 3, 12, 3, 7, 3})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tasks := fuzzTasks(data)
+		if len(tasks) < 2 {
+			return
+		}
+		// Turn every other task into a FlexTask that can start up to two hours late
+		fixed := make([]Task, 0)
+		flex := make([]FlexTask, 0)
+		for i, task := range tasks {
+			if i%2 == 0 {
+				fixed = append(fixed, task)
+				continue
+			}
+			flex = append(flex, FlexTask{
+				Duration:      task.EndTime.Sub(task.StartTime),
+				EarliestStart: task.StartTime,
+				LatestStart:   task.StartTime.Add(2 * time.Hour),
+				Priority:      task.Priority,
+				ResourceID:    task.ResourceID,
+			})
+		}
+		chosen, _, rejected, err := newTestScheduler().FindBestScheduleFlex(fixed, flex, WithPlacementStep(20*time.Minute))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := ValidateSchedule(chosen); err != nil {
+			t.Errorf("Schedule has conflicts: %v", err)
+		}
+		seen := make(map[string]bool)
+		for _, task := range chosen {
+			if seen[task.ID] {
+				t.Errorf("Task %s was placed more than once", task.ID)
+			}
+			seen[task.ID] = true
+		}
+		if len(chosen)+len(rejected) != len(tasks) {
+			t.Errorf("Expected every task to be chosen or rejected once, got %d chosen and %d rejected", len(chosen), len(rejected))
+		}
+	})
+}
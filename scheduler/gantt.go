@@ -0,0 +1,172 @@
+package scheduler
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// minGanttWidth is the narrowest chart RenderGantt will draw, any less and bars can't be told
+// apart
+const minGanttWidth = 10
+
+// GanttOption configures RenderGantt
+type GanttOption func(*ganttOptions)
+
+type ganttOptions struct {
+	rejected []RejectedTask
+	ascii    bool
+}
+
+// WithGanttRejected also draws the rejected tasks, each on a row of its own under the chosen
+// ones with a lighter bar, so it's easy to see what they overlapped
+func WithGanttRejected(rejected []RejectedTask) GanttOption {
+	return func(o *ganttOptions) {
+		o.rejected = rejected
+	}
+}
+
+// WithGanttASCII draws with plain ASCII instead of Unicode block characters, for terminals
+// that can't show them
+func WithGanttASCII() GanttOption {
+	return func(o *ganttOptions) {
+		o.ascii = true
+	}
+}
+
+// ganttGlyphs are the characters a chart is drawn with
+type ganttGlyphs struct {
+	bar, rejectedBar, instant, rejectedInstant, empty string
+}
+
+var (
+	unicodeGanttGlyphs = ganttGlyphs{bar: "█", rejectedBar: "░", instant: "◆", rejectedInstant: "◇", empty: "·"}
+	asciiGanttGlyphs   = ganttGlyphs{bar: "#", rejectedBar: "-", instant: "*", rejectedInstant: "o", empty: "."}
+)
+
+// RenderGantt draws the chosen tasks as a Gantt chart for a terminal, one row per task in the
+// order given, each a bar scaled to window across width columns and labeled with its ID and
+// priority. A zero duration task is a single marker, and the parts of a task outside the
+// window are cut off. An empty window spans the tasks being drawn. The first line gives the
+// window and how long a column is.
+func RenderGantt(chosen []Task, window TimeRange, width int, w io.Writer, opts ...GanttOption) error {
+	var options ganttOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if width < minGanttWidth {
+		return fmt.Errorf("a Gantt chart needs a width of at least %d, got %d", minGanttWidth, width)
+	}
+	start, end, err := ganttWindow(chosen, options.rejected, window)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(w)
+	if start.IsZero() && end.IsZero() {
+		fmt.Fprintln(writer, "no tasks")
+		return writer.Flush()
+	}
+	glyphs := unicodeGanttGlyphs
+	if options.ascii {
+		glyphs = asciiGanttGlyphs
+	}
+
+	labelWidth := 0
+	for _, task := range chosen {
+		labelWidth = max(labelWidth, utf8.RuneCountInString(textOrDash(task.ID)))
+	}
+	for _, rejected := range options.rejected {
+		labelWidth = max(labelWidth, utf8.RuneCountInString(textOrDash(rejected.TaskRejected.ID)))
+	}
+
+	span := end.Sub(start)
+	fmt.Fprintf(writer, "%s - %s, 1 column = %s\n", start.Format(time.RFC3339), end.Format(time.RFC3339), span/time.Duration(width))
+	row := func(task Task, bar, instant, note string) {
+		fmt.Fprintf(writer, "%-*s |%s| %s\n", labelWidth, textOrDash(task.ID), ganttBar(task, start, span, width, bar, instant, glyphs.empty), note)
+	}
+	for _, task := range chosen {
+		row(task, glyphs.bar, glyphs.instant, "priority "+formatTextNumber(task.Priority))
+	}
+	if len(options.rejected) > 0 {
+		fmt.Fprintf(writer, "%-*s +%s+\n", labelWidth, "", strings.Repeat("-", width))
+	}
+	for _, rejected := range options.rejected {
+		note := fmt.Sprintf("priority %s, %s", formatTextNumber(rejected.TaskRejected.Priority), rejected.Reason)
+		if rejected.CausedByID != "" {
+			note += " by " + rejected.CausedByID
+		}
+		row(rejected.TaskRejected, glyphs.rejectedBar, glyphs.rejectedInstant, note)
+	}
+	return writer.Flush()
+}
+
+// ganttWindow parses the window to draw, or if it's empty spans every task, returning zero
+// times if there's nothing to span
+func ganttWindow(chosen []Task, rejected []RejectedTask, window TimeRange) (time.Time, time.Time, error) {
+	if window.Start == "" && window.End == "" {
+		tasks := append([]Task(nil), chosen...)
+		for _, task := range rejected {
+			tasks = append(tasks, task.TaskRejected)
+		}
+		var start, end time.Time
+		for i, task := range tasks {
+			if i == 0 || task.StartTime.Before(start) {
+				start = task.StartTime
+			}
+			if i == 0 || task.EndTime.After(end) {
+				end = task.EndTime
+			}
+		}
+		// A lone instant still needs some time to draw across
+		if len(tasks) > 0 && !end.After(start) {
+			end = start.Add(time.Minute)
+		}
+		return start, end, nil
+	}
+	start, err := time.Parse(time.RFC3339, window.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid Gantt chart window start: %w", err)
+	}
+	end, err := time.Parse(time.RFC3339, window.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid Gantt chart window end: %w", err)
+	}
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, errors.New("the Gantt chart window must end after it starts")
+	}
+	return start, end, nil
+}
+
+// ganttBar draws one task's row of the chart, width columns covering span from start. A
+// regular task fills every column it touches, at least one if any of it is in the window, and
+// a zero duration task is a single marker in the column it falls in.
+func ganttBar(task Task, start time.Time, span time.Duration, width int, bar, instant, empty string) string {
+	column := func(t time.Time) float64 {
+		return float64(t.Sub(start)) / float64(span) * float64(width)
+	}
+	cells := make([]string, width)
+	for i := range cells {
+		cells[i] = empty
+	}
+	from, to := column(task.StartTime), column(task.EndTime)
+	if !task.EndTime.After(task.StartTime) {
+		if from >= 0 && from <= float64(width) {
+			cells[min(int(from), width-1)] = instant
+		}
+		return strings.Join(cells, "")
+	}
+	if to <= 0 || from >= float64(width) {
+		return strings.Join(cells, "")
+	}
+	first := max(int(math.Floor(from)), 0)
+	last := min(int(math.Ceil(to)), width)
+	for i := first; i < last; i++ {
+		cells[i] = bar
+	}
+	return strings.Join(cells, "")
+}
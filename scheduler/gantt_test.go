@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderGantt(t *testing.T) {
+	tasks := []Task{
+		{ID: "pass-1", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 7.5, ResourceID: "antenna-1"},
+		{ID: "pass-2", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 3},
+		{ID: "downlink", StartTime: fixedTime(11), EndTime: fixedTime(13), Priority: 10},
+		{ID: "snapshot", StartTime: fixedTime(12), EndTime: fixedTime(12), Priority: 2},
+		{ID: "calibrate", StartTime: fixedTime(13), EndTime: fixedTime(14).Add(20 * time.Minute), Priority: 4},
+		{ID: "beacon", StartTime: fixedTime(10).Add(30 * time.Minute), EndTime: fixedTime(10).Add(30 * time.Minute), Priority: 1, ResourceID: "antenna-1"},
+		{ID: "idle", StartTime: fixedTime(14), EndTime: fixedTime(15), Priority: 1},
+	}
+	chosen, _, rejected, err := newTestScheduler().FindBestSchedule(tasks)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		window TimeRange
+		opts   []GanttOption
+		golden string
+	}{
+		{
+			name:   "Spanning the tasks with rejected rows",
+			opts:   []GanttOption{WithGanttRejected(rejected)},
+			golden: "gantt.golden",
+		},
+		{
+			name:   "ASCII in a window cutting tasks off",
+			window: TimeRange{Start: "2024-01-01T10:00:00Z", End: "2024-01-01T14:00:00Z"},
+			opts:   []GanttOption{WithGanttASCII()},
+			golden: "gantt_ascii.golden",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buffer strings.Builder
+			if err := RenderGantt(chosen, tt.window, 48, &buffer, tt.opts...); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			checkGolden(t, tt.golden, buffer.String())
+		})
+	}
+}
+
+func TestRenderGanttErrors(t *testing.T) {
+	tasks := []Task{{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1}}
+	tests := []struct {
+		name   string
+		window TimeRange
+		width  int
+	}{
+		{name: "Too narrow", width: 5},
+		{name: "Bad window start", window: TimeRange{Start: "9am", End: "2024-01-01T10:00:00Z"}, width: 40},
+		{name: "Missing window end", window: TimeRange{Start: "2024-01-01T09:00:00Z"}, width: 40},
+		{name: "Window ending before it starts", window: TimeRange{Start: "2024-01-01T10:00:00Z", End: "2024-01-01T09:00:00Z"}, width: 40},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buffer strings.Builder
+			if err := RenderGantt(tasks, tt.window, tt.width, &buffer); err == nil {
+				t.Errorf("Expected an error, got:\n%s", buffer.String())
+			}
+		})
+	}
+}
+
+func TestRenderGanttNoTasks(t *testing.T) {
+	var buffer strings.Builder
+	if err := RenderGantt(nil, TimeRange{}, 40, &buffer); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if buffer.String() != "no tasks\n" {
+		t.Errorf("Expected %q, got %q", "no tasks\n", buffer.String())
+	}
+}
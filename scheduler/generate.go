@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// GenOptions controls the tasks GenerateTasks makes. Zero values get sensible defaults.
+type GenOptions struct {
+	// Seed seeds the generator, the same seed and options always give the same tasks
+	Seed int64
+	// Start is the earliest a task can start, 2024-01-01 00:00 UTC if it's zero
+	Start time.Time
+	// Overlap is how many tasks are running at once on average, 2 if it's zero. The tasks are
+	// spread over however long it takes to get that density.
+	Overlap float64
+	// MinDuration and MaxDuration bound each task's length, 0 to 4 hours if both are zero.
+	// Durations are whole minutes, so a range under a minute gives zero duration tasks.
+	MinDuration time.Duration
+	MaxDuration time.Duration
+	// MinPriority and MaxPriority bound each task's priority, 1 to 10 if both are zero
+	MinPriority float64
+	MaxPriority float64
+	// ResourceIDs are picked from at random for each task, none means the global timeline
+	ResourceIDs []string
+}
+
+// withDefaults fills in the zero values GenOptions documents
+func (o GenOptions) withDefaults() GenOptions {
+	if o.Start.IsZero() {
+		o.Start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if o.Overlap <= 0 {
+		o.Overlap = 2
+	}
+	if o.MinDuration == 0 && o.MaxDuration == 0 {
+		o.MaxDuration = 4 * time.Hour
+	}
+	if o.MinPriority == 0 && o.MaxPriority == 0 {
+		o.MinPriority, o.MaxPriority = 1, 10
+	}
+	return o
+}
+
+// GenerateTasks makes n random tasks for benchmarks and load tests, deterministically from
+// opts.Seed. Start times are uniform over a horizon sized so that on average opts.Overlap tasks
+// are running at once, and durations and priorities are uniform over their ranges. Tasks get
+// the IDs "gen-0", "gen-1" and so on, in start order.
+func GenerateTasks(n int, opts GenOptions) []Task {
+	opts = opts.withDefaults()
+	random := rand.New(rand.NewSource(opts.Seed))
+	minMinutes := int64(opts.MinDuration / time.Minute)
+	spanMinutes := max(int64(opts.MaxDuration/time.Minute)-minMinutes, 0)
+	meanMinutes := float64(minMinutes) + float64(spanMinutes)/2
+	// Average concurrency is the total time the tasks take over the horizon they share
+	horizonMinutes := max(int64(float64(n)*meanMinutes/opts.Overlap), 1)
+
+	tasks := make([]Task, n)
+	for i := range tasks {
+		start := opts.Start.Add(time.Duration(random.Int63n(horizonMinutes)) * time.Minute)
+		duration := time.Duration(minMinutes+random.Int63n(spanMinutes+1)) * time.Minute
+		tasks[i] = Task{
+			StartTime: start,
+			EndTime:   start.Add(duration),
+			Priority:  opts.MinPriority + random.Float64()*(opts.MaxPriority-opts.MinPriority),
+		}
+		if len(opts.ResourceIDs) > 0 {
+			tasks[i].ResourceID = opts.ResourceIDs[random.Intn(len(opts.ResourceIDs))]
+		}
+	}
+	sortChronologically(tasks)
+	for i := range tasks {
+		tasks[i].ID = fmt.Sprintf("gen-%d", i)
+	}
+	return tasks
+}
@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGenerateTasks(t *testing.T) {
+	opts := GenOptions{
+		Seed:        42,
+		Overlap:     3,
+		MinDuration: 30 * time.Minute,
+		MaxDuration: 90 * time.Minute,
+		MinPriority: 5,
+		MaxPriority: 8,
+		ResourceIDs: []string{"antenna-a", "antenna-b"},
+	}
+	tasks := GenerateTasks(2000, opts)
+	if len(tasks) != 2000 {
+		t.Fatalf("Expected 2000 tasks, got %d", len(tasks))
+	}
+	if again := GenerateTasks(2000, opts); !reflect.DeepEqual(again, tasks) {
+		t.Error("Expected the same seed to give the same tasks")
+	}
+	opts.Seed = 43
+	if other := GenerateTasks(2000, opts); reflect.DeepEqual(other, tasks) {
+		t.Error("Expected a different seed to give different tasks")
+	}
+
+	busy := time.Duration(0)
+	first, last := tasks[0].StartTime, tasks[0].EndTime
+	for i, task := range tasks {
+		duration := task.EndTime.Sub(task.StartTime)
+		if duration < 30*time.Minute || duration > 90*time.Minute {
+			t.Fatalf("Task %d: expected a duration between 30m and 90m, got %v", i, duration)
+		}
+		if task.Priority < 5 || task.Priority > 8 {
+			t.Fatalf("Task %d: expected a priority between 5 and 8, got %v", i, task.Priority)
+		}
+		if task.ResourceID != "antenna-a" && task.ResourceID != "antenna-b" {
+			t.Fatalf("Task %d: expected one of the given resources, got %q", i, task.ResourceID)
+		}
+		if i > 0 && task.StartTime.Before(tasks[i-1].StartTime) {
+			t.Fatalf("Task %d: expected tasks in start order", i)
+		}
+		busy += duration
+		if task.EndTime.After(last) {
+			last = task.EndTime
+		}
+	}
+	// The horizon is sized for the requested overlap, give or take the randomness
+	overlap := float64(busy) / float64(last.Sub(first))
+	if math.Abs(overlap-3) > 0.3 {
+		t.Errorf("Expected about 3 tasks running at once, got %v", overlap)
+	}
+}
+
+func TestGenerateTasksDefaults(t *testing.T) {
+	tasks := GenerateTasks(100, GenOptions{})
+	for i, task := range tasks {
+		if task.Priority < 1 || task.Priority > 10 || task.EndTime.Sub(task.StartTime) > 4*time.Hour || task.ResourceID != "" {
+			t.Fatalf("Task %d: expected the defaults, got %+v", i, task)
+		}
+	}
+	if err := ValidateTasks(tasks); err != nil {
+		t.Errorf("Expected valid tasks, got %v", err)
+	}
+	if len(GenerateTasks(0, GenOptions{})) != 0 {
+		t.Error("Expected no tasks for n = 0")
+	}
+}
@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"context"
+	"sort"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// FindScheduleGreedy builds a schedule by repeatedly taking the highest priority task that
+// doesn't conflict with anything already taken. It's not optimal, it's a baseline to compare
+// FindBestSchedule against and a fallback for inputs too large for the DP.
+//
+// Tasks that can never be scheduled (a missed deadline or starting before NotBefore) and
+// tasks that don't add any priority are skipped. Mandatory tasks get no special treatment,
+// bundles are taken or skipped whole, and tasks whose dependencies weren't taken are dropped
+// at the end. The chosen tasks come back in chronological order.
+func (s *Scheduler) FindScheduleGreedy(tasks []Task, opts ...Option) ([]Task, float64) {
+	s = s.withOptions(opts)
+	ctx, span := s.tracer().Start(context.Background(), "FindScheduleGreedy")
+	defer span.End()
+	logger := s.logger.Ctx(ctx)
+	span.SetAttributes(attribute.Int("num_tasks", len(tasks)))
+	logger.Info("Starting greedy scheduler", zap.Int("num_tasks", len(tasks)))
+
+	// Bundles are taken whole, so the greedy works on units that are either a single task or
+	// every task in a bundle. A bundle with a task that can't be scheduled is skipped entirely.
+	units := make([]greedyUnit, 0, len(tasks))
+	bundleUnit := make(map[string]int)
+	brokenBundles := make(map[string]bool)
+	for _, task := range tasks {
+		task.Priority = task.PriorityAt(task.StartTime)
+		if _, rejected := s.unschedulableReason(task); rejected {
+			if task.BundleID != "" {
+				brokenBundles[task.BundleID] = true
+			}
+			continue
+		}
+		if task.BundleID == "" {
+			units = append(units, greedyUnit{tasks: []Task{task}, priority: task.Priority})
+			continue
+		}
+		index, ok := bundleUnit[task.BundleID]
+		if !ok {
+			index = len(units)
+			bundleUnit[task.BundleID] = index
+			units = append(units, greedyUnit{})
+		}
+		units[index].tasks = append(units[index].tasks, task)
+		units[index].priority += task.Priority
+	}
+	// Sort a copy so the caller's order is left alone, ties go to the earlier unit
+	candidates := make([]greedyUnit, 0, len(units))
+	for _, unit := range units {
+		if unit.priority <= 0 || brokenBundles[unit.tasks[0].BundleID] {
+			continue
+		}
+		candidates = append(candidates, unit)
+	}
+	sort.SliceStable(candidates, func(first, second int) bool {
+		if candidates[first].priority != candidates[second].priority {
+			return candidates[first].priority > candidates[second].priority
+		}
+		return candidates[first].tasks[0].StartTime.Before(candidates[second].tasks[0].StartTime)
+	})
+
+	chosenTasks := make([]Task, 0)
+	totalPriority := 0.0
+	for _, candidate := range candidates {
+		if s.unitFits(candidate.tasks, chosenTasks) {
+			chosenTasks = append(chosenTasks, candidate.tasks...)
+			totalPriority += candidate.priority
+		}
+	}
+	if hasDependencies(chosenTasks) {
+		chosenTasks = dropUnmetDependencies(chosenTasks)
+		totalPriority = 0
+		for _, task := range chosenTasks {
+			totalPriority += task.Priority
+		}
+	}
+	sort.SliceStable(chosenTasks, func(first, second int) bool {
+		return chosenTasks[first].StartTime.Before(chosenTasks[second].StartTime)
+	})
+
+	span.SetAttributes(attribute.Int("num_chosen_tasks", len(chosenTasks)))
+	logger.Info("Greedy scheduler finished", zap.Int("num_chosen_tasks", len(chosenTasks)), zap.Float64("total_priority", totalPriority))
+	return chosenTasks, totalPriority
+}
+
+// greedyUnit is what FindScheduleGreedy takes or leaves as a whole, a task or a bundle
+type greedyUnit struct {
+	tasks    []Task
+	priority float64
+}
+
+// unitFits checks a unit's tasks conflict neither with chosen nor with each other
+func (s *Scheduler) unitFits(unit []Task, chosen []Task) bool {
+	for i, task := range unit {
+		if s.findConflictingChosenLinear(chosen, task) != -1 || s.findConflictingChosenLinear(unit[i+1:], task) != -1 {
+			return false
+		}
+	}
+	return true
+}
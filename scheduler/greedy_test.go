@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"testing"
+)
+
+func TestFindScheduleGreedy(t *testing.T) {
+	tests := []struct {
+		name             string
+		tasks            []Task
+		expectedPriority float64
+	}{
+		{
+			name:             "Empty task list",
+			tasks:            []Task{},
+			expectedPriority: 0,
+		},
+		{
+			name: "Greedy matches DP on disjoint tasks",
+			tasks: []Task{
+				{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 3},
+				{StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 4},
+			},
+			expectedPriority: 7,
+		},
+		{
+			name: "Greedy takes the big task and loses the chain",
+			tasks: []Task{
+				{StartTime: fixedTime(9), EndTime: fixedTime(12), Priority: 10},
+				{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 6},
+				{StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 6},
+				{StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 6},
+			},
+			expectedPriority: 10,
+		},
+		{
+			name: "Zero duration and overlapping tasks",
+			tasks: []Task{
+				{StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 5},
+				{StartTime: fixedTime(10), EndTime: fixedTime(10), Priority: 2},
+				{StartTime: fixedTime(11), EndTime: fixedTime(13), Priority: 4},
+				{StartTime: fixedTime(12), EndTime: fixedTime(14), Priority: 6},
+			},
+			expectedPriority: 11,
+		},
+		{
+			name: "Tasks on different resources never clash",
+			tasks: []Task{
+				{StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 5, ResourceID: "a"},
+				{StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 3, ResourceID: "b"},
+			},
+			expectedPriority: 8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScheduler()
+			greedyChosen, greedyPriority := s.FindScheduleGreedy(tt.tasks)
+			if greedyPriority != tt.expectedPriority {
+				t.Errorf("Priority mismatch: expected %.2f, got %.2f", tt.expectedPriority, greedyPriority)
+			}
+			if err := ValidateSchedule(greedyChosen); err != nil {
+				t.Errorf("Greedy schedule is invalid: %v", err)
+			}
+
+			_, bestPriority, _, err := s.FindBestSchedule(tt.tasks)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if greedyPriority > bestPriority {
+				t.Errorf("Greedy priority %.2f beats the DP's %.2f", greedyPriority, bestPriority)
+			}
+		})
+	}
+}
@@ -0,0 +1,946 @@
+package scheduler
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultICSPriority is the priority given to imported calendar events, high enough that
+// they win against anything the planner normally produces
+const DefaultICSPriority = 1000.0
+
+// ICSOption configures LoadICS
+type ICSOption func(*icsOptions)
+
+type icsOptions struct {
+	priority   float64
+	mandatory  bool
+	rangeStart time.Time
+	rangeEnd   time.Time
+}
+
+// WithICSPriority sets the priority of the imported tasks instead of DefaultICSPriority
+func WithICSPriority(priority float64) ICSOption {
+	return func(o *icsOptions) {
+		o.priority = priority
+	}
+}
+
+// WithICSMandatory marks the imported tasks as Mandatory so the scheduler can never drop them
+func WithICSMandatory() ICSOption {
+	return func(o *icsOptions) {
+		o.mandatory = true
+	}
+}
+
+// WithICSRange only keeps events that overlap [start, end), recurring events are expanded
+// within it. Recurring events without a COUNT or UNTIL need a range to be loaded at all.
+func WithICSRange(start, end time.Time) ICSOption {
+	return func(o *icsOptions) {
+		o.rangeStart = start
+		o.rangeEnd = end
+	}
+}
+
+// icsProperty is a single unfolded content line, e.g. DTSTART;TZID=Europe/London:20240101T090000
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+	line   int
+}
+
+// icsComponent is a BEGIN/END block and everything nested in it
+type icsComponent struct {
+	name       string
+	properties []icsProperty
+	children   []*icsComponent
+}
+
+func (c *icsComponent) get(name string) (icsProperty, bool) {
+	for _, property := range c.properties {
+		if property.name == name {
+			return property, true
+		}
+	}
+	return icsProperty{}, false
+}
+
+func (c *icsComponent) getAll(name string) []icsProperty {
+	properties := make([]icsProperty, 0)
+	for _, property := range c.properties {
+		if property.name == name {
+			properties = append(properties, property)
+		}
+	}
+	return properties
+}
+
+// LoadICS converts the VEVENTs in an iCalendar file into tasks that block the schedule, e.g.
+// station maintenance windows. Each task gets DefaultICSPriority unless WithICSPriority says
+// otherwise, and the event's UID as its ID ("<uid>-<k>" for occurrence k of a recurring event).
+//
+// All times are converted to UTC, TZIDs are resolved with the file's VTIMEZONE definitions
+// or failing that the IANA database. All-day events run midnight to midnight UTC. RRULEs with
+// a DAILY, WEEKLY (optionally BYDAY), MONTHLY or YEARLY frequency are expanded, honouring
+// INTERVAL, COUNT, UNTIL, EXDATE and overridden instances. Cancelled events and events
+// marked TRANSPARENT (free time) are skipped.
+func LoadICS(r io.Reader, opts ...ICSOption) ([]Task, error) {
+	options := icsOptions{priority: DefaultICSPriority}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	calendar, err := parseICS(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Time zones can be defined after the events using them, so collect them all first
+	events := make([]*icsComponent, 0)
+	zones := make(map[string]*icsTimezone)
+	var walk func(component *icsComponent) error
+	walk = func(component *icsComponent) error {
+		for _, child := range component.children {
+			switch child.name {
+			case "VEVENT":
+				events = append(events, child)
+			case "VTIMEZONE":
+				zone, err := parseICSTimezone(child)
+				if err != nil {
+					return err
+				}
+				zones[zone.id] = zone
+			case "VCALENDAR":
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(calendar); err != nil {
+		return nil, err
+	}
+	loader := icsLoader{options: options, zones: zones, overridden: make(map[string]map[int64]bool)}
+
+	// Instances moved or changed by a RECURRENCE-ID event replace the original occurrence
+	for _, event := range events {
+		recurrenceID, ok := event.get("RECURRENCE-ID")
+		if !ok {
+			continue
+		}
+		instant, err := loader.parseTime(recurrenceID)
+		if err != nil {
+			return nil, err
+		}
+		uid, _ := event.get("UID")
+		if loader.overridden[uid.value] == nil {
+			loader.overridden[uid.value] = make(map[int64]bool)
+		}
+		loader.overridden[uid.value][instant.utc.UnixNano()] = true
+	}
+
+	tasks := make([]Task, 0)
+	for _, event := range events {
+		eventTasks, err := loader.eventTasks(event)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, eventTasks...)
+	}
+	return tasks, nil
+}
+
+// parseICS reads the content lines into a tree of components
+func parseICS(r io.Reader) (*icsComponent, error) {
+	root := &icsComponent{}
+	stack := []*icsComponent{root}
+	var pending string
+	pendingLine := 0
+
+	flush := func() error {
+		if pending == "" {
+			return nil
+		}
+		property, err := parseICSContentLine(pending, pendingLine)
+		pending = ""
+		if err != nil {
+			return err
+		}
+		current := stack[len(stack)-1]
+		switch property.name {
+		case "BEGIN":
+			child := &icsComponent{name: strings.ToUpper(property.value)}
+			current.children = append(current.children, child)
+			stack = append(stack, child)
+		case "END":
+			if len(stack) == 1 || current.name != strings.ToUpper(property.value) {
+				return ErrMalformedRow{Line: property.line, Reason: fmt.Sprintf("unexpected END:%s", property.value)}
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			current.properties = append(current.properties, property)
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		// Long lines are folded onto continuation lines that start with whitespace
+		if strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t") {
+			pending += text[1:]
+			continue
+		}
+		if err := flush(); err != nil {
+			return nil, err
+		}
+		pending, pendingLine = text, line
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("invalid calendar: %s is never closed", stack[len(stack)-1].name)
+	}
+	return root, nil
+}
+
+// parseICSContentLine splits a line into its name, parameters and value, ignoring the
+// separators that appear inside quoted parameter values
+func parseICSContentLine(text string, line int) (icsProperty, error) {
+	inQuotes := false
+	separators := make([]int, 0)
+	colon := -1
+	for i, char := range text {
+		switch {
+		case char == '"':
+			inQuotes = !inQuotes
+		case char == ';' && !inQuotes:
+			separators = append(separators, i)
+		case char == ':' && !inQuotes:
+			colon = i
+		}
+		if colon != -1 {
+			break
+		}
+	}
+	if colon == -1 {
+		return icsProperty{}, ErrMalformedRow{Line: line, Reason: fmt.Sprintf("missing ':' in %q", text)}
+	}
+
+	property := icsProperty{params: make(map[string]string), value: text[colon+1:], line: line}
+	nameEnd := colon
+	if len(separators) > 0 {
+		nameEnd = separators[0]
+	}
+	property.name = strings.ToUpper(text[:nameEnd])
+	separators = append(separators, colon)
+	for i := 0; i+1 < len(separators); i++ {
+		param := text[separators[i]+1 : separators[i+1]]
+		key, value, found := strings.Cut(param, "=")
+		if !found {
+			return icsProperty{}, ErrMalformedRow{Line: line, Reason: fmt.Sprintf("invalid parameter %q", param)}
+		}
+		property.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return property, nil
+}
+
+// icsTime is a parsed DATE or DATE-TIME value. wall keeps the clock reading in the event's
+// own zone (stored in a UTC time.Time) so recurrences step in local time across DST changes.
+type icsTime struct {
+	wall   time.Time
+	utc    time.Time
+	allDay bool
+	zone   icsZone
+}
+
+// icsZone converts a wall clock reading in some time zone to UTC
+type icsZone interface {
+	toUTC(wall time.Time) time.Time
+}
+
+// utcZone is used for UTC values, floating times and all-day dates
+type utcZone struct{}
+
+func (utcZone) toUTC(wall time.Time) time.Time {
+	return wall
+}
+
+// locationZone resolves a TZID through the IANA database
+type locationZone struct {
+	location *time.Location
+}
+
+func (z locationZone) toUTC(wall time.Time) time.Time {
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), z.location).UTC()
+}
+
+// icsTimezone is a VTIMEZONE, a list of observances (STANDARD / DAYLIGHT) that each
+// switch the UTC offset from some onset onwards
+type icsTimezone struct {
+	id          string
+	observances []icsObservance
+}
+
+type icsObservance struct {
+	start      time.Time
+	offsetFrom time.Duration
+	offsetTo   time.Duration
+	// rule is the yearly rule the observance repeats on, nil if it only starts once
+	rule *icsRule
+}
+
+func (z *icsTimezone) toUTC(wall time.Time) time.Time {
+	// The observance with the most recent onset before the wall time is in effect
+	var current *icsObservance
+	var currentOnset time.Time
+	for i := range z.observances {
+		onset, ok := z.observances[i].lastOnset(wall)
+		if ok && (current == nil || onset.After(currentOnset)) {
+			current, currentOnset = &z.observances[i], onset
+		}
+	}
+	if current != nil {
+		return wall.Add(-current.offsetTo)
+	}
+	// Before every observance started, use the offset the earliest one switched from
+	earliest := z.observances[0]
+	for _, observance := range z.observances[1:] {
+		if observance.start.Before(earliest.start) {
+			earliest = observance
+		}
+	}
+	return wall.Add(-earliest.offsetFrom)
+}
+
+// lastOnset finds the latest time this observance started that's not after wall
+func (o icsObservance) lastOnset(wall time.Time) (time.Time, bool) {
+	if o.start.After(wall) {
+		return time.Time{}, false
+	}
+	if o.rule == nil {
+		return o.start, true
+	}
+	for year := wall.Year(); year >= wall.Year()-1 && year >= o.start.Year(); year-- {
+		onset, ok := o.rule.yearlyOccurrence(o.start, year)
+		if !ok || onset.Before(o.start) || onset.After(wall) {
+			continue
+		}
+		if !o.rule.until.IsZero() && onset.After(o.rule.until) {
+			continue
+		}
+		return onset, true
+	}
+	return o.start, true
+}
+
+// parseICSTimezone reads the observances of a VTIMEZONE
+func parseICSTimezone(component *icsComponent) (*icsTimezone, error) {
+	tzid, ok := component.get("TZID")
+	if !ok {
+		return nil, fmt.Errorf("invalid calendar: VTIMEZONE without a TZID")
+	}
+	zone := &icsTimezone{id: tzid.value}
+	for _, child := range component.children {
+		if child.name != "STANDARD" && child.name != "DAYLIGHT" {
+			continue
+		}
+		start, ok := child.get("DTSTART")
+		if !ok {
+			return nil, fmt.Errorf("invalid calendar: %s in %s has no DTSTART", child.name, zone.id)
+		}
+		wall, _, _, err := parseICSWall(start.value)
+		if err != nil {
+			return nil, ErrMalformedRow{Line: start.line, Reason: err.Error()}
+		}
+		observance := icsObservance{start: wall}
+		for name, offset := range map[string]*time.Duration{"TZOFFSETFROM": &observance.offsetFrom, "TZOFFSETTO": &observance.offsetTo} {
+			property, ok := child.get(name)
+			if !ok {
+				return nil, fmt.Errorf("invalid calendar: %s in %s has no %s", child.name, zone.id, name)
+			}
+			if *offset, err = parseICSOffset(property.value); err != nil {
+				return nil, ErrMalformedRow{Line: property.line, Reason: err.Error()}
+			}
+		}
+		if property, ok := child.get("RRULE"); ok {
+			rule, err := parseICSRule(property.value)
+			if err != nil {
+				return nil, ErrMalformedRow{Line: property.line, Reason: err.Error()}
+			}
+			if rule.freq != "YEARLY" {
+				return nil, ErrMalformedRow{Line: property.line, Reason: "time zone rules must be YEARLY"}
+			}
+			observance.rule = &rule
+		}
+		zone.observances = append(zone.observances, observance)
+	}
+	if len(zone.observances) == 0 {
+		return nil, fmt.Errorf("invalid calendar: VTIMEZONE %s has no observances", zone.id)
+	}
+	return zone, nil
+}
+
+// parseICSOffset parses a UTC offset like +0100 or -053000
+func parseICSOffset(value string) (time.Duration, error) {
+	if len(value) != 5 && len(value) != 7 {
+		return 0, fmt.Errorf("invalid UTC offset %q", value)
+	}
+	sign := time.Duration(1)
+	switch value[0] {
+	case '-':
+		sign = -1
+	case '+':
+	default:
+		return 0, fmt.Errorf("invalid UTC offset %q", value)
+	}
+	parts := []time.Duration{time.Hour, time.Minute, time.Second}
+	offset := time.Duration(0)
+	for i := 0; 1+2*i < len(value); i++ {
+		number, err := strconv.Atoi(value[1+2*i : 3+2*i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid UTC offset %q", value)
+		}
+		offset += time.Duration(number) * parts[i]
+	}
+	return sign * offset, nil
+}
+
+// parseICSWall parses a DATE (20240101) or DATE-TIME (20240101T090000, optionally ending in
+// Z for UTC) into its wall clock reading
+func parseICSWall(value string) (wall time.Time, allDay bool, isUTC bool, err error) {
+	if len(value) == 8 {
+		wall, err = time.Parse("20060102", value)
+		if err != nil {
+			return time.Time{}, false, false, fmt.Errorf("invalid date %q", value)
+		}
+		return wall, true, false, nil
+	}
+	isUTC = strings.HasSuffix(value, "Z")
+	wall, err = time.Parse("20060102T150405", strings.TrimSuffix(value, "Z"))
+	if err != nil {
+		return time.Time{}, false, false, fmt.Errorf("invalid date-time %q", value)
+	}
+	return wall, false, isUTC, nil
+}
+
+// icsLoader holds what's needed to turn events into tasks
+type icsLoader struct {
+	options icsOptions
+	zones   map[string]*icsTimezone
+	// overridden holds, per UID, the occurrences replaced by a RECURRENCE-ID event
+	overridden map[string]map[int64]bool
+}
+
+// zone resolves a TZID parameter, preferring the calendar's own definition
+func (l icsLoader) zone(tzid string) (icsZone, error) {
+	if tzid == "" {
+		return utcZone{}, nil
+	}
+	if zone, ok := l.zones[tzid]; ok {
+		return zone, nil
+	}
+	location, err := time.LoadLocation(tzid)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", tzid)
+	}
+	return locationZone{location: location}, nil
+}
+
+// parseTime parses a date or date-time property value into UTC
+func (l icsLoader) parseTime(property icsProperty) (icsTime, error) {
+	return l.parseTimeValue(property, property.value)
+}
+
+func (l icsLoader) parseTimeValue(property icsProperty, value string) (icsTime, error) {
+	wall, allDay, isUTC, err := parseICSWall(value)
+	if err != nil {
+		return icsTime{}, ErrMalformedRow{Line: property.line, Reason: err.Error()}
+	}
+	parsed := icsTime{wall: wall, allDay: allDay, zone: utcZone{}}
+	if !allDay && !isUTC {
+		if parsed.zone, err = l.zone(property.params["TZID"]); err != nil {
+			return icsTime{}, ErrMalformedRow{Line: property.line, Reason: err.Error()}
+		}
+	}
+	parsed.utc = parsed.zone.toUTC(wall)
+	return parsed, nil
+}
+
+// eventTasks turns one VEVENT into a task per occurrence
+func (l icsLoader) eventTasks(event *icsComponent) ([]Task, error) {
+	if status, ok := event.get("STATUS"); ok && strings.EqualFold(status.value, "CANCELLED") {
+		return nil, nil
+	}
+	if transparency, ok := event.get("TRANSP"); ok && strings.EqualFold(transparency.value, "TRANSPARENT") {
+		return nil, nil
+	}
+	startProperty, ok := event.get("DTSTART")
+	if !ok {
+		return nil, fmt.Errorf("invalid calendar: VEVENT without a DTSTART")
+	}
+	start, err := l.parseTime(startProperty)
+	if err != nil {
+		return nil, err
+	}
+
+	// The length of each occurrence, all-day events count in whole days so they keep
+	// covering midnight to midnight
+	duration := time.Duration(0)
+	if start.allDay {
+		duration = 24 * time.Hour
+	}
+	if endProperty, ok := event.get("DTEND"); ok {
+		end, err := l.parseTime(endProperty)
+		if err != nil {
+			return nil, err
+		}
+		duration = end.utc.Sub(start.utc)
+	} else if durationProperty, ok := event.get("DURATION"); ok {
+		if duration, err = parseICSDuration(durationProperty.value); err != nil {
+			return nil, ErrMalformedRow{Line: durationProperty.line, Reason: err.Error()}
+		}
+	}
+
+	uid, _ := event.get("UID")
+	id := unescapeICSText(uid.value)
+	newTask := func(occurrenceStart time.Time) Task {
+		return Task{
+			ID:        id,
+			StartTime: occurrenceStart,
+			EndTime:   occurrenceStart.Add(duration),
+			Priority:  l.options.priority,
+			Mandatory: l.options.mandatory,
+		}
+	}
+
+	ruleProperty, recurring := event.get("RRULE")
+	// An overriding instance is a one off event of its own
+	if _, isOverride := event.get("RECURRENCE-ID"); isOverride || !recurring {
+		task := newTask(start.utc)
+		if !l.inRange(task) {
+			return nil, nil
+		}
+		return []Task{task}, nil
+	}
+
+	rule, err := parseICSRule(ruleProperty.value)
+	if err != nil {
+		return nil, ErrMalformedRow{Line: ruleProperty.line, Reason: err.Error()}
+	}
+	if rule.count == 0 && rule.until.IsZero() && l.options.rangeEnd.IsZero() {
+		return nil, ErrMalformedRow{Line: ruleProperty.line, Reason: "RRULE never ends, use WithICSRange to load it"}
+	}
+	excluded := make(map[int64]bool)
+	for instant := range l.overridden[uid.value] {
+		excluded[instant] = true
+	}
+	for _, property := range event.getAll("EXDATE") {
+		for _, value := range strings.Split(property.value, ",") {
+			exdate, err := l.parseTimeValue(property, value)
+			if err != nil {
+				return nil, err
+			}
+			excluded[exdate.utc.UnixNano()] = true
+		}
+	}
+
+	tasks := make([]Task, 0)
+	occurrences := rule.occurrences(start.wall)
+	for k := 0; ; k++ {
+		if k >= maxRecurrences {
+			return nil, ErrMalformedRow{Line: ruleProperty.line, Reason: fmt.Sprintf("RRULE expands to more than %d occurrences", maxRecurrences)}
+		}
+		if rule.count > 0 && k >= rule.count {
+			break
+		}
+		wall, ok := occurrences()
+		if !ok {
+			break
+		}
+		occurrenceStart := start.zone.toUTC(wall)
+		if !rule.until.IsZero() && rule.pastUntil(wall, occurrenceStart) {
+			break
+		}
+		if !l.options.rangeEnd.IsZero() && !occurrenceStart.Before(l.options.rangeEnd) {
+			break
+		}
+		task := newTask(occurrenceStart)
+		if excluded[occurrenceStart.UnixNano()] || !l.inRange(task) {
+			continue
+		}
+		if task.ID != "" {
+			task.ID = fmt.Sprintf("%s-%d", id, k)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// inRange checks a task overlaps the WithICSRange window, if one was given
+func (l icsLoader) inRange(task Task) bool {
+	if l.options.rangeStart.IsZero() && l.options.rangeEnd.IsZero() {
+		return true
+	}
+	if !l.options.rangeEnd.IsZero() && !task.StartTime.Before(l.options.rangeEnd) {
+		return false
+	}
+	if l.options.rangeStart.IsZero() {
+		return true
+	}
+	// Zero duration events count if they sit on the start of the range
+	return task.EndTime.After(l.options.rangeStart) || task.StartTime.Equal(l.options.rangeStart)
+}
+
+// parseICSDuration parses a duration like PT1H30M, P2D or -P1W. Days are taken as 24 hours.
+func parseICSDuration(value string) (time.Duration, error) {
+	invalid := fmt.Errorf("invalid duration %q", value)
+	sign := time.Duration(1)
+	rest := value
+	if strings.HasPrefix(rest, "-") {
+		sign, rest = -1, rest[1:]
+	}
+	rest = strings.TrimPrefix(rest, "+")
+	if !strings.HasPrefix(rest, "P") || len(rest) < 3 {
+		return 0, invalid
+	}
+	rest = rest[1:]
+	units := map[byte]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour}
+	duration := time.Duration(0)
+	number := ""
+	for i := 0; i < len(rest); i++ {
+		char := rest[i]
+		switch {
+		case char >= '0' && char <= '9':
+			number += string(char)
+		case char == 'T':
+			if number != "" {
+				return 0, invalid
+			}
+			units = map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+		default:
+			unit, ok := units[char]
+			if !ok || number == "" {
+				return 0, invalid
+			}
+			amount, err := strconv.Atoi(number)
+			if err != nil {
+				return 0, invalid
+			}
+			duration += time.Duration(amount) * unit
+			number = ""
+		}
+	}
+	if number != "" {
+		return 0, invalid
+	}
+	return sign * duration, nil
+}
+
+// icsRule is the subset of an RRULE we can expand
+type icsRule struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+	// untilUTC is false for a DATE or floating UNTIL, which is then compared to wall times
+	untilUTC bool
+	byDay    []icsWeekday
+	byMonth  int
+}
+
+// icsWeekday is a BYDAY entry, ordinal is the n in e.g. 2SU (second Sunday), 0 for every one
+type icsWeekday struct {
+	ordinal int
+	day     time.Weekday
+}
+
+var icsWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// parseICSRule parses an RRULE, returning an error for the parts we can't honour rather
+// than silently producing the wrong occurrences
+func parseICSRule(value string) (icsRule, error) {
+	rule := icsRule{interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		key, partValue, found := strings.Cut(part, "=")
+		if !found {
+			return icsRule{}, fmt.Errorf("invalid RRULE part %q", part)
+		}
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.freq = strings.ToUpper(partValue)
+		case "INTERVAL":
+			rule.interval, err = strconv.Atoi(partValue)
+			if err == nil && rule.interval < 1 {
+				err = fmt.Errorf("interval must be positive")
+			}
+		case "COUNT":
+			rule.count, err = strconv.Atoi(partValue)
+			if err == nil && rule.count < 1 {
+				err = fmt.Errorf("count must be positive")
+			}
+		case "UNTIL":
+			var allDay, isUTC bool
+			rule.until, allDay, isUTC, err = parseICSWall(partValue)
+			rule.untilUTC = isUTC
+			// A DATE UNTIL includes occurrences on that day
+			if allDay {
+				rule.until = rule.until.Add(24*time.Hour - time.Nanosecond)
+			}
+		case "BYDAY":
+			for _, entry := range strings.Split(partValue, ",") {
+				if len(entry) < 2 {
+					return icsRule{}, fmt.Errorf("invalid BYDAY %q", entry)
+				}
+				day, ok := icsWeekdays[strings.ToUpper(entry[len(entry)-2:])]
+				if !ok {
+					return icsRule{}, fmt.Errorf("invalid BYDAY %q", entry)
+				}
+				weekday := icsWeekday{day: day}
+				if ordinal := entry[:len(entry)-2]; ordinal != "" {
+					if weekday.ordinal, err = strconv.Atoi(ordinal); err != nil {
+						return icsRule{}, fmt.Errorf("invalid BYDAY %q", entry)
+					}
+				}
+				rule.byDay = append(rule.byDay, weekday)
+			}
+		case "BYMONTH":
+			rule.byMonth, err = strconv.Atoi(partValue)
+			if err == nil && (rule.byMonth < 1 || rule.byMonth > 12) {
+				err = fmt.Errorf("month out of range")
+			}
+		case "WKST":
+			// Only matters for BYDAY with an INTERVAL over a week, we always start weeks on Monday
+		default:
+			return icsRule{}, fmt.Errorf("unsupported RRULE part %q", key)
+		}
+		if err != nil {
+			return icsRule{}, fmt.Errorf("invalid RRULE part %q: %w", part, err)
+		}
+	}
+
+	switch rule.freq {
+	case "DAILY", "MONTHLY":
+		if len(rule.byDay) > 0 || rule.byMonth != 0 {
+			return icsRule{}, fmt.Errorf("BYDAY and BYMONTH are only supported with WEEKLY or YEARLY rules")
+		}
+	case "WEEKLY":
+		if rule.byMonth != 0 {
+			return icsRule{}, fmt.Errorf("BYMONTH is only supported with YEARLY rules")
+		}
+		for _, weekday := range rule.byDay {
+			if weekday.ordinal != 0 {
+				return icsRule{}, fmt.Errorf("BYDAY ordinals are only supported with YEARLY rules")
+			}
+		}
+	case "YEARLY":
+		if len(rule.byDay) > 1 || (len(rule.byDay) == 1 && (rule.byMonth == 0 || rule.byDay[0].ordinal == 0)) {
+			return icsRule{}, fmt.Errorf("YEARLY rules only support a single BYDAY with an ordinal and a BYMONTH")
+		}
+		// The ordinal counts within the BYMONTH, no month has a 6th of any weekday
+		if len(rule.byDay) == 1 && (rule.byDay[0].ordinal < -5 || rule.byDay[0].ordinal > 5) {
+			return icsRule{}, fmt.Errorf("BYDAY ordinals in YEARLY rules must be between -5 and 5")
+		}
+	case "":
+		return icsRule{}, fmt.Errorf("RRULE has no FREQ")
+	default:
+		return icsRule{}, fmt.Errorf("unsupported RRULE frequency %q", rule.freq)
+	}
+	return rule, nil
+}
+
+// pastUntil checks if an occurrence is after the rule's UNTIL
+func (r icsRule) pastUntil(wall, utc time.Time) bool {
+	if r.untilUTC {
+		return utc.After(r.until)
+	}
+	return wall.After(r.until)
+}
+
+// icsCalendarCycle is how many years it takes the Gregorian calendar to repeat, weekdays and
+// leap days included, so a yearly date that hasn't come up in that many years never will
+const icsCalendarCycle = 400
+
+// occurrences returns a generator of the rule's wall clock start times in order, beginning
+// with start itself. It returns false once there are no more, which only happens to a YEARLY
+// rule whose date never comes up again (e.g. the 30th of February).
+func (r icsRule) occurrences(start time.Time) func() (time.Time, bool) {
+	switch {
+	case r.freq == "WEEKLY" && len(r.byDay) > 0:
+		// Offsets of the chosen days from the Monday of the week, in order
+		offsets := make([]int, 0, len(r.byDay))
+		for _, weekday := range r.byDay {
+			offsets = append(offsets, (int(weekday.day)+6)%7)
+		}
+		sort.Ints(offsets)
+		weekStart := start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		week, index := 0, 0
+		first := true
+		return func() (time.Time, bool) {
+			for {
+				if first {
+					// DTSTART always counts as the first occurrence
+					first = false
+					return start, true
+				}
+				if index == len(offsets) {
+					week, index = week+r.interval, 0
+				}
+				candidate := weekStart.AddDate(0, 0, 7*week+offsets[index])
+				index++
+				if candidate.After(start) {
+					return candidate, true
+				}
+			}
+		}
+	case r.freq == "YEARLY" && (len(r.byDay) > 0 || r.byMonth != 0):
+		year := start.Year() - r.interval
+		first := true
+		return func() (time.Time, bool) {
+			if first {
+				first = false
+				return start, true
+			}
+			// Years where the date doesn't exist (a 5th Monday, the 31st of a short month)
+			// are skipped, the rule can still land later in DTSTART's own year
+			for i := 0; i <= icsCalendarCycle; i++ {
+				year += r.interval
+				if occurrence, ok := r.yearlyOccurrence(start, year); ok && occurrence.After(start) {
+					return occurrence, true
+				}
+			}
+			return time.Time{}, false
+		}
+	default:
+		step := 0
+		return func() (time.Time, bool) {
+			for {
+				var candidate time.Time
+				switch r.freq {
+				case "DAILY":
+					candidate = start.AddDate(0, 0, step*r.interval)
+				case "WEEKLY":
+					candidate = start.AddDate(0, 0, 7*step*r.interval)
+				case "MONTHLY":
+					candidate = start.AddDate(0, step*r.interval, 0)
+				case "YEARLY":
+					candidate = start.AddDate(step*r.interval, 0, 0)
+				}
+				step++
+				// The 31st in a 30 day month (or the 29th of February) doesn't exist, so
+				// that occurrence is skipped instead of spilling into the next month
+				if r.freq == "DAILY" || r.freq == "WEEKLY" || candidate.Day() == start.Day() {
+					return candidate, true
+				}
+			}
+		}
+	}
+}
+
+// yearlyOccurrence finds the date a YEARLY rule lands on in year, at start's time of day.
+// Without BYDAY it's start's day of the BYMONTH, or of start's own month. It returns false if
+// the date doesn't exist that year rather than letting it spill into the next month.
+func (r icsRule) yearlyOccurrence(start time.Time, year int) (time.Time, bool) {
+	month := start.Month()
+	if r.byMonth != 0 {
+		month = time.Month(r.byMonth)
+	}
+	if len(r.byDay) == 0 {
+		occurrence := time.Date(year, month, start.Day(), start.Hour(), start.Minute(), start.Second(), 0, time.UTC)
+		return occurrence, occurrence.Month() == month
+	}
+	weekday := r.byDay[0]
+	if weekday.ordinal > 0 {
+		first := time.Date(year, month, 1, start.Hour(), start.Minute(), start.Second(), 0, time.UTC)
+		shift := (int(weekday.day) - int(first.Weekday()) + 7) % 7
+		occurrence := first.AddDate(0, 0, shift+7*(weekday.ordinal-1))
+		return occurrence, occurrence.Month() == month
+	}
+	last := time.Date(year, month+1, 0, start.Hour(), start.Minute(), start.Second(), 0, time.UTC)
+	shift := (int(last.Weekday()) - int(weekday.day) + 7) % 7
+	occurrence := last.AddDate(0, 0, -shift-7*(-weekday.ordinal-1))
+	return occurrence, occurrence.Month() == month
+}
+
+// icsDateTimeFormat is a UTC DATE-TIME value, e.g. 20240101T090000Z
+const icsDateTimeFormat = "20060102T150405Z"
+
+// icsMaxLineLength is the longest a content line can be before it has to be folded
+const icsMaxLineLength = 75
+
+// ExportICS writes the chosen tasks as an iCalendar feed, one VEVENT per task with UTC
+// DTSTART/DTEND. The priority goes in the SUMMARY and in an X-SCHEDULER-PRIORITY property,
+// the resource (if any) in RESOURCES. Zero duration tasks get DTEND equal to DTSTART.
+func ExportICS(chosen []Task, w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	writeLine := func(line string) {
+		// Fold long lines onto continuation lines starting with a space, never splitting a
+		// multi-byte character
+		for len(line) > icsMaxLineLength {
+			cut := icsMaxLineLength
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+			writer.WriteString(line[:cut] + "\r\n")
+			line = " " + line[cut:]
+		}
+		writer.WriteString(line + "\r\n")
+	}
+
+	stamp := time.Now().UTC().Format(icsDateTimeFormat)
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//turionspace//nei-mission-planner scheduler//EN")
+	writeLine("CALSCALE:GREGORIAN")
+	for i, task := range chosen {
+		end := task.EndTime
+		if !end.After(task.StartTime) {
+			end = task.StartTime
+		}
+		uid := task.ID
+		if uid == "" {
+			uid = fmt.Sprintf("task-%d-%d", i, task.StartTime.Unix())
+		}
+		priority := strconv.FormatFloat(task.Priority, 'g', -1, 64)
+
+		writeLine("BEGIN:VEVENT")
+		writeLine("UID:" + escapeICSText(uid))
+		writeLine("DTSTAMP:" + stamp)
+		writeLine("DTSTART:" + task.StartTime.UTC().Format(icsDateTimeFormat))
+		writeLine("DTEND:" + end.UTC().Format(icsDateTimeFormat))
+		writeLine("SUMMARY:" + escapeICSText(fmt.Sprintf("%s (priority %s)", uid, priority)))
+		writeLine("X-SCHEDULER-PRIORITY:" + priority)
+		if task.ResourceID != "" {
+			writeLine("RESOURCES:" + escapeICSText(task.ResourceID))
+		}
+		writeLine("END:VEVENT")
+	}
+	writeLine("END:VCALENDAR")
+	return writer.Flush()
+}
+
+// escapeICSText escapes the characters that have a meaning in TEXT values
+func escapeICSText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(text)
+}
+
+// unescapeICSText reverses escapeICSText
+func unescapeICSText(text string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(text)
+}
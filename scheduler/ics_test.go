@@ -0,0 +1,324 @@
+package scheduler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Helper function to wrap events in a calendar with CRLF line endings like real exports
+func icsCalendar(lines ...string) string {
+	all := append([]string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//test//EN"}, lines...)
+	all = append(all, "END:VCALENDAR")
+	return strings.Join(all, "\r\n") + "\r\n"
+}
+
+// Helper function to compare loaded tasks against expected IDs and UTC times
+func checkICSTasks(t *testing.T, tasks []Task, expected []Task) {
+	t.Helper()
+	if len(tasks) != len(expected) {
+		t.Fatalf("Expected %d tasks, got %d: %+v", len(expected), len(tasks), tasks)
+	}
+	for i := range tasks {
+		if tasks[i].ID != expected[i].ID || !tasks[i].StartTime.Equal(expected[i].StartTime) || !tasks[i].EndTime.Equal(expected[i].EndTime) {
+			t.Errorf("Task %d: expected %s %s-%s, got %s %s-%s", i,
+				expected[i].ID, expected[i].StartTime.Format(time.RFC3339), expected[i].EndTime.Format(time.RFC3339),
+				tasks[i].ID, tasks[i].StartTime.Format(time.RFC3339), tasks[i].EndTime.Format(time.RFC3339))
+		}
+	}
+}
+
+func TestLoadICS(t *testing.T) {
+	input := icsCalendar(
+		"BEGIN:VEVENT",
+		"UID:maintenance",
+		"DTSTART:20240101T090000Z",
+		"DTEND:20240101T110000Z",
+		"SUMMARY:Antenna maintenance that has a description long enough that the exporter",
+		"  folded it onto a second line",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:holiday",
+		"DTSTART;VALUE=DATE:20240102",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:short",
+		"DTSTART:20240103T120000Z",
+		"DURATION:PT30M",
+		"BEGIN:VALARM",
+		"TRIGGER:-PT15M",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:cancelled",
+		"STATUS:CANCELLED",
+		"DTSTART:20240104T090000Z",
+		"DTEND:20240104T100000Z",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:free",
+		"TRANSP:TRANSPARENT",
+		"DTSTART:20240104T090000Z",
+		"DTEND:20240104T100000Z",
+		"END:VEVENT",
+	)
+	tasks, err := LoadICS(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkICSTasks(t, tasks, []Task{
+		{ID: "maintenance", StartTime: fixedTime(9), EndTime: fixedTime(11)},
+		{ID: "holiday", StartTime: fixedTime(0).AddDate(0, 0, 1), EndTime: fixedTime(0).AddDate(0, 0, 2)},
+		{ID: "short", StartTime: fixedTime(12).AddDate(0, 0, 2), EndTime: fixedTime(12).AddDate(0, 0, 2).Add(30 * time.Minute)},
+	})
+	for _, task := range tasks {
+		if task.Priority != DefaultICSPriority || task.Mandatory {
+			t.Errorf("Expected default priority and not mandatory, got %+v", task)
+		}
+	}
+
+	tasks, err = LoadICS(strings.NewReader(input), WithICSPriority(50), WithICSMandatory())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tasks[0].Priority != 50 || !tasks[0].Mandatory {
+		t.Errorf("Expected priority 50 and mandatory, got %+v", tasks[0])
+	}
+}
+
+func TestLoadICSTimezones(t *testing.T) {
+	input := icsCalendar(
+		"BEGIN:VTIMEZONE",
+		"TZID:Eastern Standard Time",
+		"BEGIN:STANDARD",
+		"DTSTART:16011104T020000",
+		"RRULE:FREQ=YEARLY;BYDAY=1SU;BYMONTH=11",
+		"TZOFFSETFROM:-0400",
+		"TZOFFSETTO:-0500",
+		"END:STANDARD",
+		"BEGIN:DAYLIGHT",
+		"DTSTART:16010311T020000",
+		"RRULE:FREQ=YEARLY;BYDAY=2SU;BYMONTH=3",
+		"TZOFFSETFROM:-0500",
+		"TZOFFSETTO:-0400",
+		"END:DAYLIGHT",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"UID:winter",
+		`DTSTART;TZID="Eastern Standard Time":20240115T090000`,
+		`DTEND;TZID="Eastern Standard Time":20240115T100000`,
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:summer",
+		"DTSTART;TZID=Eastern Standard Time:20240715T090000",
+		"DTEND;TZID=Eastern Standard Time:20240715T100000",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:iana",
+		"DTSTART;TZID=Europe/London:20240715T090000",
+		"DTEND;TZID=Europe/London:20240715T100000",
+		"END:VEVENT",
+	)
+	tasks, err := LoadICS(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	july := func(hour int) time.Time { return time.Date(2024, 7, 15, hour, 0, 0, 0, time.UTC) }
+	checkICSTasks(t, tasks, []Task{
+		{ID: "winter", StartTime: time.Date(2024, 1, 15, 14, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 1, 15, 15, 0, 0, 0, time.UTC)},
+		{ID: "summer", StartTime: july(13), EndTime: july(14)},
+		{ID: "iana", StartTime: july(8), EndTime: july(9)},
+	})
+}
+
+func TestLoadICSRecurring(t *testing.T) {
+	input := icsCalendar(
+		"BEGIN:VEVENT",
+		"UID:weekly",
+		"DTSTART:20240101T090000Z",
+		"DTEND:20240101T100000Z",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=5",
+		"EXDATE:20240103T090000Z",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:weekly",
+		"RECURRENCE-ID:20240108T090000Z",
+		"DTSTART:20240108T150000Z",
+		"DTEND:20240108T160000Z",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:daily",
+		"DTSTART;TZID=America/New_York:20240309T090000",
+		"DTEND;TZID=America/New_York:20240309T100000",
+		"RRULE:FREQ=DAILY",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:monthly",
+		"DTSTART:20240131T090000Z",
+		"DTEND:20240131T100000Z",
+		"RRULE:FREQ=MONTHLY;UNTIL=20240331",
+		"END:VEVENT",
+	)
+	tasks, err := LoadICS(strings.NewReader(input), WithICSRange(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2024, month, day, hour, 0, 0, 0, time.UTC)
+	}
+	checkICSTasks(t, tasks, []Task{
+		// Wednesday the 3rd is excluded and Monday the 8th was moved to the afternoon
+		{ID: "weekly-0", StartTime: at(1, 1, 9), EndTime: at(1, 1, 10)},
+		{ID: "weekly-3", StartTime: at(1, 10, 9), EndTime: at(1, 10, 10)},
+		{ID: "weekly-4", StartTime: at(1, 15, 9), EndTime: at(1, 15, 10)},
+		{ID: "weekly", StartTime: at(1, 8, 15), EndTime: at(1, 8, 16)},
+		// Clocks go forward on the 10th of March, the event stays at 9am local time
+		{ID: "daily-0", StartTime: at(3, 9, 14), EndTime: at(3, 9, 15)},
+		{ID: "daily-1", StartTime: at(3, 10, 13), EndTime: at(3, 10, 14)},
+		// There's no 31st of February, that occurrence is skipped
+		{ID: "monthly-0", StartTime: at(1, 31, 9), EndTime: at(1, 31, 10)},
+	})
+}
+
+func TestLoadICSYearly(t *testing.T) {
+	input := icsCalendar(
+		"BEGIN:VEVENT",
+		"UID:march",
+		"DTSTART:20240110T090000Z",
+		"DTEND:20240110T100000Z",
+		"RRULE:FREQ=YEARLY;BYMONTH=3;COUNT=3",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:fifth-monday",
+		"DTSTART:20240429T090000Z",
+		"DTEND:20240429T100000Z",
+		"RRULE:FREQ=YEARLY;BYDAY=5MO;BYMONTH=4;COUNT=3",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:february",
+		"DTSTART:20240131T090000Z",
+		"DTEND:20240131T100000Z",
+		"RRULE:FREQ=YEARLY;BYMONTH=2;COUNT=3",
+		"END:VEVENT",
+	)
+	tasks, err := LoadICS(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	at := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+	}
+	checkICSTasks(t, tasks, []Task{
+		// BYMONTH moves the later occurrences to March, starting with this year's
+		{ID: "march-0", StartTime: at(2024, 1, 10, 9), EndTime: at(2024, 1, 10, 10)},
+		{ID: "march-1", StartTime: at(2024, 3, 10, 9), EndTime: at(2024, 3, 10, 10)},
+		{ID: "march-2", StartTime: at(2025, 3, 10, 9), EndTime: at(2025, 3, 10, 10)},
+		// Aprils without a 5th Monday are skipped
+		{ID: "fifth-monday-0", StartTime: at(2024, 4, 29, 9), EndTime: at(2024, 4, 29, 10)},
+		{ID: "fifth-monday-1", StartTime: at(2029, 4, 30, 9), EndTime: at(2029, 4, 30, 10)},
+		{ID: "fifth-monday-2", StartTime: at(2030, 4, 29, 9), EndTime: at(2030, 4, 29, 10)},
+		// There's never a 31st of February, so only DTSTART is left
+		{ID: "february-0", StartTime: at(2024, 1, 31, 9), EndTime: at(2024, 1, 31, 10)},
+	})
+}
+
+func TestLoadICSErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		expectedLine int
+	}{
+		{
+			name:         "Bad DTSTART",
+			input:        icsCalendar("BEGIN:VEVENT", "UID:a", "DTSTART:tomorrow", "END:VEVENT"),
+			expectedLine: 6,
+		},
+		{
+			name:         "Line without a colon",
+			input:        icsCalendar("BEGIN:VEVENT", "UID a", "END:VEVENT"),
+			expectedLine: 5,
+		},
+		{
+			name:         "Recurring event that never ends",
+			input:        icsCalendar("BEGIN:VEVENT", "UID:a", "DTSTART:20240101T090000Z", "RRULE:FREQ=DAILY", "END:VEVENT"),
+			expectedLine: 7,
+		},
+		{
+			name:         "Unsupported RRULE part",
+			input:        icsCalendar("BEGIN:VEVENT", "UID:a", "DTSTART:20240101T090000Z", "RRULE:FREQ=MONTHLY;BYSETPOS=1;COUNT=2", "END:VEVENT"),
+			expectedLine: 7,
+		},
+		{
+			name:         "YEARLY BYDAY ordinal past the 5th",
+			input:        icsCalendar("BEGIN:VEVENT", "UID:a", "DTSTART:20240101T090000Z", "RRULE:FREQ=YEARLY;BYDAY=6MO;BYMONTH=4;COUNT=2", "END:VEVENT"),
+			expectedLine: 7,
+		},
+		{
+			name:         "Unknown time zone",
+			input:        icsCalendar("BEGIN:VEVENT", "UID:a", "DTSTART;TZID=Mars/Olympus:20240101T090000", "END:VEVENT"),
+			expectedLine: 6,
+		},
+		{
+			name:         "Mismatched END",
+			input:        icsCalendar("BEGIN:VEVENT", "UID:a", "END:VTODO"),
+			expectedLine: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadICS(strings.NewReader(tt.input))
+			var malformed ErrMalformedRow
+			if !errors.As(err, &malformed) {
+				t.Fatalf("Expected ErrMalformedRow, got %v", err)
+			}
+			if malformed.Line != tt.expectedLine {
+				t.Errorf("Expected error on line %d, got line %d (%v)", tt.expectedLine, malformed.Line, err)
+			}
+		})
+	}
+
+	if _, err := LoadICS(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\n")); err == nil {
+		t.Error("Expected an error for an unterminated calendar")
+	}
+}
+
+func TestExportICS(t *testing.T) {
+	chosen := []Task{
+		{ID: "pass-1", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 7.5, ResourceID: "antenna-1"},
+		{ID: "instant", StartTime: fixedTime(11), EndTime: fixedTime(11), Priority: 2},
+		{ID: strings.Repeat("long-id,", 12), StartTime: fixedTime(12), EndTime: fixedTime(13), Priority: 1},
+	}
+	var buffer strings.Builder
+	if err := ExportICS(chosen, &buffer); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	output := buffer.String()
+
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("Line longer than 75 octets: %q", line)
+		}
+		if strings.Contains(line, "\n") {
+			t.Errorf("Line with a bare newline: %q", line)
+		}
+	}
+	for _, expected := range []string{
+		"DTSTART:20240101T110000Z\r\nDTEND:20240101T110000Z\r\n",
+		"SUMMARY:pass-1 (priority 7.5)\r\n",
+		"X-SCHEDULER-PRIORITY:7.5\r\n",
+		"RESOURCES:antenna-1\r\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+
+	// What we write, we can read back
+	loaded, err := LoadICS(strings.NewReader(output))
+	if err != nil {
+		t.Fatalf("Unexpected error reading the export back: %v", err)
+	}
+	checkICSTasks(t, loaded, chosen)
+}
@@ -0,0 +1,156 @@
+package scheduler
+
+import (
+	"context"
+	"sort"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// AddTask fits newTask into a schedule we already have without re-running the DP over
+// everything, for interactive planning where an operator drags in one task at a time. existing
+// is every task the schedule was built from and chosen the schedule itself, as returned by
+// FindBestSchedule with the same options.
+//
+// Chosen tasks that don't conflict with newTask are kept as they are. Between newTask, the
+// chosen tasks it conflicts with and the unchosen tasks in existing that those were blocking,
+// the best combination is picked, so newTask only evicts tasks when it's worth more than them
+// (and whatever now fits around it). The result is locally optimal: no schedule that keeps the
+// untouched tasks does better, and the total never drops below what chosen had. It can still
+// fall short of a full FindBestSchedule, e.g. when moving an untouched task would help.
+//
+// It returns the new schedule in chronological order, its total priority and the rejections
+// for tasks that end up out of it having been in play: newTask if it didn't make it, and any
+// chosen task that was evicted. Mandatory chosen tasks are never evicted, a newTask that
+// conflicts with one is rejected. With WithExclusiveGroups a chosen task in newTask's group
+// counts as conflicting with it. Bundles are left as they are: chosen tasks in a bundle are
+// treated like mandatory ones and a newTask in a bundle is rejected.
+func (s *Scheduler) AddTask(existing []Task, chosen []Task, newTask Task, opts ...Option) ([]Task, float64, []RejectedTask) {
+	s = s.withOptions(opts)
+	ctx, span := s.tracer().Start(context.Background(), "AddTask")
+	defer span.End()
+	logger := s.logger.Ctx(ctx)
+	span.SetAttributes(attribute.Int("num_existing_tasks", len(existing)), attribute.Int("num_chosen_tasks", len(chosen)))
+
+	// IDs are how we tell existing tasks apart from chosen ones, fill them in the same way
+	// FindBestSchedule did
+	existing = append([]Task(nil), existing...)
+	s.assignMissingIDs(existing)
+	s.resolvePriorities(existing)
+	chosen = append([]Task(nil), chosen...)
+	// chosen came out of FindBestSchedule with the same options, so under WithEffectivePriority
+	// its priorities are already effective and weighting them again would compound
+	if s.options.effectivePriority == nil {
+		s.resolvePriorities(chosen)
+	}
+	withID := []Task{newTask}
+	if newTask.ID == "" {
+		s.assignMissingIDs(withID)
+	}
+	s.resolvePriorities(withID)
+	newTask = s.clampToWindow(s.snap(withID[0]))
+	for i := range existing {
+		existing[i] = s.clampToWindow(s.snap(existing[i]))
+	}
+	unchanged := func(rejected ...RejectedTask) ([]Task, float64, []RejectedTask) {
+		totalPriority := 0.0
+		for _, task := range chosen {
+			totalPriority += task.Priority
+		}
+		return chosen, totalPriority, rejected
+	}
+
+	chosenIDs := make(map[string]bool, len(chosen))
+	for _, task := range chosen {
+		chosenIDs[task.ID] = true
+	}
+	if chosenIDs[newTask.ID] {
+		return unchanged()
+	}
+	if reason, rejected := s.unschedulableReason(newTask); rejected {
+		return unchanged(RejectedTask{TaskRejected: newTask, Reason: reason})
+	}
+	// One task on its own can't bring the rest of its bundle in
+	if newTask.BundleID != "" {
+		return unchanged(RejectedTask{TaskRejected: newTask, Reason: RejectionReasonBundleInfeasible})
+	}
+
+	// Split the schedule into what newTask touches and what it leaves alone
+	affected := make([]Task, 0)
+	fixed := make([]Task, 0, len(chosen))
+	fixedGroups := make(map[string]bool)
+	for _, task := range chosen {
+		if !s.tasksConflict(newTask, task) && !s.groupConflict(newTask, task) {
+			fixed = append(fixed, task)
+			fixedGroups[task.GroupID] = true
+			continue
+		}
+		// Evicting part of a bundle would leave the rest of it stranded
+		if task.Mandatory || task.BundleID != "" {
+			reason := RejectionReasonConflict
+			if !s.tasksConflict(newTask, task) {
+				reason = RejectionReasonGroupExclusive
+			}
+			return unchanged(RejectedTask{TaskRejected: newTask, CausedByID: task.ID, Reason: reason})
+		}
+		affected = append(affected, task)
+	}
+	span.SetAttributes(attribute.Int("num_affected_tasks", len(affected)))
+
+	// An unchosen task can only get in now if it was blocked by something that might leave,
+	// and it still has to fit around the tasks that stay
+	fixedByTimeline := s.chosenByTimeline(fixed)
+	candidates := append([]Task{newTask}, affected...)
+	for _, task := range existing {
+		if chosenIDs[task.ID] || task.ID == newTask.ID || task.Mandatory || task.BundleID != "" {
+			continue
+		}
+		if _, rejected := s.unschedulableReason(task); rejected {
+			continue
+		}
+		touched := s.tasksConflict(task, newTask) || s.groupConflict(task, newTask)
+		for i := 0; !touched && i < len(affected); i++ {
+			touched = s.tasksConflict(task, affected[i]) || s.groupConflict(task, affected[i])
+		}
+		if s.options.exclusiveGroups && task.GroupID != "" && fixedGroups[task.GroupID] {
+			continue
+		}
+		if touched && s.findConflictingChosen(fixedByTimeline[s.timelineKey(task)], task) == -1 {
+			candidates = append(candidates, task)
+		}
+	}
+	span.SetAttributes(attribute.Int("num_candidate_tasks", len(candidates)))
+
+	// Under WithMaxTasks the tasks that stay already use up part of the cap. s is our own copy
+	// from withOptions, so this doesn't leak into the caller's Scheduler.
+	if s.options.limitTasks {
+		s.options.maxTasks = max(s.options.maxTasks-len(fixed), 0)
+	}
+	localChosen, _, localRejected, err := s.schedule(ctx, span, candidates)
+	if err != nil {
+		// Mandatory chosen tasks were dealt with above, so this can't really happen, but if it
+		// does leave the schedule alone
+		span.RecordError(err)
+		logger.Warn("Could not add task", zap.Error(err))
+		return unchanged(RejectedTask{TaskRejected: newTask, Reason: RejectionReasonConflict})
+	}
+
+	chosenTasks := append(fixed, localChosen...)
+	sort.SliceStable(chosenTasks, func(first, second int) bool {
+		return chosenTasks[first].StartTime.Before(chosenTasks[second].StartTime)
+	})
+	totalPriority := 0.0
+	for _, task := range chosenTasks {
+		totalPriority += task.Priority
+	}
+	// Tasks that were already out stay out, only report what changed
+	rejectedTasks := []RejectedTask{}
+	for _, rejected := range localRejected {
+		if rejected.TaskRejected.ID == newTask.ID || chosenIDs[rejected.TaskRejected.ID] {
+			rejectedTasks = append(rejectedTasks, rejected)
+		}
+	}
+	logger.Info("Added task", zap.String("task_id", newTask.ID), zap.Int("num_chosen_tasks", len(chosenTasks)), zap.Int("num_rejected_tasks", len(rejectedTasks)))
+	return chosenTasks, totalPriority, rejectedTasks
+}
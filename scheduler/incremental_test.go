@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"testing"
+)
+
+func TestAddTask(t *testing.T) {
+	existing := []Task{
+		{ID: "long", StartTime: fixedTime(9), EndTime: fixedTime(12), Priority: 10},
+		{ID: "blocked", StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 4},
+		{ID: "later", StartTime: fixedTime(13), EndTime: fixedTime(14), Priority: 5},
+		{ID: "important", StartTime: fixedTime(15), EndTime: fixedTime(16), Priority: 1, Mandatory: true},
+	}
+	tests := []struct {
+		name          string
+		newTask       Task
+		expectedIDs   []string
+		expectedTotal float64
+		rejectedIDs   []string
+	}{
+		{
+			name:          "Fits in a gap",
+			newTask:       Task{ID: "new", StartTime: fixedTime(12), EndTime: fixedTime(13), Priority: 2},
+			expectedIDs:   []string{"long", "new", "later", "important"},
+			expectedTotal: 18,
+		},
+		{
+			name:          "Not worth evicting for",
+			newTask:       Task{ID: "new", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 3},
+			expectedIDs:   []string{"long", "later", "important"},
+			expectedTotal: 16,
+			rejectedIDs:   []string{"new"},
+		},
+		{
+			// The new task and the task long was blocking beat long together
+			name:          "Evicts and lets a blocked task in",
+			newTask:       Task{ID: "new", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 7},
+			expectedIDs:   []string{"new", "blocked", "later", "important"},
+			expectedTotal: 17,
+			rejectedIDs:   []string{"long"},
+		},
+		{
+			name:          "Can't evict a mandatory task",
+			newTask:       Task{ID: "new", StartTime: fixedTime(15), EndTime: fixedTime(16), Priority: 100},
+			expectedIDs:   []string{"long", "later", "important"},
+			expectedTotal: 16,
+			rejectedIDs:   []string{"new"},
+		},
+		{
+			name:          "Already chosen",
+			newTask:       existing[2],
+			expectedIDs:   []string{"long", "later", "important"},
+			expectedTotal: 16,
+		},
+		{
+			name:          "Missed deadline",
+			newTask:       Task{ID: "new", StartTime: fixedTime(12), EndTime: fixedTime(13), Priority: 2, Deadline: fixedTime(12)},
+			expectedIDs:   []string{"long", "later", "important"},
+			expectedTotal: 16,
+			rejectedIDs:   []string{"new"},
+		},
+	}
+
+	s := newTestScheduler()
+	chosen, _, _, err := s.FindBestSchedule(existing)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, total, rejected := s.AddTask(existing, chosen, tt.newTask)
+			if total != tt.expectedTotal {
+				t.Errorf("Expected total %.2f, got %.2f", tt.expectedTotal, total)
+			}
+			if len(updated) != len(tt.expectedIDs) {
+				t.Fatalf("Expected %v, got %+v", tt.expectedIDs, updated)
+			}
+			for i, id := range tt.expectedIDs {
+				if updated[i].ID != id {
+					t.Errorf("Expected task %d to be %s, got %s", i, id, updated[i].ID)
+				}
+			}
+			if len(rejected) != len(tt.rejectedIDs) {
+				t.Fatalf("Expected %v rejected, got %+v", tt.rejectedIDs, rejected)
+			}
+			for i, id := range tt.rejectedIDs {
+				if rejected[i].TaskRejected.ID != id {
+					t.Errorf("Expected rejection %d to be %s, got %s", i, id, rejected[i].TaskRejected.ID)
+				}
+			}
+			if err := ValidateSchedule(updated); err != nil {
+				t.Errorf("Updated schedule has conflicts: %v", err)
+			}
+		})
+	}
+}
+
+func TestAddTaskWithMaxTasks(t *testing.T) {
+	existing := []Task{
+		{ID: "first", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 5},
+		{ID: "second", StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 5},
+	}
+	s := newTestScheduler()
+	chosen, _, _, err := s.FindBestSchedule(existing, WithMaxTasks(2))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// There's room in time but not in the cap, and the tasks that stay can't be given up
+	newTask := Task{ID: "new", StartTime: fixedTime(13), EndTime: fixedTime(14), Priority: 50}
+	updated, total, rejected := s.AddTask(existing, chosen, newTask, WithMaxTasks(2))
+	if len(updated) != 2 || total != 10 {
+		t.Errorf("Expected the schedule to stay at 2 tasks worth 10, got %+v worth %.2f", updated, total)
+	}
+	if len(rejected) != 1 || rejected[0].Reason != RejectionReasonCapExceeded {
+		t.Errorf("Expected the new task rejected for the cap, got %+v", rejected)
+	}
+}
+
+func TestAddTaskWithExclusiveGroups(t *testing.T) {
+	existing := []Task{
+		{ID: "downlink-1", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 5, GroupID: "downlink"},
+		{ID: "science", StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 3},
+	}
+	s := newTestScheduler()
+	chosen, _, _, err := s.FindBestSchedule(existing, WithExclusiveGroups())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// No overlap in time, but only one downlink can stay
+	newTask := Task{ID: "downlink-2", StartTime: fixedTime(13), EndTime: fixedTime(14), Priority: 8, GroupID: "downlink"}
+	updated, total, rejected := s.AddTask(existing, chosen, newTask, WithExclusiveGroups())
+	if total != 11 || len(updated) != 2 || updated[0].ID != "science" || updated[1].ID != "downlink-2" {
+		t.Errorf("Expected science and downlink-2 worth 11, got %+v worth %.2f", updated, total)
+	}
+	if len(rejected) != 1 || rejected[0].TaskRejected.ID != "downlink-1" || rejected[0].Reason != RejectionReasonGroupExclusive {
+		t.Errorf("Expected downlink-1 evicted for the group, got %+v", rejected)
+	}
+}
+
+func FuzzAddTaskNeverWorse(f *testing.F) {
+	f.Add([]byte{0, 6, 5, 0, 3, 6, 8, 0, 7, 6, 4, 0, 100, 0, 2, 0, 200, 12, 9, 1})
+	f.Add([]byte{5, 40, 200, 2, 6, 3, 100, 2, 9, 3, 100, 2, 12, 0, 1, 3, 12, 3, 7, 3})
+	// Chosen tasks on two resources, which used to hide a conflict from the search
+	f.Add([]byte("\x85002ax\xb10A770xA00GX\xf00T000"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tasks := fuzzTasks(data)
+		if len(tasks) < 2 {
+			return
+		}
+		// Schedule everything but the last task, then drag that one in
+		s := newTestScheduler()
+		existing, newTask := tasks[:len(tasks)-1], tasks[len(tasks)-1]
+		newTask.ID = "new"
+		chosen, total, _, err := s.FindBestSchedule(existing)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		updated, updatedTotal, _ := s.AddTask(existing, chosen, newTask)
+		if err := ValidateSchedule(updated); err != nil {
+			t.Errorf("Updated schedule has conflicts: %v", err)
+		}
+		if updatedTotal < total-1e-9 {
+			t.Errorf("Adding a task dropped the total from %v to %v", total, updatedTotal)
+		}
+		_, bestTotal, _, err := s.FindBestSchedule(append(append([]Task(nil), existing...), newTask))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if updatedTotal > bestTotal+1e-9 {
+			t.Errorf("Added total %v beats the optimum %v", updatedTotal, bestTotal)
+		}
+	})
+}
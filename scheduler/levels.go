@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"time"
+)
+
+// PriorityLevel is a named priority tier, for callers that rank tasks by tier rather than by
+// number. The values are stable and machine readable, they're what Task.Level holds in JSON.
+type PriorityLevel string
+
+const (
+	// PriorityCritical is for tasks the mission can't do without short of making them Mandatory
+	PriorityCritical PriorityLevel = "CRITICAL"
+	PriorityHigh     PriorityLevel = "HIGH"
+	PriorityMedium   PriorityLevel = "MEDIUM"
+	// PriorityLow is for tasks that only fill gaps
+	PriorityLow PriorityLevel = "LOW"
+)
+
+// DefaultPriority is the level's numeric priority when WithPriorityMapping doesn't give one.
+// Each level is worth more than the one below it, but not so much that a handful of lower
+// tasks can never outweigh it. 0 is returned for an unknown level.
+func (l PriorityLevel) DefaultPriority() float64 {
+	switch l {
+	case PriorityCritical:
+		return 15
+	case PriorityHigh:
+		return 10
+	case PriorityMedium:
+		return 5
+	case PriorityLow:
+		return 1
+	}
+	return 0
+}
+
+// valid checks the level is one of the known ones
+func (l PriorityLevel) valid() bool {
+	switch l {
+	case PriorityCritical, PriorityHigh, PriorityMedium, PriorityLow:
+		return true
+	}
+	return false
+}
+
+func (l PriorityLevel) String() string {
+	return string(l)
+}
+
+// TaskFromLevel makes a task running from start to end at the given level, with Priority set
+// to the level's DefaultPriority. WithPriorityMapping can still reweight it when it's scheduled.
+func TaskFromLevel(start, end time.Time, level PriorityLevel) Task {
+	return Task{StartTime: start, EndTime: end, Priority: level.DefaultPriority(), Level: level}
+}
+
+// levelPriority is the numeric priority of a level, from WithPriorityMapping if it has the
+// level and its default otherwise
+func (s *Scheduler) levelPriority(level PriorityLevel) float64 {
+	if priority, ok := s.options.priorityMapping[level]; ok {
+		return priority
+	}
+	return level.DefaultPriority()
+}
@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestTaskFromLevel(t *testing.T) {
+	task := TaskFromLevel(fixedTime(9), fixedTime(10), PriorityCritical)
+	if task.Priority != 15 || task.Level != PriorityCritical {
+		t.Errorf("Expected a CRITICAL task with priority 15, got %+v", task)
+	}
+	if !task.StartTime.Equal(fixedTime(9)) || !task.EndTime.Equal(fixedTime(10)) {
+		t.Errorf("Expected the task to run 9:00 to 10:00, got %+v", task)
+	}
+	if (PriorityLevel("URGENT")).DefaultPriority() != 0 {
+		t.Error("Expected an unknown level to default to 0")
+	}
+}
+
+func TestPriorityMapping(t *testing.T) {
+	critical := TaskFromLevel(fixedTime(9), fixedTime(11), PriorityCritical)
+	critical.ID = "critical"
+	low1 := TaskFromLevel(fixedTime(9), fixedTime(10), PriorityLow)
+	low1.ID = "low1"
+	low2 := TaskFromLevel(fixedTime(10), fixedTime(11), PriorityLow)
+	low2.ID = "low2"
+	// A level set by hand replaces whatever Priority says
+	medium := Task{ID: "medium", StartTime: fixedTime(12), EndTime: fixedTime(13), Priority: 100, Level: PriorityMedium}
+	tasks := []Task{critical, low1, low2, medium}
+
+	tests := []struct {
+		name             string
+		opts             []Option
+		expectedChosen   []string
+		expectedPriority float64
+	}{
+		{
+			name:             "Default weights",
+			expectedChosen:   []string{"critical", "medium"},
+			expectedPriority: 20,
+		},
+		{
+			name:             "Flatter weights",
+			opts:             []Option{WithPriorityMapping(map[PriorityLevel]float64{PriorityCritical: 3, PriorityLow: 2})},
+			expectedChosen:   []string{"low1", "low2", "medium"},
+			expectedPriority: 9,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chosen, totalPriority, _, err := newTestScheduler().FindBestSchedule(tasks, tt.opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if totalPriority != tt.expectedPriority {
+				t.Errorf("Expected total priority %v, got %v", tt.expectedPriority, totalPriority)
+			}
+			if len(chosen) != len(tt.expectedChosen) {
+				t.Fatalf("Expected %v chosen, got %+v", tt.expectedChosen, chosen)
+			}
+			for i, id := range tt.expectedChosen {
+				if chosen[i].ID != id {
+					t.Errorf("Expected task %d to be %s, got %s", i, id, chosen[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestPriorityLevelInvalid(t *testing.T) {
+	unknown := Task{StartTime: fixedTime(9), EndTime: fixedTime(10), Level: "URGENT"}
+	var invalid ErrInvalidTask
+	if _, _, _, err := newTestScheduler().FindBestSchedule([]Task{unknown}); !errors.As(err, &invalid) {
+		t.Errorf("Expected ErrInvalidTask for an unknown level, got %v", err)
+	}
+
+	high := TaskFromLevel(fixedTime(9), fixedTime(10), PriorityHigh)
+	mapping := map[PriorityLevel]float64{PriorityHigh: math.NaN()}
+	if _, _, _, err := newTestScheduler().FindBestSchedule([]Task{high}, WithPriorityMapping(mapping)); !errors.As(err, &invalid) {
+		t.Errorf("Expected ErrInvalidTask for a level mapped to NaN, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMalformedRow is returned when a row of a loaded file can't be turned into a task,
+// Line is the 1-based line number in the file
+type ErrMalformedRow struct {
+	Line   int
+	Reason string
+}
+
+func (e ErrMalformedRow) Error() string {
+	return fmt.Sprintf("malformed row at line %d: %s", e.Line, e.Reason)
+}
+
+// csvColumns is the column layout LoadTasksCSV expects
+var csvColumns = []string{"start_time", "end_time", "priority"}
+
+// LoadTasksCSV parses rows of start_time,end_time,priority into tasks, times are RFC3339.
+// A header row naming the columns is skipped if present. Malformed rows return an
+// ErrMalformedRow with the line they're on.
+func LoadTasksCSV(r io.Reader) ([]Task, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	// Column counts are checked per row so the error can say which line was wrong
+	reader.FieldsPerRecord = -1
+
+	tasks := make([]Task, 0)
+	for row := 0; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, ErrMalformedRow{Line: parseErr.StartLine, Reason: parseErr.Err.Error()}
+			}
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if row == 0 && isCSVHeader(record) {
+			continue
+		}
+		if len(record) != len(csvColumns) {
+			return nil, ErrMalformedRow{Line: line, Reason: fmt.Sprintf("expected %d columns, got %d", len(csvColumns), len(record))}
+		}
+
+		startTime, err := time.Parse(time.RFC3339, strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, ErrMalformedRow{Line: line, Reason: fmt.Sprintf("invalid start_time %q", record[0])}
+		}
+		endTime, err := time.Parse(time.RFC3339, strings.TrimSpace(record[1]))
+		if err != nil {
+			return nil, ErrMalformedRow{Line: line, Reason: fmt.Sprintf("invalid end_time %q", record[1])}
+		}
+		priority, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil {
+			return nil, ErrMalformedRow{Line: line, Reason: fmt.Sprintf("invalid priority %q", record[2])}
+		}
+		tasks = append(tasks, Task{StartTime: startTime, EndTime: endTime, Priority: priority})
+	}
+	return tasks, nil
+}
+
+// isCSVHeader checks if a row is the column names rather than data
+func isCSVHeader(record []string) bool {
+	if len(record) != len(csvColumns) {
+		return false
+	}
+	for i, column := range csvColumns {
+		if !strings.EqualFold(strings.TrimSpace(record[i]), column) {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadScheduleOutput reads a schedule saved as JSON (e.g. output.json), use TaskOutput.ToTask
+// to turn its tasks back into scheduler input
+func LoadScheduleOutput(r io.Reader) (ScheduleOutput, error) {
+	var output ScheduleOutput
+	if err := json.NewDecoder(r).Decode(&output); err != nil {
+		return ScheduleOutput{}, fmt.Errorf("invalid schedule output: %w", err)
+	}
+	return output, nil
+}
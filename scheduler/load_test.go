@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoadTasksCSV(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expectedTasks []Task
+		expectedLine  int
+	}{
+		{
+			name:  "Rows with a header",
+			input: "start_time,end_time,priority\n2024-01-01T09:00:00Z,2024-01-01T10:00:00Z,5\n2024-01-01T10:00:00Z,2024-01-01T10:00:00Z,2.5\n",
+			expectedTasks: []Task{
+				{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 5},
+				{StartTime: fixedTime(10), EndTime: fixedTime(10), Priority: 2.5},
+			},
+		},
+		{
+			name:  "Rows without a header",
+			input: "2024-01-01T09:00:00Z, 2024-01-01T10:00:00Z, 5\n",
+			expectedTasks: []Task{
+				{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 5},
+			},
+		},
+		{
+			name:          "Empty input",
+			input:         "",
+			expectedTasks: []Task{},
+		},
+		{
+			name:         "Bad start time",
+			input:        "start_time,end_time,priority\n2024-01-01T09:00:00Z,2024-01-01T10:00:00Z,5\n9am,2024-01-01T10:00:00Z,5\n",
+			expectedLine: 3,
+		},
+		{
+			name:         "Bad priority",
+			input:        "2024-01-01T09:00:00Z,2024-01-01T10:00:00Z,high\n",
+			expectedLine: 1,
+		},
+		{
+			name:         "Missing column",
+			input:        "start_time,end_time,priority\n2024-01-01T09:00:00Z,2024-01-01T10:00:00Z\n",
+			expectedLine: 2,
+		},
+		{
+			name:         "Unterminated quote",
+			input:        "start_time,end_time,priority\n\"2024-01-01T09:00:00Z,2024-01-01T10:00:00Z,5\n",
+			expectedLine: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := LoadTasksCSV(strings.NewReader(tt.input))
+			if tt.expectedLine != 0 {
+				var malformed ErrMalformedRow
+				if !errors.As(err, &malformed) {
+					t.Fatalf("Expected ErrMalformedRow, got %v", err)
+				}
+				if malformed.Line != tt.expectedLine {
+					t.Errorf("Expected error on line %d, got line %d (%v)", tt.expectedLine, malformed.Line, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(tasks) != len(tt.expectedTasks) {
+				t.Fatalf("Expected %d tasks, got %d", len(tt.expectedTasks), len(tasks))
+			}
+			for i := range tasks {
+				expected := tt.expectedTasks[i]
+				if !tasks[i].StartTime.Equal(expected.StartTime) || !tasks[i].EndTime.Equal(expected.EndTime) || tasks[i].Priority != expected.Priority {
+					t.Errorf("Task %d: expected %+v, got %+v", i, expected, tasks[i])
+				}
+			}
+		})
+	}
+}
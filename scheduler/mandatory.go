@@ -0,0 +1,196 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrInfeasible is returned when the mandatory tasks can't all be scheduled, TaskIDs lists
+// the mandatory tasks involved
+type ErrInfeasible struct {
+	TaskIDs []string
+	Reason  string
+}
+
+func (e ErrInfeasible) Error() string {
+	return fmt.Sprintf("infeasible schedule, %s: %s", e.Reason, strings.Join(e.TaskIDs, ", "))
+}
+
+// mandatoryTasks picks the mandatory tasks out of tasks, in order
+func mandatoryTasks(tasks []Task) []Task {
+	mandatory := make([]Task, 0)
+	for _, task := range tasks {
+		if task.Mandatory {
+			mandatory = append(mandatory, task)
+		}
+	}
+	return mandatory
+}
+
+// checkMandatory makes sure no two mandatory tasks conflict with each other
+func (s *Scheduler) checkMandatory(mandatory []Task) error {
+	if taskIDs := conflictingIDs(mandatory, s.tasksConflict); len(taskIDs) > 0 {
+		return ErrInfeasible{TaskIDs: taskIDs, Reason: "mandatory tasks conflict"}
+	}
+	return nil
+}
+
+// conflictingIDs lists the IDs of the tasks that conflict with at least one other, in order
+func conflictingIDs(tasks []Task, conflict func(task1, task2 Task) bool) []string {
+	conflicting := make([]bool, len(tasks))
+	for i := range tasks {
+		for j := i + 1; j < len(tasks); j++ {
+			if conflict(tasks[i], tasks[j]) {
+				conflicting[i] = true
+				conflicting[j] = true
+			}
+		}
+	}
+	taskIDs := make([]string, 0)
+	for i := range tasks {
+		if conflicting[i] {
+			taskIDs = append(taskIDs, tasks[i].ID)
+		}
+	}
+	return taskIDs
+}
+
+// hardConflict checks if two tasks can never both be chosen: they conflict and WithSoftConflict
+// (if it's set) doesn't let them overlap for a penalty, or they share an exclusive group
+func (s *Scheduler) hardConflict(task1, task2 Task) bool {
+	if s.groupConflict(task1, task2) {
+		return true
+	}
+	if !s.tasksConflict(task1, task2) {
+		return false
+	}
+	if s.options.softConflict == nil {
+		return true
+	}
+	penalty := s.options.softConflict(task1, task2)
+	return math.IsNaN(penalty) || math.IsInf(penalty, 1)
+}
+
+// CheckFeasible reports whether every mandatory task can be scheduled, without running the
+// optimizer, so a UI can warn about an impossible plan straight away. When it can't, the IDs of
+// the mandatory tasks to blame come back in input order: ones the planning window, their
+// deadline or NotBefore rule out, and ones that conflict with another mandatory task. Conflicts
+// are judged exactly as FindBestSchedule judges them with the same options (resources, gaps,
+// setup and teardown, WithConflictFunc, hard soft conflicts and exclusive groups), and with
+// WithMaxTasks every mandatory task is listed if there are too many of them.
+//
+// Tasks without an ID get the one FindBestSchedule would give them. Input FindBestSchedule
+// would refuse as invalid comes back as infeasible with no IDs. Bundles aren't looked at, so a
+// feasible answer can still end in an ErrInfeasible when a mandatory task's bundle can't be
+// scheduled whole.
+func (s *Scheduler) CheckFeasible(tasks []Task, opts ...Option) (bool, []string) {
+	s = s.withOptions(opts)
+	if err := s.validateTasks(tasks); err != nil {
+		return false, nil
+	}
+	tasks = append([]Task(nil), tasks...)
+	s.assignMissingIDs(tasks)
+
+	mandatory := make([]Task, 0)
+	unschedulable := make([]string, 0)
+	for _, task := range tasks {
+		if !task.Mandatory {
+			continue
+		}
+		task = s.clampToWindow(s.snap(task))
+		if _, rejected := s.unschedulableReason(task); rejected {
+			unschedulable = append(unschedulable, task.ID)
+			continue
+		}
+		mandatory = append(mandatory, task)
+	}
+	if s.options.limitTasks && len(mandatory)+len(unschedulable) > s.options.maxTasks {
+		taskIDs := make([]string, 0)
+		for _, task := range tasks {
+			if task.Mandatory {
+				taskIDs = append(taskIDs, task.ID)
+			}
+		}
+		return false, taskIDs
+	}
+
+	// Report both kinds of problem together in input order
+	blamed := make(map[string]bool)
+	for _, id := range append(unschedulable, conflictingIDs(mandatory, s.hardConflict)...) {
+		blamed[id] = true
+	}
+	if len(blamed) == 0 {
+		return true, nil
+	}
+	taskIDs := make([]string, 0, len(blamed))
+	for _, task := range tasks {
+		if blamed[task.ID] {
+			taskIDs = append(taskIDs, task.ID)
+			delete(blamed, task.ID)
+		}
+	}
+	return false, taskIDs
+}
+
+// scheduleAroundMandatory commits every mandatory task on a timeline, rejects whatever
+// conflicts with them and runs the DP over the tasks that still fit in between
+func (s *Scheduler) scheduleAroundMandatory(ctx context.Context, span trace.Span, tasks []Task) ([]Task, float64, []RejectedTask, error) {
+	mandatory := mandatoryTasks(tasks)
+	if len(mandatory) == 0 {
+		return s.scheduleTimeline(ctx, span, tasks)
+	}
+	if err := s.checkMandatory(mandatory); err != nil {
+		return nil, 0, nil, err
+	}
+
+	// Since the mandatory tasks are always chosen, anything clashing with one is out
+	rejectedTasks := []RejectedTask{}
+	free := make([]Task, 0, len(tasks)-len(mandatory))
+	for _, task := range tasks {
+		if task.Mandatory {
+			continue
+		}
+		blocked := false
+		for _, committed := range mandatory {
+			if s.tasksConflict(task, committed) {
+				span.AddEvent("task_rejected", trace.WithAttributes(attribute.String("reason", RejectionReasonConflict.String())))
+				rejectedTasks = append(rejectedTasks, RejectedTask{
+					TaskRejected: task,
+					CausedByID:   committed.ID,
+					Reason:       RejectionReasonConflict,
+				})
+				blocked = true
+				break
+			}
+		}
+		if !blocked {
+			free = append(free, task)
+		}
+	}
+
+	chosenTasks := make([]Task, 0, len(mandatory))
+	totalPriority := 0.0
+	if len(free) > 0 {
+		freeChosen, freePriority, freeRejected, err := s.scheduleTimeline(ctx, span, free)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		chosenTasks = append(chosenTasks, freeChosen...)
+		totalPriority += freePriority
+		rejectedTasks = append(rejectedTasks, freeRejected...)
+	}
+	for _, task := range mandatory {
+		chosenTasks = append(chosenTasks, task)
+		totalPriority += task.Priority
+	}
+	sort.SliceStable(chosenTasks, func(first, second int) bool {
+		return chosenTasks[first].StartTime.Before(chosenTasks[second].StartTime)
+	})
+	return chosenTasks, totalPriority, rejectedTasks, nil
+}
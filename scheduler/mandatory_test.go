@@ -0,0 +1,214 @@
+package scheduler
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMandatoryTasks(t *testing.T) {
+	t.Run("Feasible mandatory set is scheduled around", func(t *testing.T) {
+		tasks := []Task{
+			{ID: "must", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 1, Mandatory: true},
+			{ID: "big", StartTime: fixedTime(9), EndTime: fixedTime(12), Priority: 20},
+			{ID: "early", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 3},
+			{ID: "late", StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 4},
+		}
+		resultTasks, resultPriority, rejected, err := newTestScheduler().FindBestSchedule(tasks)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resultPriority != 8 {
+			t.Errorf("Expected priority 8, got %.2f", resultPriority)
+		}
+		if len(resultTasks) != 3 || resultTasks[1].ID != "must" {
+			t.Errorf("Expected early, must, late to be chosen, got %+v", resultTasks)
+		}
+		if len(rejected) != 1 || rejected[0].TaskRejected.ID != "big" || rejected[0].CausedByID != "must" {
+			t.Errorf("Expected big to be rejected because of must, got %+v", rejected)
+		}
+	})
+
+	t.Run("Negative priority mandatory task is still scheduled", func(t *testing.T) {
+		tasks := []Task{
+			{ID: "must", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: -2, Mandatory: true},
+		}
+		resultTasks, resultPriority, _, err := newTestScheduler().FindBestSchedule(tasks)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(resultTasks) != 1 || resultPriority != -2 {
+			t.Errorf("Expected the mandatory task with priority -2, got %+v (%.2f)", resultTasks, resultPriority)
+		}
+	})
+
+	t.Run("Conflicting mandatory tasks are infeasible", func(t *testing.T) {
+		tasks := []Task{
+			{ID: "first", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 1, Mandatory: true},
+			{ID: "other", StartTime: fixedTime(12), EndTime: fixedTime(13), Priority: 1, Mandatory: true},
+			{ID: "second", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 1, Mandatory: true},
+		}
+		_, _, _, err := newTestScheduler().FindBestSchedule(tasks)
+		var infeasible ErrInfeasible
+		if !errors.As(err, &infeasible) {
+			t.Fatalf("Expected ErrInfeasible, got %v", err)
+		}
+		if len(infeasible.TaskIDs) != 2 || infeasible.TaskIDs[0] != "first" || infeasible.TaskIDs[1] != "second" {
+			t.Errorf("Expected first and second to be listed, got %v", infeasible.TaskIDs)
+		}
+	})
+
+	t.Run("Mandatory task past its deadline is infeasible", func(t *testing.T) {
+		tasks := []Task{
+			{ID: "late", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 1, Mandatory: true, Deadline: fixedTime(10)},
+		}
+		_, _, _, err := newTestScheduler().FindBestSchedule(tasks)
+		var infeasible ErrInfeasible
+		if !errors.As(err, &infeasible) {
+			t.Fatalf("Expected ErrInfeasible, got %v", err)
+		}
+	})
+
+	t.Run("Multi resource takes mandatory tasks over higher priorities", func(t *testing.T) {
+		tasks := []Task{
+			{ID: "must", StartTime: fixedTime(9), EndTime: fixedTime(12), Priority: 1, Mandatory: true},
+			{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(12), Priority: 10},
+			{ID: "b", StartTime: fixedTime(9), EndTime: fixedTime(12), Priority: 10},
+		}
+		_, resultPriority, _, err := newTestScheduler().FindBestScheduleMulti(tasks, 2)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resultPriority != 11 {
+			t.Errorf("Expected priority 11, got %.2f", resultPriority)
+		}
+
+		tasks[1].Mandatory = true
+		tasks[2].Mandatory = true
+		_, _, _, err = newTestScheduler().FindBestScheduleMulti(tasks, 2)
+		var infeasible ErrInfeasible
+		if !errors.As(err, &infeasible) {
+			t.Fatalf("Expected ErrInfeasible with three mandatory tasks on two resources, got %v", err)
+		}
+	})
+}
+
+func TestCheckFeasible(t *testing.T) {
+	must := func(id string, start, end int) Task {
+		return Task{ID: id, StartTime: fixedTime(start), EndTime: fixedTime(end), Priority: 1, Mandatory: true}
+	}
+	tests := []struct {
+		name             string
+		tasks            []Task
+		opts             []Option
+		expectedFeasible bool
+		expectedIDs      []string
+	}{
+		{
+			name:             "Mandatory tasks fit together",
+			tasks:            []Task{must("a", 9, 10), must("b", 10, 11), {ID: "free", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 5}},
+			expectedFeasible: true,
+		},
+		{
+			name:        "Overlapping mandatory tasks",
+			tasks:       []Task{must("a", 9, 11), must("other", 12, 13), must("b", 10, 12)},
+			expectedIDs: []string{"a", "b"},
+		},
+		{
+			name:        "Outside the window",
+			tasks:       []Task{must("early", 6, 7), must("a", 9, 10)},
+			opts:        []Option{WithWindow(fixedTime(8), fixedTime(18))},
+			expectedIDs: []string{"early"},
+		},
+		{
+			name:             "Clamped into the window",
+			tasks:            []Task{must("early", 7, 9), must("a", 9, 10)},
+			opts:             []Option{WithWindow(fixedTime(8), fixedTime(18)), WithClampToWindow()},
+			expectedFeasible: true,
+		},
+		{
+			name:        "Window and conflicts together",
+			tasks:       []Task{must("b", 10, 12), must("late", 19, 20), must("a", 9, 11)},
+			opts:        []Option{WithWindow(fixedTime(8), fixedTime(18))},
+			expectedIDs: []string{"b", "late", "a"},
+		},
+		{
+			name:             "Different resources",
+			tasks:            []Task{{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Mandatory: true, ResourceID: "x"}, {ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(12), Mandatory: true, ResourceID: "y"}},
+			expectedFeasible: true,
+		},
+		{
+			name:        "Too close for the minimum gap",
+			tasks:       []Task{must("a", 9, 10), must("b", 10, 11)},
+			opts:        []Option{WithMinGap(30 * time.Minute)},
+			expectedIDs: []string{"a", "b"},
+		},
+		{
+			name:        "Shared exclusive group",
+			tasks:       []Task{{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Mandatory: true, GroupID: "g"}, {ID: "b", StartTime: fixedTime(14), EndTime: fixedTime(15), Mandatory: true, GroupID: "g"}},
+			opts:        []Option{WithExclusiveGroups()},
+			expectedIDs: []string{"a", "b"},
+		},
+		{
+			name:        "More than WithMaxTasks",
+			tasks:       []Task{must("a", 9, 10), must("b", 11, 12)},
+			opts:        []Option{WithMaxTasks(1)},
+			expectedIDs: []string{"a", "b"},
+		},
+		{
+			name:             "Soft conflict with a penalty",
+			tasks:            []Task{must("a", 9, 11), must("b", 10, 12)},
+			opts:             []Option{WithSoftConflict(flatPenalty(1))},
+			expectedFeasible: true,
+		},
+		{
+			name:        "Soft conflict with an infinite penalty",
+			tasks:       []Task{must("a", 9, 11), must("b", 10, 12)},
+			opts:        []Option{WithSoftConflict(flatPenalty(math.Inf(1)))},
+			expectedIDs: []string{"a", "b"},
+		},
+		{
+			name:  "Invalid task",
+			tasks: []Task{{ID: "a", StartTime: fixedTime(9)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feasible, taskIDs := newTestScheduler().CheckFeasible(tt.tasks, tt.opts...)
+			if feasible != tt.expectedFeasible {
+				t.Errorf("Expected feasible %t, got %t", tt.expectedFeasible, feasible)
+			}
+			if !reflect.DeepEqual(taskIDs, tt.expectedIDs) {
+				t.Errorf("Expected IDs %v, got %v", tt.expectedIDs, taskIDs)
+			}
+		})
+	}
+}
+
+func TestCheckFeasibleMatchesFindBestSchedule(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	window := WithWindow(fixedTime(9), fixedTime(14))
+	for round := 0; round < 300; round++ {
+		tasks := make([]Task, 1+random.Intn(8))
+		for i := range tasks {
+			start := fixedTime(8).Add(time.Duration(random.Intn(14)) * 30 * time.Minute)
+			tasks[i] = Task{
+				StartTime:  start,
+				EndTime:    start.Add(time.Duration(random.Intn(4)) * 30 * time.Minute),
+				Priority:   float64(random.Intn(10)),
+				ResourceID: []string{"", "a"}[random.Intn(2)],
+				Mandatory:  random.Intn(3) == 0,
+			}
+		}
+		feasible, _ := newTestScheduler().CheckFeasible(tasks, window)
+		_, _, _, err := newTestScheduler().FindBestSchedule(tasks, window)
+		var infeasible ErrInfeasible
+		if feasible == errors.As(err, &infeasible) {
+			t.Fatalf("Round %d: expected CheckFeasible %t to agree with %v for %+v", round, feasible, err, tasks)
+		}
+	}
+}
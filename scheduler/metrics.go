@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// schedulerMetrics holds the instruments recorded on every FindBestSchedule call
+type schedulerMetrics struct {
+	scheduledTasks metric.Int64Counter
+	rejectedTasks  metric.Int64Counter
+	totalPriority  metric.Float64Histogram
+	duration       metric.Float64Histogram
+}
+
+// newSchedulerMetrics creates the scheduler's instruments on meter. The OTel API hands back
+// a working no-op instrument alongside any error, so a failure here only loses metrics.
+func newSchedulerMetrics(meter metric.Meter) (*schedulerMetrics, error) {
+	scheduledTasks, err := meter.Int64Counter("scheduler.tasks.scheduled",
+		metric.WithDescription("Number of tasks chosen by the scheduler"),
+		metric.WithUnit("{task}"))
+	if err != nil {
+		return nil, err
+	}
+	rejectedTasks, err := meter.Int64Counter("scheduler.tasks.rejected",
+		metric.WithDescription("Number of tasks rejected by the scheduler, by reason"),
+		metric.WithUnit("{task}"))
+	if err != nil {
+		return nil, err
+	}
+	totalPriority, err := meter.Float64Histogram("scheduler.total_priority",
+		metric.WithDescription("Total priority of each schedule found"))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.Float64Histogram("scheduler.duration_ms",
+		metric.WithDescription("Time spent computing each schedule, excluding validation and telemetry"),
+		metric.WithUnit("ms"))
+	if err != nil {
+		return nil, err
+	}
+	return &schedulerMetrics{
+		scheduledTasks: scheduledTasks,
+		rejectedTasks:  rejectedTasks,
+		totalPriority:  totalPriority,
+		duration:       duration,
+	}, nil
+}
+
+// record adds a finished schedule to the instruments
+func (m *schedulerMetrics) record(ctx context.Context, chosenTasks []Task, totalPriority float64, rejectedTasks []RejectedTask) {
+	// Schedulers built without NewScheduler have no instruments
+	if m == nil {
+		return
+	}
+	m.scheduledTasks.Add(ctx, int64(len(chosenTasks)))
+	rejectedByReason := make(map[RejectionReason]int64)
+	for _, rejected := range rejectedTasks {
+		rejectedByReason[rejected.Reason]++
+	}
+	for reason, count := range rejectedByReason {
+		m.rejectedTasks.Add(ctx, count, metric.WithAttributes(attribute.String("reason", reason.String())))
+	}
+	m.totalPriority.Record(ctx, totalPriority)
+}
+
+// recordDuration adds how long a schedule took to compute to the latency histogram
+func (m *schedulerMetrics) recordDuration(ctx context.Context, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.duration.Record(ctx, durationMillis(elapsed))
+}
+
+// durationMillis converts a duration to fractional milliseconds, fast schedules take well
+// under a millisecond
+func durationMillis(elapsed time.Duration) float64 {
+	return float64(elapsed) / float64(time.Millisecond)
+}
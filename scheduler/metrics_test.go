@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap"
+)
+
+// Helper function to find a metric by name in what the reader collected
+func findMetric(t *testing.T, data metricdata.ResourceMetrics, name string) metricdata.Metrics {
+	t.Helper()
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+	}
+	t.Fatalf("Metric %s was not recorded", name)
+	return metricdata.Metrics{}
+}
+
+func TestSchedulerMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	s := NewScheduler(SchedulerConfig{
+		Logger:        otelzap.New(zap.NewNop()),
+		MeterProvider: sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	})
+
+	tasks := []Task{
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 5},
+		{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 3},
+		{ID: "c", StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 4},
+		{ID: "d", StartTime: fixedTime(13), EndTime: fixedTime(14), Priority: 2, Deadline: fixedTime(12)},
+	}
+	// Run twice so we can see the counters add up across calls
+	for i := 0; i < 2; i++ {
+		if _, _, _, err := s.FindBestSchedule(tasks); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+
+	scheduled := findMetric(t, data, "scheduler.tasks.scheduled").Data.(metricdata.Sum[int64])
+	if len(scheduled.DataPoints) != 1 || scheduled.DataPoints[0].Value != 4 {
+		t.Errorf("Expected 4 scheduled tasks over two calls, got %+v", scheduled.DataPoints)
+	}
+
+	rejected := findMetric(t, data, "scheduler.tasks.rejected").Data.(metricdata.Sum[int64])
+	rejectedByReason := make(map[string]int64)
+	for _, point := range rejected.DataPoints {
+		reason, _ := point.Attributes.Value(attribute.Key("reason"))
+		rejectedByReason[reason.AsString()] = point.Value
+	}
+	if rejectedByReason["LOW_PRIORITY"] != 2 || rejectedByReason["DEADLINE_MISSED"] != 2 {
+		t.Errorf("Expected 2 LOW_PRIORITY and 2 DEADLINE_MISSED rejections, got %v", rejectedByReason)
+	}
+
+	totalPriority := findMetric(t, data, "scheduler.total_priority").Data.(metricdata.Histogram[float64])
+	if len(totalPriority.DataPoints) != 1 || totalPriority.DataPoints[0].Count != 2 || totalPriority.DataPoints[0].Sum != 18 {
+		t.Errorf("Expected two total priority samples summing to 18, got %+v", totalPriority.DataPoints)
+	}
+
+	duration := findMetric(t, data, "scheduler.duration_ms").Data.(metricdata.Histogram[float64])
+	if len(duration.DataPoints) != 1 || duration.DataPoints[0].Count != 2 || duration.DataPoints[0].Sum < 0 {
+		t.Errorf("Expected two duration samples, got %+v", duration.DataPoints)
+	}
+}
@@ -0,0 +1,319 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// flowEpsilon absorbs floating point noise when comparing path costs in the
+// min cost flow, otherwise rounding can look like an improving cycle
+const flowEpsilon = 1e-9
+
+// flowEdge is a single directed edge in the residual graph. Every edge is
+// stored next to its reverse edge so edge^1 is always its partner.
+type flowEdge struct {
+	to       int
+	capacity int
+	cost     float64
+	// task is the index of the task this edge represents, or -1 for the
+	// "resource is idle" edges that connect neighbouring points in time
+	task int
+}
+
+type flowGraph struct {
+	edges     []flowEdge
+	adjacency [][]int
+}
+
+func newFlowGraph(numNodes int) *flowGraph {
+	return &flowGraph{adjacency: make([][]int, numNodes)}
+}
+
+func (g *flowGraph) addEdge(from, to, capacity int, cost float64, task int) {
+	g.adjacency[from] = append(g.adjacency[from], len(g.edges))
+	g.edges = append(g.edges, flowEdge{to: to, capacity: capacity, cost: cost, task: task})
+	g.adjacency[to] = append(g.adjacency[to], len(g.edges))
+	g.edges = append(g.edges, flowEdge{to: from, capacity: 0, cost: -cost, task: task})
+}
+
+// shortestPath runs SPFA (queue based Bellman-Ford) over the residual graph,
+// we need it over Dijkstra because task edges carry negative costs
+func (g *flowGraph) shortestPath(source int) ([]float64, []int) {
+	numNodes := len(g.adjacency)
+	dist := make([]float64, numNodes)
+	inQueue := make([]bool, numNodes)
+	viaEdge := make([]int, numNodes)
+	reached := make([]bool, numNodes)
+	for i := range viaEdge {
+		viaEdge[i] = -1
+	}
+	reached[source] = true
+	queue := []int{source}
+	inQueue[source] = true
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		inQueue[node] = false
+		for _, edgeIndex := range g.adjacency[node] {
+			edge := g.edges[edgeIndex]
+			if edge.capacity == 0 {
+				continue
+			}
+			candidate := dist[node] + edge.cost
+			if !reached[edge.to] || candidate < dist[edge.to]-flowEpsilon {
+				reached[edge.to] = true
+				dist[edge.to] = candidate
+				viaEdge[edge.to] = edgeIndex
+				if !inQueue[edge.to] {
+					inQueue[edge.to] = true
+					queue = append(queue, edge.to)
+				}
+			}
+		}
+	}
+	return dist, viaEdge
+}
+
+// minCostFlow pushes up to maxFlow units from source to sink, stopping early
+// once no path lowers the total cost any further
+func (g *flowGraph) minCostFlow(source, sink, maxFlow int) {
+	for pushed := 0; pushed < maxFlow; {
+		dist, viaEdge := g.shortestPath(source)
+		if viaEdge[sink] == -1 || dist[sink] >= -flowEpsilon {
+			return
+		}
+		// Find how much we can push along this path
+		amount := maxFlow - pushed
+		for node := sink; node != source; node = g.edges[viaEdge[node]^1].to {
+			if capacity := g.edges[viaEdge[node]].capacity; capacity < amount {
+				amount = capacity
+			}
+		}
+		for node := sink; node != source; node = g.edges[viaEdge[node]^1].to {
+			g.edges[viaEdge[node]].capacity -= amount
+			g.edges[viaEdge[node]^1].capacity += amount
+		}
+		pushed += amount
+	}
+}
+
+// FindBestScheduleMulti finds the combination of tasks that gives us the highest total
+// priority when numResources identical resources (e.g. ground stations) can each run one
+// task at a time. It returns one chronologically ordered slice of tasks per resource.
+//
+// The problem is solved exactly as a min cost flow over the timeline: every resource is a
+// unit of flow walking forward in time, it can either sit idle or "ride" a task edge whose
+// cost is the negated priority. Two tasks can share a resource whenever tasksConflict says
+// they don't conflict, the same rules FindBestSchedule uses, so a zero duration task where one
+// task hands over to the next still conflicts with both and setup and teardown keep the resource
+// busy. With numResources == 1 this simply defers to FindBestSchedule.
+//
+// The resources are interchangeable, so there's nothing for Task.ResourceID to pin a task to.
+// Tasks with one set are refused with an error rather than ignored, whatever numResources is,
+// split them out and schedule each ResourceID with FindBestSchedule instead. numResources below
+// 1 is an error. Input goes through the same checks as FindBestSchedule, except that up to
+// numResources copies of a duplicated task are kept since each can run on its own resource.
+func (s *Scheduler) FindBestScheduleMulti(tasks []Task, numResources int, opts ...Option) ([][]Task, float64, []RejectedTask, error) {
+	for i, task := range tasks {
+		if task.ResourceID != "" {
+			return nil, 0, nil, fmt.Errorf("FindBestScheduleMulti does not support tasks pinned to a resource, task %d has ResourceID %q", i, task.ResourceID)
+		}
+	}
+	if numResources == 1 {
+		chosenTasks, totalPriority, rejectedTasks, err := s.FindBestSchedule(tasks, opts...)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		return [][]Task{chosenTasks}, totalPriority, rejectedTasks, nil
+	}
+	s = s.withOptions(opts)
+
+	ctx, span := s.tracer().Start(context.Background(), "FindBestScheduleMulti")
+	defer span.End()
+	logger := s.logger.Ctx(ctx)
+	span.SetAttributes(attribute.Int("num_tasks", len(tasks)), attribute.Int("num_resources", numResources))
+	logger.Info("Starting multi resource scheduler", zap.Int("num_tasks", len(tasks)), zap.Int("num_resources", numResources))
+
+	if numResources < 1 {
+		err := errors.New("FindBestScheduleMulti needs at least 1 resource")
+		span.RecordError(err)
+		return nil, 0, nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, 0, nil, nil
+	}
+	// The flow model only understands time overlap, and every task on a resource shares its one
+	// timeline
+	if s.options.conflictFunc != nil || s.options.softConflict != nil || s.options.instantaneousCoexist || s.options.overlapPolicy == ProRate {
+		err := errors.New("FindBestScheduleMulti does not support WithConflictFunc, WithSoftConflict, WithInstantaneousCoexist or the ProRate overlap policy")
+		span.RecordError(err)
+		return nil, 0, nil, err
+	}
+	if s.options.limitTasks || s.options.exclusiveGroups || len(sharedBundles(tasks)) > 0 || hasDependencies(tasks) {
+		err := errors.New("FindBestScheduleMulti does not support WithMaxTasks, WithExclusiveGroups, bundles or dependencies")
+		span.RecordError(err)
+		return nil, 0, nil, err
+	}
+	// Every resource can run its own copy of a task, so only copies past that are duplicates
+	tasks, rejectedTasks, _, err := s.prepareTasks(span, logger, tasks, numResources)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	chosenByResource := make([][]Task, numResources)
+	if len(tasks) == 0 {
+		return chosenByResource, 0, rejectedTasks, nil
+	}
+
+	// Collect every distinct instant, each gets four nodes: "pre" where a resource arrives idle
+	// from the instant before, "land" where tasks ending at it arrive, "leave" where tasks
+	// starting at it set off and "post" where the resource goes on idle to the next instant.
+	// Zero duration tasks run from pre to post, so like in tasksConflict one can only be taken
+	// by a resource that's idle on both sides of it, not one handing over between tasks.
+	// Every task ties its resource up over its occupied interval, setup and teardown included.
+	// A minimum gap is modelled by keeping the resource busy for minGap after each task ends,
+	// which also turns zero duration tasks into short regular ones
+	occupied := make([]Task, len(tasks))
+	for i, task := range tasks {
+		occupied[i] = s.occupied(task)
+	}
+	occupiedUntil := func(task Task) time.Time {
+		return s.sortKey(task).Add(s.options.minGap)
+	}
+	isInstant := func(task Task) bool {
+		return s.options.minGap <= 0 && s.isZeroDuration(task)
+	}
+	instants := make([]int64, 0, len(tasks)*2)
+	for _, task := range occupied {
+		instants = append(instants, task.StartTime.UnixNano())
+		if !isInstant(task) {
+			instants = append(instants, occupiedUntil(task).UnixNano())
+		}
+	}
+	sort.Slice(instants, func(first, second int) bool { return instants[first] < instants[second] })
+	instantIndex := make(map[int64]int, len(instants))
+	for _, instant := range instants {
+		if _, ok := instantIndex[instant]; !ok {
+			instantIndex[instant] = len(instantIndex)
+		}
+	}
+	numInstants := len(instantIndex)
+	pre := func(index int) int { return 4 * index }
+	land := func(index int) int { return 4*index + 1 }
+	leave := func(index int) int { return 4*index + 2 }
+	post := func(index int) int { return 4*index + 3 }
+
+	graph := newFlowGraph(4 * numInstants)
+	for i := 0; i < numInstants; i++ {
+		graph.addEdge(pre(i), post(i), numResources, 0, -1)
+		graph.addEdge(pre(i), leave(i), numResources, 0, -1)
+		graph.addEdge(land(i), leave(i), numResources, 0, -1)
+		graph.addEdge(land(i), post(i), numResources, 0, -1)
+		if i+1 < numInstants {
+			graph.addEdge(post(i), pre(i+1), numResources, 0, -1)
+		}
+	}
+	// Mandatory tasks get a bonus bigger than every other priority combined, so the flow
+	// takes all of them whenever that's possible at all
+	mandatoryBonus := 1.0
+	for _, task := range tasks {
+		mandatoryBonus += math.Abs(task.Priority)
+	}
+	for i, task := range tasks {
+		cost := -task.Priority
+		if task.Mandatory {
+			cost -= mandatoryBonus
+		}
+		// Tasks that can't improve the total never need an edge
+		if cost >= 0 {
+			continue
+		}
+		start := instantIndex[occupied[i].StartTime.UnixNano()]
+		if isInstant(occupied[i]) {
+			graph.addEdge(pre(start), post(start), 1, cost, i)
+		} else {
+			graph.addEdge(leave(start), land(instantIndex[occupiedUntil(occupied[i]).UnixNano()]), 1, cost, i)
+		}
+	}
+	graph.minCostFlow(pre(0), post(numInstants-1), numResources)
+
+	// Walk each unit of flow from the first instant to the last, the task edges it
+	// used become that resource's schedule
+	chosen := make([]bool, len(tasks))
+	totalPriority := 0.0
+	for resource := 0; resource < numResources; resource++ {
+		resourceTasks := make([]Task, 0)
+		for node := pre(0); node != post(numInstants-1); {
+			next := -1
+			for _, edgeIndex := range graph.adjacency[node] {
+				// Forward edges have even indexes, used flow shows up on the reverse edge
+				if edgeIndex%2 != 0 || graph.edges[edgeIndex^1].capacity == 0 {
+					continue
+				}
+				// Prefer following a task edge over an idle one
+				if next == -1 || graph.edges[edgeIndex].task != -1 {
+					next = edgeIndex
+				}
+			}
+			if next == -1 {
+				break
+			}
+			graph.edges[next^1].capacity--
+			if task := graph.edges[next].task; task != -1 {
+				resourceTasks = append(resourceTasks, tasks[task])
+				chosen[task] = true
+				totalPriority += tasks[task].Priority
+			}
+			node = graph.edges[next].to
+		}
+		chosenByResource[resource] = resourceTasks
+	}
+
+	missedMandatory := make([]string, 0)
+	for i, task := range tasks {
+		if task.Mandatory && !chosen[i] {
+			missedMandatory = append(missedMandatory, task.ID)
+		}
+	}
+	if len(missedMandatory) > 0 {
+		err := ErrInfeasible{TaskIDs: missedMandatory, Reason: "not enough resources for the mandatory tasks"}
+		span.RecordError(err)
+		logger.Warn("Multi resource scheduler failed", zap.Error(err))
+		return nil, 0, nil, err
+	}
+
+	// Anything left out either clashed with a chosen task while every resource was
+	// busy, or wasn't worth scheduling at all. Each resource's tasks are sorted so the
+	// conflicting one can be searched for rather than checked against every chosen task.
+	sortedByResource := make([][]Task, numResources)
+	for resource, schedule := range chosenByResource {
+		sortedByResource[resource] = append([]Task(nil), schedule...)
+		s.sortTasks(sortedByResource[resource])
+	}
+	for i := range tasks {
+		if chosen[i] {
+			continue
+		}
+		rejection := RejectedTask{TaskRejected: tasks[i], Reason: RejectionReasonLowPriority}
+		for _, schedule := range sortedByResource {
+			if j := s.findConflictingChosen(schedule, tasks[i]); j != -1 {
+				rejection.CausedByID = schedule[j].ID
+				rejection.Reason = RejectionReasonConflict
+				break
+			}
+		}
+		span.AddEvent("task_rejected", trace.WithAttributes(attribute.String("reason", rejection.Reason.String())))
+		rejectedTasks = append(rejectedTasks, rejection)
+	}
+
+	span.AddEvent("scheduler_finished", trace.WithAttributes(attribute.Int("num_rejected_tasks", len(rejectedTasks))))
+	logger.Info("Multi resource scheduler finished", zap.Float64("total_priority", totalPriority), zap.Int("num_rejected_tasks", len(rejectedTasks)))
+	return chosenByResource, totalPriority, rejectedTasks, nil
+}
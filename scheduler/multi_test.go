@@ -0,0 +1,300 @@
+package scheduler
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// Helper function to total up the priority across every resource
+func multiPriority(schedules [][]Task) float64 {
+	total := 0.0
+	for _, schedule := range schedules {
+		for _, task := range schedule {
+			total += task.Priority
+		}
+	}
+	return total
+}
+
+func TestFindBestScheduleMulti(t *testing.T) {
+	tests := []struct {
+		name             string
+		tasks            []Task
+		numResources     int
+		expectedPriority float64
+		expectedRejected int
+	}{
+		{
+			name:             "Empty task list",
+			tasks:            []Task{},
+			numResources:     2,
+			expectedPriority: 0,
+		},
+		{
+			name: "Two overlapping tasks fit on two resources",
+			tasks: []Task{
+				{StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 7},
+				{StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 4},
+			},
+			numResources:     2,
+			expectedPriority: 11,
+		},
+		{
+			name: "Three overlapping tasks on two resources drops the lowest",
+			tasks: []Task{
+				{StartTime: fixedTime(9), EndTime: fixedTime(12), Priority: 5},
+				{StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 3},
+				{StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 8},
+			},
+			numResources:     2,
+			expectedPriority: 13,
+			expectedRejected: 1,
+		},
+		{
+			name: "Long task on one resource and a chain on the other",
+			tasks: []Task{
+				{StartTime: fixedTime(9), EndTime: fixedTime(13), Priority: 10},
+				{StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 6},
+				{StartTime: fixedTime(11), EndTime: fixedTime(13), Priority: 6},
+				{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 4},
+				{StartTime: fixedTime(10), EndTime: fixedTime(13), Priority: 4},
+			},
+			numResources:     2,
+			expectedPriority: 22,
+			expectedRejected: 2,
+		},
+		{
+			name: "More resources than tasks schedules everything",
+			tasks: []Task{
+				{StartTime: fixedTime(9), EndTime: fixedTime(12), Priority: 5},
+				{StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 3},
+				{StartTime: fixedTime(10), EndTime: fixedTime(10), Priority: 2},
+			},
+			numResources:     5,
+			expectedPriority: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedules, totalPriority, rejected, err := newTestScheduler().FindBestScheduleMulti(tt.tasks, tt.numResources)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if totalPriority != tt.expectedPriority {
+				t.Errorf("Priority mismatch: expected %.2f, got %.2f", tt.expectedPriority, totalPriority)
+			}
+			if len(tt.tasks) > 0 && len(schedules) != tt.numResources {
+				t.Errorf("Expected %d schedules, got %d", tt.numResources, len(schedules))
+			}
+			if sum := multiPriority(schedules); sum != totalPriority {
+				t.Errorf("Returned tasks sum to %.2f but total priority is %.2f", sum, totalPriority)
+			}
+			if len(rejected) != tt.expectedRejected {
+				t.Errorf("Expected %d rejected tasks, got %d", tt.expectedRejected, len(rejected))
+			}
+
+			// Every resource must be conflict free on its own
+			s := newTestScheduler()
+			for resource, schedule := range schedules {
+				for i := range schedule {
+					for j := i + 1; j < len(schedule); j++ {
+						if s.tasksConflict(schedule[i], schedule[j]) {
+							t.Errorf("Resource %d has overlapping tasks %d and %d", resource, i, j)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFindBestScheduleMultiSingleResource(t *testing.T) {
+	tasks := []Task{
+		{StartTime: fixedTime(9), EndTime: fixedTime(12), Priority: 15},
+		{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 6},
+		{StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 6},
+		{StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 6},
+	}
+	schedules, totalPriority, _, err := newTestScheduler().FindBestScheduleMulti(tasks, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(schedules) != 1 {
+		t.Fatalf("Expected 1 schedule, got %d", len(schedules))
+	}
+	if totalPriority != 18 {
+		t.Errorf("Expected priority 18, got %.2f", totalPriority)
+	}
+}
+
+func TestFindBestScheduleMultiInstantsAtBoundaries(t *testing.T) {
+	// An instant where one task hands over to the next conflicts with both, on every resource
+	tasks := []Task{
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 5},
+		{ID: "instant", StartTime: fixedTime(10), EndTime: fixedTime(10), Priority: 3},
+		{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 5},
+	}
+	for numResources := 1; numResources <= 2; numResources++ {
+		doubled := make([]Task, 0, numResources*len(tasks))
+		for i := 0; i < numResources; i++ {
+			doubled = append(doubled, tasks...)
+		}
+		schedules, totalPriority, _, err := newTestScheduler().FindBestScheduleMulti(doubled, numResources)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if expected := 10 * float64(numResources); totalPriority != expected {
+			t.Errorf("Expected %v on %d resources, got %v from %+v", expected, numResources, totalPriority, schedules)
+		}
+	}
+}
+
+func TestFindBestScheduleMultiSetupAndTeardown(t *testing.T) {
+	// Both setups reach back into a, so neither b nor c can follow it on the same resource
+	tasks := []Task{
+		{ID: "a", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 5},
+		{ID: "b", StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 3, SetupBefore: 30 * time.Minute},
+		{ID: "c", StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 3, SetupBefore: 30 * time.Minute},
+	}
+	s := newTestScheduler()
+	schedules, totalPriority, rejected, err := s.FindBestScheduleMulti(tasks, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if totalPriority != 8 {
+		t.Errorf("Expected 8, got %v from %+v", totalPriority, schedules)
+	}
+	for resource, schedule := range schedules {
+		for i := range schedule {
+			for j := i + 1; j < len(schedule); j++ {
+				if s.tasksConflict(schedule[i], schedule[j]) {
+					t.Errorf("Resource %d has conflicting tasks %s and %s", resource, schedule[i].ID, schedule[j].ID)
+				}
+			}
+		}
+	}
+	if len(rejected) != 1 || rejected[0].Reason != RejectionReasonConflict {
+		t.Errorf("Expected one task rejected for a conflict, got %+v", rejected)
+	}
+}
+
+func TestFindBestScheduleMultiMatchesSingleResource(t *testing.T) {
+	optionSets := map[string][]Option{
+		"default": nil,
+		"min gap": {WithMinGap(20 * time.Minute)},
+	}
+	for name, opts := range optionSets {
+		t.Run(name, func(t *testing.T) {
+			random := rand.New(rand.NewSource(1))
+			for round := 0; round < 300; round++ {
+				tasks := randomCapacityTasks(random, 1+random.Intn(10))
+				for i := range tasks {
+					tasks[i].ResourceID = ""
+					tasks[i].SetupBefore = time.Duration(random.Intn(3)) * 15 * time.Minute
+					tasks[i].TeardownAfter = time.Duration(random.Intn(3)) * 15 * time.Minute
+				}
+				_, expected, _, err := newTestScheduler().FindBestSchedule(tasks, opts...)
+				if err != nil {
+					t.Fatalf("Round %d: unexpected error: %v", round, err)
+				}
+				// Two resources with two copies of every task can do exactly twice as well, each
+				// resource can at best run the single resource schedule
+				schedules, totalPriority, _, err := newTestScheduler().FindBestScheduleMulti(append(tasks, tasks...), 2, opts...)
+				if err != nil {
+					t.Fatalf("Round %d: unexpected error: %v", round, err)
+				}
+				if math.Abs(totalPriority-2*expected) > 1e-9 {
+					t.Fatalf("Round %d: expected %v, got %v for %+v", round, 2*expected, totalPriority, tasks)
+				}
+				s := newTestScheduler().withOptions(opts)
+				for resource, schedule := range schedules {
+					for i := range schedule {
+						for j := i + 1; j < len(schedule); j++ {
+							if s.tasksConflict(schedule[i], schedule[j]) {
+								t.Fatalf("Round %d: resource %d has conflicting tasks %+v and %+v", round, resource, schedule[i], schedule[j])
+							}
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFindBestScheduleMultiRefusesPinnedTasks(t *testing.T) {
+	tasks := []Task{
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 5},
+		{ID: "pinned", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 8, ResourceID: "A"},
+	}
+	for numResources := 1; numResources <= 2; numResources++ {
+		if _, _, _, err := newTestScheduler().FindBestScheduleMulti(tasks, numResources); err == nil {
+			t.Errorf("Expected a pinned task to be refused on %d resources", numResources)
+		}
+	}
+}
+
+func TestFindBestScheduleMultiRefusesSoftConflict(t *testing.T) {
+	tasks := []Task{
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 5},
+		{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 3},
+	}
+	if _, _, _, err := newTestScheduler().FindBestScheduleMulti(tasks, 2, WithSoftConflict(flatPenalty(1))); err == nil {
+		t.Error("Expected FindBestScheduleMulti to refuse WithSoftConflict")
+	}
+}
+
+func TestFindBestScheduleMultiRefusesInstantaneousCoexist(t *testing.T) {
+	tasks := []Task{
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 5},
+		{ID: "instant", StartTime: fixedTime(10), EndTime: fixedTime(10), Priority: 3},
+	}
+	if _, _, _, err := newTestScheduler().FindBestScheduleMulti(tasks, 2, WithInstantaneousCoexist()); err == nil {
+		t.Error("Expected FindBestScheduleMulti to refuse WithInstantaneousCoexist")
+	}
+}
+
+func TestFindBestScheduleMultiRefusesNoResources(t *testing.T) {
+	tasks := []Task{
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 5},
+	}
+	for _, numResources := range []int{0, -1} {
+		if _, _, _, err := newTestScheduler().FindBestScheduleMulti(tasks, numResources); err == nil {
+			t.Errorf("Expected an error for %d resources", numResources)
+		}
+	}
+}
+
+func TestFindBestScheduleMultiDuplicates(t *testing.T) {
+	// Two resources can each take a copy, the third copy is a duplicate
+	tasks := []Task{
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 5},
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 5},
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 5},
+	}
+	_, totalPriority, rejected, err := newTestScheduler().FindBestScheduleMulti(tasks, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if totalPriority != 10 {
+		t.Errorf("Expected priority 10, got %.2f", totalPriority)
+	}
+	if len(rejected) != 1 || rejected[0].Reason != RejectionReasonDuplicate {
+		t.Errorf("Expected 1 duplicate rejection, got %+v", rejected)
+	}
+}
+
+func TestFindBestScheduleMultiStrictTies(t *testing.T) {
+	tasks := []Task{
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 5},
+		{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 3},
+	}
+	_, _, _, err := newTestScheduler().FindBestScheduleMulti(tasks, 2, WithStrictTies())
+	var tie ErrAmbiguousTie
+	if !errors.As(err, &tie) {
+		t.Errorf("Expected an ErrAmbiguousTie, got %v", err)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !scheduler_debug
+
+package scheduler
+
+// debugChecks is off outside scheduler_debug builds, see debug.go
+const debugChecks = false
@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"math"
+	"time"
+)
+
+// ObjectiveMode picks what the scheduler optimises for
+type ObjectiveMode int
+
+const (
+	// MaxPriority maximises the summed priority of the chosen tasks
+	MaxPriority ObjectiveMode = iota
+	// MaxPriorityThenUtilization maximises summed priority and, among schedules with the same
+	// total, prefers the one that keeps the timeline busy for longest
+	MaxPriorityThenUtilization
+	// MaxNetValue maximises the summed Value-Cost of the chosen tasks. Each task's Priority is
+	// replaced by its net value, so the returned total, the tasks' Priority and the rejection
+	// reasons are all in net value, the same as with WithEffectivePriority.
+	MaxNetValue
+)
+
+// TieBreak picks between schedules that are equally good under the objective
+type TieBreak int
+
+const (
+	// TieBreakNone leaves ties to the order the DP visits tasks in
+	TieBreakNone TieBreak = iota
+	// TieBreakShorter prefers more tasks and then less time spent on them, e.g. two short
+	// tasks over one long task worth the same. Only tasks with a positive priority count, so
+	// it never adds zero priority tasks just to make up the numbers.
+	TieBreakShorter
+)
+
+// scheduleValue is what the DP accumulates for a partial schedule. Priority always comes first,
+// the other fields only break ties depending on the objective.
+type scheduleValue struct {
+	priority float64
+	// scaledPriority is the priority in fixed-point, only kept with WithFixedPointPriorities
+	scaledPriority int64
+	utilization    time.Duration
+	// count is how many tasks with a positive priority the schedule has, for TieBreakShorter
+	count int
+}
+
+// taskValue is the value a single task adds to a schedule
+func (s *Scheduler) taskValue(task Task) scheduleValue {
+	value := scheduleValue{priority: task.Priority}
+	if s.options.priorityScale > 0 {
+		value.scaledPriority = int64(math.Round(task.Priority * s.options.priorityScale))
+	}
+	if !s.isZeroDuration(task) {
+		value.utilization = task.EndTime.Sub(task.StartTime)
+	}
+	if task.Priority > 0 {
+		value.count = 1
+	}
+	return value
+}
+
+func (v scheduleValue) plus(other scheduleValue) scheduleValue {
+	return scheduleValue{
+		priority:       v.priority + other.priority,
+		scaledPriority: v.scaledPriority + other.scaledPriority,
+		utilization:    v.utilization + other.utilization,
+		count:          v.count + other.count,
+	}
+}
+
+// betterValue reports if first is strictly better than second under the objective. Float
+// priorities are compared exactly, so sums that differ only by rounding aren't ties, use
+// WithFixedPointPriorities when that matters.
+func (s *Scheduler) betterValue(first, second scheduleValue) bool {
+	if s.options.priorityScale > 0 {
+		if first.scaledPriority != second.scaledPriority {
+			return first.scaledPriority > second.scaledPriority
+		}
+	} else if first.priority != second.priority {
+		return first.priority > second.priority
+	}
+	if s.options.objective == MaxPriorityThenUtilization && first.utilization != second.utilization {
+		return first.utilization > second.utilization
+	}
+	if s.options.tieBreak == TieBreakShorter {
+		if first.count != second.count {
+			return first.count > second.count
+		}
+		return first.utilization < second.utilization
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestObjectiveUtilization(t *testing.T) {
+	newTasks := func() []Task {
+		return []Task{
+			{ID: "long", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 8},
+			{ID: "first", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 4},
+			{ID: "second", StartTime: fixedTime(10), EndTime: fixedTime(10).Add(30 * time.Minute), Priority: 4},
+		}
+	}
+
+	resultTasks, resultPriority, _, err := newTestScheduler().FindBestSchedule(newTasks())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resultPriority != 8 || len(resultTasks) != 2 {
+		t.Errorf("Expected the two short tasks by default, got %+v", resultTasks)
+	}
+
+	resultTasks, resultPriority, _, err = newTestScheduler().FindBestSchedule(newTasks(), WithObjective(MaxPriorityThenUtilization))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resultPriority != 8 || len(resultTasks) != 1 || resultTasks[0].ID != "long" {
+		t.Errorf("Expected the long task when preferring utilization, got %+v", resultTasks)
+	}
+}
+
+func TestObjectiveUtilizationNeverLosesPriority(t *testing.T) {
+	tasks := []Task{
+		{ID: "long", StartTime: fixedTime(9), EndTime: fixedTime(13), Priority: 5},
+		{ID: "short", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 6},
+	}
+	resultTasks, resultPriority, _, err := newTestScheduler().FindBestSchedule(tasks, WithObjective(MaxPriorityThenUtilization))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resultPriority != 6 || resultTasks[0].ID != "short" {
+		t.Errorf("Expected utilization to only break ties, got %+v", resultTasks)
+	}
+}
+
+func TestWithFixedPointPriorities(t *testing.T) {
+	// A run of short tasks against one long task worth exactly what they add up to, as floats
+	// the sums land either side of the long task's priority depending on the order
+	newTasks := func(priorities []float64, long float64) []Task {
+		tasks := make([]Task, 0, len(priorities)+1)
+		for i, priority := range priorities {
+			tasks = append(tasks, Task{ID: fmt.Sprintf("short-%d", i), StartTime: fixedTime(i), EndTime: fixedTime(i + 1), Priority: priority})
+		}
+		return append(tasks, Task{ID: "long", StartTime: fixedTime(0), EndTime: fixedTime(len(priorities)), Priority: long})
+	}
+	tenths := make([]float64, 100)
+	for i := range tenths {
+		tenths[i] = 0.1
+	}
+	tests := []struct {
+		name  string
+		tasks []Task
+	}{
+		{name: "Many tenths", tasks: newTasks(tenths, 10)},
+		{name: "Ascending", tasks: newTasks([]float64{0.1, 0.2, 0.3}, 0.6)},
+		{name: "Descending", tasks: newTasks([]float64{0.3, 0.2, 0.1}, 0.6)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The sums tie exactly, so the long task keeps its place in every order the
+			// input comes in
+			random := rand.New(rand.NewSource(1))
+			for run := 0; run < 10; run++ {
+				random.Shuffle(len(tt.tasks), func(i, j int) {
+					tt.tasks[i], tt.tasks[j] = tt.tasks[j], tt.tasks[i]
+				})
+				resultTasks, _, _, err := newTestScheduler().FindBestSchedule(tt.tasks, WithFixedPointPriorities(1000))
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if len(resultTasks) != 1 || resultTasks[0].ID != "long" {
+					t.Fatalf("Expected the long task on run %d, got %+v", run, resultTasks)
+				}
+			}
+		})
+	}
+}
+
+func TestTieBreakShorter(t *testing.T) {
+	tests := []struct {
+		name            string
+		tasks           []Task
+		opts            []Option
+		expectedDefault []string
+		expectedShorter []string
+	}{
+		{
+			name: "Long task worth exactly the short ones",
+			tasks: []Task{
+				{ID: "first", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 4},
+				{ID: "long", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 8},
+				{ID: "second", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 4},
+			},
+			expectedDefault: []string{"long"},
+			expectedShorter: []string{"first", "second"},
+		},
+		{
+			name: "Same number of tasks, less time",
+			tasks: []Task{
+				{ID: "long", StartTime: fixedTime(8), EndTime: fixedTime(12), Priority: 5},
+				{ID: "short", StartTime: fixedTime(11), EndTime: fixedTime(13), Priority: 5},
+			},
+			expectedDefault: []string{"long"},
+			expectedShorter: []string{"short"},
+		},
+		{
+			name: "Zero priority tasks don't make up the numbers",
+			tasks: []Task{
+				{ID: "long", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 8},
+				{ID: "free", StartTime: fixedTime(12), EndTime: fixedTime(13), Priority: 0},
+			},
+			expectedDefault: []string{"long", "free"},
+			expectedShorter: []string{"long"},
+		},
+		{
+			name: "Utilization objective comes first",
+			tasks: []Task{
+				{ID: "first", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 4},
+				{ID: "long", StartTime: fixedTime(9), EndTime: fixedTime(12), Priority: 8},
+				{ID: "second", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 4},
+			},
+			opts:            []Option{WithObjective(MaxPriorityThenUtilization)},
+			expectedDefault: []string{"long"},
+			expectedShorter: []string{"long"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, run := range []struct {
+				opts     []Option
+				expected []string
+			}{
+				{tt.opts, tt.expectedDefault},
+				{append(tt.opts, WithTieBreak(TieBreakShorter)), tt.expectedShorter},
+			} {
+				resultTasks, _, rejected, err := newTestScheduler().FindBestSchedule(tt.tasks, run.opts...)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if len(resultTasks) != len(run.expected) {
+					t.Fatalf("Expected %v, got %+v", run.expected, resultTasks)
+				}
+				for i, id := range run.expected {
+					if resultTasks[i].ID != id {
+						t.Errorf("Expected task %d to be %s, got %s", i, id, resultTasks[i].ID)
+					}
+				}
+				if len(resultTasks)+len(rejected) != len(tt.tasks) {
+					t.Errorf("Expected every task to be chosen or rejected, got %d and %d", len(resultTasks), len(rejected))
+				}
+			}
+		})
+	}
+}
+
+func TestTieBreakShorterNeverLosesPriority(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for round := 0; round < 200; round++ {
+		tasks := GenerateTasks(1+random.Intn(30), GenOptions{Seed: int64(round), MaxPriority: 3})
+		_, expected, _, err := newTestScheduler().FindBestSchedule(tasks)
+		if err != nil {
+			t.Fatalf("Round %d: unexpected error: %v", round, err)
+		}
+		_, totalPriority, _, err := newTestScheduler().FindBestSchedule(tasks, WithTieBreak(TieBreakShorter), WithReconstructionCheck())
+		if err != nil {
+			t.Fatalf("Round %d: unexpected error: %v", round, err)
+		}
+		if totalPriority != expected {
+			t.Fatalf("Round %d: expected %v, got %v", round, expected, totalPriority)
+		}
+	}
+}
+
+func TestMaxNetValue(t *testing.T) {
+	tasks := []Task{
+		{ID: "priority", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 10, Value: 400, Cost: 300},
+		{ID: "profit", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 1, Value: 500, Cost: 150},
+		{ID: "loss", StartTime: fixedTime(13), EndTime: fixedTime(14), Priority: 5, Value: 100, Cost: 200},
+	}
+
+	chosen, totalPriority, _, err := newTestScheduler().FindBestSchedule(tasks)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if totalPriority != 15 || len(chosen) != 2 || chosen[0].ID != "priority" || chosen[1].ID != "loss" {
+		t.Errorf("Expected priority to pick priority and loss by default, got %+v worth %v", chosen, totalPriority)
+	}
+
+	chosen, totalPriority, rejected, err := newTestScheduler().FindBestSchedule(tasks, WithObjective(MaxNetValue))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if totalPriority != 350 || len(chosen) != 1 || chosen[0].ID != "profit" || chosen[0].Priority != 350 {
+		t.Errorf("Expected only profit worth 350, got %+v worth %v", chosen, totalPriority)
+	}
+	if len(rejected) != 2 {
+		t.Fatalf("Expected 2 rejected, got %+v", rejected)
+	}
+	for _, rejection := range rejected {
+		expected := map[string]RejectionReason{"priority": RejectionReasonConflict, "loss": RejectionReasonLowPriority}[rejection.TaskRejected.ID]
+		if rejection.Reason != expected {
+			t.Errorf("Expected %s rejected as %s, got %s", rejection.TaskRejected.ID, expected, rejection.Reason)
+		}
+	}
+	statistics := ComputeStatistics(chosen, newTimeRange(fixedTime(8), fixedTime(18)))
+	if statistics.TotalValue != 500 || statistics.TotalCost != 150 || statistics.NetValue != totalPriority {
+		t.Errorf("Expected the statistics to total the chosen value and cost, got %+v", statistics)
+	}
+
+	var invalid ErrInvalidTask
+	infinite := []Task{{StartTime: fixedTime(9), EndTime: fixedTime(10), Value: math.Inf(1)}}
+	if _, _, _, err := newTestScheduler().FindBestSchedule(infinite, WithObjective(MaxNetValue)); !errors.As(err, &invalid) {
+		t.Errorf("Expected ErrInvalidTask for an infinite value, got %v", err)
+	}
+	overflow := []Task{{StartTime: fixedTime(9), EndTime: fixedTime(10), Value: math.MaxFloat64, Cost: -math.MaxFloat64}}
+	if _, _, _, err := newTestScheduler().FindBestSchedule(overflow, WithObjective(MaxNetValue)); !errors.As(err, &invalid) {
+		t.Errorf("Expected ErrInvalidTask for a net value that overflows, got %v", err)
+	}
+}
@@ -0,0 +1,382 @@
+package scheduler
+
+import "time"
+
+// Option tweaks how a single FindBestSchedule call behaves
+type Option func(*scheduleOptions)
+
+// scheduleOptions holds the settings built up from the Options passed to a call
+type scheduleOptions struct {
+	// allowNegativeDuration lets tasks that end before they start through validation,
+	// they're then treated like zero duration tasks at their start time
+	allowNegativeDuration bool
+	// minGap is the smallest allowed idle time between two tasks on the same timeline
+	minGap time.Duration
+	// conflictFunc replaces tasksConflict's time based rules when set
+	conflictFunc func(a, b Task) bool
+	// objective decides how the DP compares two partial schedules
+	objective ObjectiveMode
+	// tieBreak decides between partial schedules the objective rates the same
+	tieBreak TieBreak
+	// parallel solves independent clusters of tasks concurrently
+	parallel bool
+	// limitTasks turns on the cap on how many tasks can be chosen, maxTasks is the cap
+	limitTasks bool
+	maxTasks   int
+	// placementStep is how far apart FindBestScheduleFlex tries a FlexTask's starts
+	placementStep time.Duration
+	// exclusiveGroups allows at most one chosen task per Task.GroupID
+	exclusiveGroups bool
+	// trace collects the DP tables when set
+	trace *ScheduleTrace
+	// priorityScale turns on fixed-point priorities when positive, see WithFixedPointPriorities
+	priorityScale float64
+	// instantaneousCoexist stops zero duration tasks conflicting with regular ones
+	instantaneousCoexist bool
+	// outputOrder is the order FindBestSchedule returns the chosen tasks in
+	outputOrder OutputOrder
+	// softConflict turns conflicts into penalties when set, see WithSoftConflict
+	softConflict func(a, b Task) float64
+	// priorityMapping overrides the default priorities of Task.Level, see WithPriorityMapping
+	priorityMapping map[PriorityLevel]float64
+	// effectivePriority replaces each task's priority when set, see WithEffectivePriority
+	effectivePriority func(Task) float64
+	// window turns on the planning window from windowStart to windowEnd, clampToWindow cuts
+	// tasks that stick out of it down to size instead of rejecting them
+	window        bool
+	windowStart   time.Time
+	windowEnd     time.Time
+	clampToWindow bool
+	// reconstructionCheck errors if the chosen tasks don't add up to the reported total
+	reconstructionCheck bool
+	// snap is the granularity task times are rounded to, zero or less leaves them alone
+	snap time.Duration
+	// preSorted trusts the input to already be in the DP's order, see WithPreSorted
+	preSorted bool
+	// baggageKeys are the baggage members copied onto the span and logs, nil for the defaults
+	baggageKeys []string
+	// strictTies errors on conflicting tasks that end together, see WithStrictTies
+	strictTies bool
+	// overlapPolicy decides what choosing overlapping tasks means, see WithOverlapPolicy
+	overlapPolicy OverlapPolicy
+}
+
+func newScheduleOptions(opts []Option) scheduleOptions {
+	options := scheduleOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithAllowNegativeDuration accepts tasks whose EndTime is before their StartTime instead of
+// rejecting them as invalid, they're scheduled as if they were zero duration
+func WithAllowNegativeDuration() Option {
+	return func(o *scheduleOptions) {
+		o.allowNegativeDuration = true
+	}
+}
+
+// WithMinGap requires at least d of idle time between any two chosen tasks that share
+// a timeline, e.g. to leave room for an antenna to slew between passes
+func WithMinGap(d time.Duration) Option {
+	return func(o *scheduleOptions) {
+		o.minGap = d
+	}
+}
+
+// WithConflictFunc replaces the time overlap rules with a caller supplied conflict check,
+// for example when tasks clash because they share keep-out geometry rather than time.
+// The predecessor search and the rejection classification both use fn.
+//
+// The fast binary search for a compatible predecessor relies on conflicts being about time
+// (tasks that finish earlier never conflict more than ones that finish later), which an
+// arbitrary fn can't promise. With a custom fn the search falls back to a linear scan for the
+// latest compatible task, and the result is only optimal if fn has that same property.
+// ResourceID and WithMinGap are ignored since fn decides everything.
+func WithConflictFunc(fn func(a, b Task) bool) Option {
+	return func(o *scheduleOptions) {
+		o.conflictFunc = fn
+	}
+}
+
+// WithObjective changes what the scheduler optimises for, the default is MaxPriority
+func WithObjective(mode ObjectiveMode) Option {
+	return func(o *scheduleOptions) {
+		o.objective = mode
+	}
+}
+
+// WithTieBreak picks between schedules that are equally good under the objective, the default
+// is TieBreakNone. It's carried through the DP as part of each partial schedule's value, so
+// the schedule returned is the best under the tie-break among every optimal one.
+func WithTieBreak(tieBreak TieBreak) Option {
+	return func(o *scheduleOptions) {
+		o.tieBreak = tieBreak
+	}
+}
+
+// WithParallel solves clusters of tasks that can't affect each other (nothing in one overlaps
+// anything in another) concurrently, using up to GOMAXPROCS goroutines. The result is exactly
+// the same as without it, it only helps when the input spreads out into many clusters.
+func WithParallel() Option {
+	return func(o *scheduleOptions) {
+		o.parallel = true
+	}
+}
+
+// WithMaxTasks limits the schedule to at most n tasks, choosing the n (or fewer) that give the
+// highest total priority without conflicting, e.g. when every task costs an uplinked command
+// slot. Mandatory tasks count towards n, if there are more of them than n the schedule is
+// infeasible. A negative n is treated as zero.
+//
+// The cap ties every task to every other one, so clusters and resources are no longer solved
+// independently and the DP grows to track how many tasks have been used, costing roughly n
+// times as much.
+func WithMaxTasks(n int) Option {
+	return func(o *scheduleOptions) {
+		o.limitTasks = true
+		o.maxTasks = max(n, 0)
+	}
+}
+
+// WithPlacementStep sets how finely FindBestScheduleFlex tries start times for a FlexTask, it
+// tries every step from EarliestStart plus LatestStart itself. Smaller steps find tighter
+// packings but every start is another task for the DP. The default is DefaultPlacementStep.
+func WithPlacementStep(step time.Duration) Option {
+	return func(o *scheduleOptions) {
+		o.placementStep = step
+	}
+}
+
+// WithExclusiveGroups chooses at most one task from each group of tasks sharing a GroupID,
+// even when they don't overlap, e.g. for redundant downlink opportunities where one is enough.
+// The others are rejected with RejectionReasonGroupExclusive.
+//
+// Like WithMaxTasks this ties tasks together however far apart they are, and the DP tracks
+// which groups have been used so it doubles in size with every group of more than one task.
+// At most 10 such groups are supported.
+func WithExclusiveGroups() Option {
+	return func(o *scheduleOptions) {
+		o.exclusiveGroups = true
+	}
+}
+
+// WithTrace fills in trace with the DP table of every timeline the interval DP solves, for
+// explaining a schedule after the fact. It's off by default since it copies every table.
+// With WithParallel the timelines can be added in any order. WithMaxTasks and
+// WithExclusiveGroups use a different solver and add nothing, and with bundles every
+// combination tried adds its own tables.
+func WithTrace(trace *ScheduleTrace) Option {
+	return func(o *scheduleOptions) {
+		o.trace = trace
+	}
+}
+
+// WithFixedPointPriorities compares schedules on priorities rounded to multiples of 1/scale
+// and summed as int64s, instead of on float64 sums. Float sums depend on the order they're
+// added in (0.1+0.2+0.3 isn't 0.3+0.2+0.1), so two schedules that should tie can come out a
+// rounding error apart and which one wins then depends on how the tasks happen to line up.
+// With a scale of 1000 priorities keep three decimal places and ties are exact. The returned
+// total is still the float64 sum of the chosen priorities. A scale that isn't positive turns
+// it off.
+func WithFixedPointPriorities(scale float64) Option {
+	return func(o *scheduleOptions) {
+		o.priorityScale = scale
+	}
+}
+
+// WithInstantaneousCoexist lets zero duration tasks, e.g. a command send at 12:00, be chosen
+// alongside the regular tasks they sit inside (or touch) instead of conflicting with them.
+// Instants still conflict with other instants at the same time, or closer than WithMinGap,
+// on the same resource. It has no effect with WithConflictFunc, and FindBestScheduleMulti
+// refuses it with more than one resource.
+func WithInstantaneousCoexist() Option {
+	return func(o *scheduleOptions) {
+		o.instantaneousCoexist = true
+	}
+}
+
+// OutputOrder is the order FindBestSchedule returns the chosen tasks in
+type OutputOrder int
+
+const (
+	// Chronological returns the chosen tasks by start time
+	Chronological OutputOrder = iota
+	// PriorityDesc returns the highest priority chosen task first, ties stay chronological
+	PriorityDesc
+)
+
+// WithOutputOrder changes the order FindBestSchedule returns the chosen tasks in, e.g.
+// PriorityDesc for a dispatch queue. Only the returned slice is reordered, the schedule
+// itself is the same. The default is Chronological.
+func WithOutputOrder(order OutputOrder) Option {
+	return func(o *scheduleOptions) {
+		o.outputOrder = order
+	}
+}
+
+// WithSoftConflict lets conflicting tasks both be chosen at a cost, e.g. for antenna contention
+// that degrades a pass rather than blocking it. penalty is asked about every pair of tasks
+// that conflict (under the usual rules, WithConflictFunc included) and whatever it returns is
+// taken off the total if both are chosen. Returning math.Inf(1) keeps the pair a hard
+// conflict, negative penalties count as zero. The returned total priority has the penalties
+// taken off, and ValidateSchedule still checks the hard rules so it will object to the
+// overlaps.
+//
+// With pairwise penalties the problem is no longer interval scheduling, it's NP-hard in
+// general. Clusters of tasks that overlap each other (see WithParallel) are still solved
+// separately, exactly if they have at most 20 tasks and otherwise with a heuristic that's
+// never worse than treating every conflict as hard. It can't be combined with WithMaxTasks,
+// WithExclusiveGroups, bundles or dependencies.
+func WithSoftConflict(penalty func(a, b Task) float64) Option {
+	return func(o *scheduleOptions) {
+		o.softConflict = penalty
+	}
+}
+
+// WithPriorityMapping sets the numeric priority of each PriorityLevel, for tasks with a Level.
+// Levels missing from mapping keep their DefaultPriority. The mapping is copied, so changing it
+// afterwards doesn't affect the option.
+func WithPriorityMapping(mapping map[PriorityLevel]float64) Option {
+	copied := make(map[PriorityLevel]float64, len(mapping))
+	for level, priority := range mapping {
+		copied[level] = priority
+	}
+	return func(o *scheduleOptions) {
+		o.priorityMapping = copied
+	}
+}
+
+// WithEffectivePriority optimises on fn(task) instead of the task's Priority, e.g.
+// Priority * Quality to favour passes with a better link. fn sees the task with Priority
+// already resolved from PriorityFunc or Level, and must return a finite value or the task is
+// invalid. Everything downstream works on the effective value: the DP, the returned total,
+// the Priority of every returned task and the rejection reasons, so a task rejected as
+// RejectionReasonLowPriority was worth too little after weighting even if its raw Priority
+// was high. Without it the priority is used as it is.
+func WithEffectivePriority(fn func(Task) float64) Option {
+	return func(o *scheduleOptions) {
+		o.effectivePriority = fn
+	}
+}
+
+// WithWindow only schedules tasks that lie inside the planning window from start to end, e.g.
+// an operator's shift, so stray tasks from a bad upload can't end up outside it. A task
+// sticking out of the window at either end, or not in it at all, is rejected with
+// RejectionReasonOutsideWindow (a mandatory one makes the schedule infeasible). Touching an
+// edge is inside, a zero duration task is inside if it's at or between start and end.
+func WithWindow(start, end time.Time) Option {
+	return func(o *scheduleOptions) {
+		o.window = true
+		o.windowStart = start
+		o.windowEnd = end
+	}
+}
+
+// WithClampToWindow changes WithWindow to cut a task that's partly outside the window down
+// to the part inside it, rather than rejecting it. The task keeps its priority and comes back
+// with its clamped times. Tasks that don't overlap the window at all are still rejected.
+func WithClampToWindow() Option {
+	return func(o *scheduleOptions) {
+		o.clampToWindow = true
+	}
+}
+
+// WithReconstructionCheck makes FindBestSchedule check that the tasks it returns add up to the
+// total the solver found, returning an ErrTotalMismatch if they don't, as a guard against bugs
+// in rebuilding the schedule from the DP. The totals are always logged at debug level, this
+// turns a mismatch into an error. It's skipped with WithSoftConflict, where the total has the
+// penalties taken off.
+func WithReconstructionCheck() Option {
+	return func(o *scheduleOptions) {
+		o.reconstructionCheck = true
+	}
+}
+
+// WithSnap rounds every task's start and end to the nearest multiple of d (e.g. time.Second or
+// time.Minute) before scheduling, so sub-d jitter in upstream timestamps doesn't make
+// back-to-back tasks overlap. Deadlines and NotBefore times aren't snapped. The caller's tasks
+// are left alone, the tasks returned (chosen and rejected) carry the snapped times. A task
+// shorter than d can snap to zero duration, it's then scheduled as an instant at its snapped
+// start like any other zero duration task, see WithInstantaneousCoexist. Rounding keeps times
+// in order, so no task ends up ending before it starts. d of zero or less turns it off.
+func WithSnap(d time.Duration) Option {
+	return func(o *scheduleOptions) {
+		o.snap = d
+	}
+}
+
+// WithPreSorted tells the scheduler the tasks already come in the order its DP visits them,
+// so it can skip sorting them. The order is by the end of the interval each task occupies
+// (EndTime plus TeardownAfter, or for a zero duration task its StartTime), with a regular task
+// before a zero duration one when they tie. Ties within that are broken by start time and
+// then highest priority first when the scheduler sorts itself, input in that exact order gets
+// exactly the schedule it would without the option, otherwise an equally good one. Tasks on
+// different resources can be interleaved freely.
+//
+// The order isn't checked unless the package is built with the scheduler_debug tag, where a
+// wrongly sorted input panics. Without it the schedule is silently wrong, so only use this
+// when the input really is sorted. It has no effect with WithSnap or WithClampToWindow, which
+// can move tasks after the caller sorted them.
+func WithPreSorted() Option {
+	return func(o *scheduleOptions) {
+		o.preSorted = true
+	}
+}
+
+// WithBaggageKeys picks which members of the context's OpenTelemetry baggage
+// FindBestScheduleContext copies onto its span and log fields, in place of the default
+// mission_id and plan_id. Passing no keys copies nothing.
+func WithBaggageKeys(keys ...string) Option {
+	return func(o *scheduleOptions) {
+		o.baggageKeys = append([]string{}, keys...)
+	}
+}
+
+// WithStrictTies makes FindBestSchedule return an ErrAmbiguousTie instead of scheduling when two
+// tasks that conflict finish at the same instant. The scheduler always breaks such ties the
+// same way (see WithPreSorted for the order), but which of the two wins then comes down to
+// start time, priority or input order rather than anything the caller decided, so pipelines
+// that can't accept that can insist on disambiguating the input first.
+func WithStrictTies() Option {
+	return func(o *scheduleOptions) {
+		o.strictTies = true
+	}
+}
+
+// OverlapPolicy is what FindBestSchedule does with tasks that overlap
+type OverlapPolicy int
+
+const (
+	// Exclude never chooses two tasks that conflict, the usual interval scheduling
+	Exclude OverlapPolicy = iota
+	// ProRate lets overlapping tasks all be chosen for part of their priority. It's
+	// experimental, see WithOverlapPolicy.
+	ProRate
+)
+
+// WithOverlapPolicy changes what FindBestSchedule does with tasks that overlap. The default
+// is Exclude.
+//
+// ProRate is experimental and may change or go away. Under it tasks on the same resource
+// may overlap, and each chosen task earns its priority times the fraction of its StartTime
+// to EndTime that no other chosen task covers: a task half covered by others earns half its
+// priority, one nested entirely inside another earns nothing. A zero duration task earns all
+// of its priority or none of it, none if it sits strictly inside another chosen task (unless
+// WithInstantaneousCoexist is set) or on the same instant as another chosen zero duration
+// task. Setup and teardown are ignored. The schedule with the best total of those credits is
+// chosen, mandatory tasks always among them, and the chosen tasks come back with Priority set
+// to the credit they earned so they add up to the total. Tasks left out are rejected as
+// LOW_PRIORITY, never as conflicts, and ValidateSchedule will object to the overlaps.
+//
+// Like WithSoftConflict this is no longer interval scheduling, clusters of overlapping tasks
+// are solved exactly if they have at most 16 tasks and otherwise with a heuristic that's
+// never worse than Exclude. It can't be combined with WithSoftConflict, WithConflictFunc,
+// WithMinGap, WithMaxTasks, WithExclusiveGroups, bundles or dependencies, and
+// FindBestScheduleCapacity, FindBestScheduleMulti and ScheduleStream refuse it.
+func WithOverlapPolicy(policy OverlapPolicy) Option {
+	return func(o *scheduleOptions) {
+		o.overlapPolicy = policy
+	}
+}
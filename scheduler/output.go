@@ -0,0 +1,120 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+)
+
+// NewTaskOutput converts a task into its JSON output form, times are written in whatever
+// location the task's times carry
+func NewTaskOutput(task Task) TaskOutput {
+	return TaskOutput{
+		ID:             task.ID,
+		StartTime:      task.StartTime.Format(time.RFC3339),
+		EndTime:        task.EndTime.Format(time.RFC3339),
+		Priority:       task.Priority,
+		DurationMins:   int(task.EndTime.Sub(task.StartTime).Minutes()),
+		IsZeroDuration: !task.EndTime.After(task.StartTime),
+		ResourceID:     task.ResourceID,
+		Tags:           task.Tags,
+	}
+}
+
+// NewScheduleOutput converts the result of scheduling tasks into its JSON output form, the time
+// range spans every input task and utilization is measured over it
+func NewScheduleOutput(tasks, chosen []Task, totalPriority float64, rejected []RejectedTask) ScheduleOutput {
+	output := ScheduleOutput{
+		ChosenTasks:   make([]TaskOutput, len(chosen)),
+		RejectedTasks: make([]TaskOutput, len(rejected)),
+		TotalPriority: totalPriority,
+	}
+	for i, task := range chosen {
+		output.ChosenTasks[i] = NewTaskOutput(task)
+	}
+	for i, task := range rejected {
+		output.RejectedTasks[i] = NewRejectedTaskOutput(task)
+	}
+	var start, end time.Time
+	for i, task := range tasks {
+		if i == 0 || task.StartTime.Before(start) {
+			start = task.StartTime
+		}
+		if i == 0 || task.EndTime.After(end) {
+			end = task.EndTime
+		}
+	}
+	if len(tasks) > 0 {
+		output.TimeRange = newTimeRange(start, end)
+	}
+	output.Statistics = ComputeStatistics(chosen, output.TimeRange)
+	output.Statistics.TotalTasks = len(tasks)
+	output.Statistics.RejectedTasks = len(rejected)
+	if byTag := StatisticsByTag(chosen, rejected); len(byTag) > 0 {
+		output.Statistics.ByTag = byTag
+	}
+	return output
+}
+
+// FormatSchedule converts a schedule into its JSON output form with every time written in loc
+// (UTC if it's nil), for showing operators their local time. Only the output changes, times
+// keep their offset so they still parse back to the same instants, and each one gets the
+// offset in effect at that instant so a schedule crossing a DST change renders correctly.
+// TimeRange spans the chosen tasks.
+func FormatSchedule(chosen []Task, loc *time.Location) ScheduleOutput {
+	if loc == nil {
+		loc = time.UTC
+	}
+	output := ScheduleOutput{
+		ChosenTasks: make([]TaskOutput, len(chosen)),
+	}
+	var start, end time.Time
+	for i, task := range chosen {
+		task.StartTime, task.EndTime = task.StartTime.In(loc), task.EndTime.In(loc)
+		output.ChosenTasks[i] = NewTaskOutput(task)
+		output.TotalPriority += task.Priority
+		if i == 0 || task.StartTime.Before(start) {
+			start = task.StartTime
+		}
+		if i == 0 || task.EndTime.After(end) {
+			end = task.EndTime
+		}
+	}
+	if len(chosen) > 0 {
+		output.TimeRange = newTimeRange(start, end)
+	}
+	output.Statistics = ComputeStatistics(chosen, output.TimeRange)
+	if byTag := StatisticsByTag(chosen, nil); len(byTag) > 0 {
+		output.Statistics.ByTag = byTag
+	}
+	return output
+}
+
+// NewRejectedTaskOutput converts a rejection into its JSON output form, keeping why the
+// task was dropped and which chosen task (if any) pushed it out
+func NewRejectedTaskOutput(rejected RejectedTask) TaskOutput {
+	output := NewTaskOutput(rejected.TaskRejected)
+	output.RejectionReason = rejected.Reason
+	output.CausedByID = rejected.CausedByID
+	return output
+}
+
+// ToTask parses a task back out of its JSON output form so a saved schedule can be fed to
+// the scheduler again. Fields only the output has, like the rejection reason, are dropped.
+func (o TaskOutput) ToTask() (Task, error) {
+	startTime, err := time.Parse(time.RFC3339, o.StartTime)
+	if err != nil {
+		return Task{}, fmt.Errorf("invalid start_time for task %q: %w", o.ID, err)
+	}
+	endTime, err := time.Parse(time.RFC3339, o.EndTime)
+	if err != nil {
+		return Task{}, fmt.Errorf("invalid end_time for task %q: %w", o.ID, err)
+	}
+	return Task{
+		ID:         o.ID,
+		StartTime:  startTime,
+		EndTime:    endTime,
+		Priority:   o.Priority,
+		ResourceID: o.ResourceID,
+		Tags:       o.Tags,
+	}, nil
+}
@@ -0,0 +1,144 @@
+package scheduler
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestNewRejectedTaskOutput(t *testing.T) {
+	chosen := Task{ID: "chosen", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 7}
+	rejected := RejectedTask{
+		TaskRejected: Task{StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 4},
+		CausedByID:   chosen.ID,
+		Reason:       RejectionReasonConflict,
+	}
+
+	output := NewRejectedTaskOutput(rejected)
+	if output.RejectionReason != RejectionReasonConflict {
+		t.Errorf("Expected reason %s, got %s", RejectionReasonConflict, output.RejectionReason)
+	}
+	if output.DurationMins != 120 {
+		t.Errorf("Expected 120 minutes, got %d", output.DurationMins)
+	}
+	if output.CausedByID != "chosen" {
+		t.Errorf("Expected caused by the chosen task, got %q", output.CausedByID)
+	}
+
+	lowPriority := NewRejectedTaskOutput(RejectedTask{TaskRejected: chosen, Reason: RejectionReasonLowPriority})
+	if lowPriority.CausedByID != "" {
+		t.Errorf("Expected no caused by for a low priority rejection, got %q", lowPriority.CausedByID)
+	}
+}
+
+func TestTaskOutputRoundTrip(t *testing.T) {
+	tasks := []Task{
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 7},
+		{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 4, ResourceID: "antenna-1"},
+		{ID: "c", StartTime: fixedTime(12), EndTime: fixedTime(12), Priority: 2.5},
+	}
+	chosen, totalPriority, rejected, err := newTestScheduler().FindBestSchedule(tasks)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	output := ScheduleOutput{TotalPriority: totalPriority}
+	for _, task := range chosen {
+		output.ChosenTasks = append(output.ChosenTasks, NewTaskOutput(task))
+	}
+	for _, task := range rejected {
+		output.RejectedTasks = append(output.RejectedTasks, NewRejectedTaskOutput(task))
+	}
+	data, err := json.Marshal(output)
+	if err != nil {
+		t.Fatalf("Failed to marshal output: %v", err)
+	}
+
+	loaded, err := LoadScheduleOutput(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Unexpected error loading output: %v", err)
+	}
+	reloaded := make([]Task, 0, len(tasks))
+	for _, taskOutput := range append(loaded.ChosenTasks, loaded.RejectedTasks...) {
+		task, err := taskOutput.ToTask()
+		if err != nil {
+			t.Fatalf("Unexpected error converting %s: %v", taskOutput.ID, err)
+		}
+		reloaded = append(reloaded, task)
+	}
+	if len(reloaded) != len(tasks) {
+		t.Fatalf("Expected %d tasks back, got %d", len(tasks), len(reloaded))
+	}
+
+	// Re-running on the loaded tasks gives the same schedule
+	_, reloadedPriority, _, err := newTestScheduler().FindBestSchedule(reloaded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if reloadedPriority != totalPriority {
+		t.Errorf("Expected priority %.2f after reloading, got %.2f", totalPriority, reloadedPriority)
+	}
+	for _, task := range reloaded {
+		if task.ID == "b" && task.ResourceID != "antenna-1" {
+			t.Errorf("Expected resource antenna-1 to survive the round trip, got %q", task.ResourceID)
+		}
+	}
+}
+
+func TestTaskOutputToTaskInvalid(t *testing.T) {
+	if _, err := (TaskOutput{ID: "a", StartTime: "9am", EndTime: "2024-01-01T10:00:00Z"}).ToTask(); err == nil {
+		t.Error("Expected an error for an invalid start time")
+	}
+	if _, err := LoadScheduleOutput(strings.NewReader("{")); err == nil {
+		t.Error("Expected an error for truncated JSON")
+	}
+}
+
+func TestFormatSchedule(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("Failed to load location: %v", err)
+	}
+	// Clocks in New York went forward at 2am local (7am UTC) on 10 March 2024
+	dstDay := func(hour int) time.Time {
+		return time.Date(2024, 3, 10, hour, 0, 0, 0, time.UTC)
+	}
+	chosen := []Task{
+		{ID: "before", StartTime: dstDay(5), EndTime: dstDay(6), Priority: 2},
+		{ID: "across", StartTime: dstDay(6), EndTime: dstDay(8), Priority: 3},
+	}
+
+	output := FormatSchedule(chosen, newYork)
+	expected := [][2]string{
+		{"2024-03-10T00:00:00-05:00", "2024-03-10T01:00:00-05:00"},
+		{"2024-03-10T01:00:00-05:00", "2024-03-10T04:00:00-04:00"},
+	}
+	for i, taskOutput := range output.ChosenTasks {
+		if taskOutput.StartTime != expected[i][0] || taskOutput.EndTime != expected[i][1] {
+			t.Errorf("Expected %s to run %s to %s, got %s to %s", taskOutput.ID, expected[i][0], expected[i][1], taskOutput.StartTime, taskOutput.EndTime)
+		}
+		// The two hours across the change are still two hours
+		if task, err := taskOutput.ToTask(); err != nil || !task.StartTime.Equal(chosen[i].StartTime) || task.EndTime.Sub(task.StartTime) != chosen[i].EndTime.Sub(chosen[i].StartTime) {
+			t.Errorf("Expected %s to parse back to the same instants, got %+v (%v)", taskOutput.ID, task, err)
+		}
+	}
+	if output.ChosenTasks[1].DurationMins != 120 {
+		t.Errorf("Expected 120 minutes across the change, got %d", output.ChosenTasks[1].DurationMins)
+	}
+	if output.TotalPriority != 5 || output.Statistics.ScheduledTasks != 2 || output.Statistics.UtilizationRatio != 1 {
+		t.Errorf("Unexpected totals %+v", output)
+	}
+	if output.TimeRange.Start != expected[0][0] || output.TimeRange.End != expected[1][1] {
+		t.Errorf("Expected the time range in New York time, got %+v", output.TimeRange)
+	}
+	// The caller's tasks stay in UTC
+	if chosen[0].StartTime.Location() != time.UTC {
+		t.Error("Expected the input tasks to be left alone")
+	}
+
+	if utc := FormatSchedule(chosen, nil); utc.ChosenTasks[0].StartTime != "2024-03-10T05:00:00Z" {
+		t.Errorf("Expected UTC for a nil location, got %s", utc.ChosenTasks[0].StartTime)
+	}
+}
@@ -0,0 +1,116 @@
+package scheduler
+
+import (
+	"context"
+	"sort"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// FindBestScheduleWithPinned finds the best schedule that includes every pinned task, for an
+// "approve then re-optimize" workflow where an operator has already signed off on part of the
+// plan. Unlike Mandatory, pins never make the schedule infeasible: they're taken as given,
+// even if they overlap each other, and any task in tasks that conflicts with one (or shares
+// its group under WithExclusiveGroups) is rejected with it as CausedByID, mandatory or not,
+// along with the rest of its bundle. The remaining tasks are scheduled with FindBestSchedule
+// around the pins, a task also passed as a pin (by ID) is just the pin.
+//
+// It returns the pins and the chosen tasks together in the order WithOutputOrder asks for,
+// the total priority of both and the rejections. Under WithMaxTasks the pins count towards
+// the cap. Invalid tasks or pins are refused with an error, as is anything FindBestSchedule
+// can't schedule around the pins, e.g. a dependency cycle or mandatory tasks that conflict
+// with each other.
+func (s *Scheduler) FindBestScheduleWithPinned(tasks []Task, pinned []Task, opts ...Option) ([]Task, float64, []RejectedTask, error) {
+	s = s.withOptions(opts)
+	ctx, span := s.tracer().Start(context.Background(), "FindBestScheduleWithPinned")
+	defer span.End()
+	logger := s.logger.Ctx(ctx)
+	span.SetAttributes(attribute.Int("num_tasks", len(tasks)), attribute.Int("num_pinned_tasks", len(pinned)))
+
+	// Pins go straight into the total, so they need checking as much as the tasks do
+	for _, input := range [][]Task{pinned, tasks} {
+		if err := s.validateTasks(input); err != nil {
+			span.RecordError(err)
+			logger.Warn("Invalid task passed to pinned scheduler", zap.Error(err))
+			return nil, 0, nil, err
+		}
+	}
+	pinned = append([]Task(nil), pinned...)
+	s.assignMissingIDs(pinned)
+	s.resolvePriorities(pinned)
+	pinnedIDs := make(map[string]bool, len(pinned))
+	for _, pin := range pinned {
+		pinnedIDs[pin.ID] = true
+	}
+	tasks = append([]Task(nil), tasks...)
+	s.assignMissingIDs(tasks)
+	s.resolvePriorities(tasks)
+
+	// Anything a pin blocks is out, and takes the rest of its bundle with it
+	rejectedTasks := []RejectedTask{}
+	blockedBundles := make(map[string]string)
+	free := make([]Task, 0, len(tasks))
+	for _, task := range tasks {
+		if pinnedIDs[task.ID] {
+			continue
+		}
+		if rejected, blocked := s.pinConflict(task, pinned); blocked {
+			rejectedTasks = append(rejectedTasks, rejected)
+			if task.BundleID != "" {
+				blockedBundles[task.BundleID] = rejected.CausedByID
+			}
+			continue
+		}
+		free = append(free, task)
+	}
+	remaining := make([]Task, 0, len(free))
+	for _, task := range free {
+		if causedBy, blocked := blockedBundles[task.BundleID]; blocked && task.BundleID != "" {
+			rejectedTasks = append(rejectedTasks, RejectedTask{TaskRejected: task, CausedByID: causedBy, Reason: RejectionReasonBundleInfeasible})
+			continue
+		}
+		remaining = append(remaining, task)
+	}
+	span.SetAttributes(attribute.Int("num_pin_rejected_tasks", len(rejectedTasks)))
+
+	// The pins already use up part of the cap, options apply in order so this one wins
+	if s.options.limitTasks {
+		opts = append(append([]Option(nil), opts...), WithMaxTasks(s.options.maxTasks-len(pinned)))
+	}
+	chosen, totalPriority, rejected, err := s.FindBestScheduleContext(ctx, remaining, opts...)
+	if err != nil {
+		span.RecordError(err)
+		logger.Warn("Could not schedule around pinned tasks", zap.Error(err))
+		return nil, 0, nil, err
+	}
+	rejectedTasks = append(rejectedTasks, rejected...)
+
+	chosenTasks := append(pinned, chosen...)
+	for _, pin := range pinned {
+		totalPriority += pin.Priority
+	}
+	sort.SliceStable(chosenTasks, func(first, second int) bool {
+		return chosenTasks[first].StartTime.Before(chosenTasks[second].StartTime)
+	})
+	if s.options.outputOrder == PriorityDesc {
+		sort.SliceStable(chosenTasks, func(first, second int) bool {
+			return chosenTasks[first].Priority > chosenTasks[second].Priority
+		})
+	}
+	logger.Info("Scheduled around pinned tasks", zap.Int("num_chosen_tasks", len(chosenTasks)), zap.Int("num_rejected_tasks", len(rejectedTasks)))
+	return chosenTasks, totalPriority, rejectedTasks, nil
+}
+
+// pinConflict checks task against every pin, returning its rejection if one blocks it
+func (s *Scheduler) pinConflict(task Task, pinned []Task) (RejectedTask, bool) {
+	for _, pin := range pinned {
+		if s.tasksConflict(task, pin) {
+			return RejectedTask{TaskRejected: task, CausedByID: pin.ID, Reason: RejectionReasonConflict}, true
+		}
+		if s.groupConflict(task, pin) {
+			return RejectedTask{TaskRejected: task, CausedByID: pin.ID, Reason: RejectionReasonGroupExclusive}, true
+		}
+	}
+	return RejectedTask{}, false
+}
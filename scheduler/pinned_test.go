@@ -0,0 +1,137 @@
+package scheduler
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFindBestScheduleWithPinned(t *testing.T) {
+	big := Task{ID: "big", StartTime: fixedTime(9), EndTime: fixedTime(12), Priority: 10}
+	small := Task{ID: "small", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 2}
+	later := Task{ID: "later", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 3}
+	evening := Task{ID: "evening", StartTime: fixedTime(18), EndTime: fixedTime(19), Priority: 1}
+	forced := Task{ID: "forced", StartTime: fixedTime(11), EndTime: fixedTime(13), Priority: 1, Mandatory: true}
+	uplink := Task{ID: "uplink", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 4, BundleID: "pair"}
+	downlink := Task{ID: "downlink", StartTime: fixedTime(15), EndTime: fixedTime(16), Priority: 4, BundleID: "pair"}
+	tests := []struct {
+		name             string
+		tasks            []Task
+		pinned           []Task
+		opts             []Option
+		expectedChosen   []string
+		expectedPriority float64
+		expectedRejected map[string]RejectionReason
+	}{
+		{
+			name:             "Pin beats a better task",
+			tasks:            []Task{big, small, later, evening},
+			pinned:           []Task{small},
+			expectedChosen:   []string{"small", "later", "evening"},
+			expectedPriority: 6,
+			expectedRejected: map[string]RejectionReason{"big": RejectionReasonConflict},
+		},
+		{
+			name:             "Pins may overlap each other",
+			tasks:            []Task{evening},
+			pinned:           []Task{big, small},
+			expectedChosen:   []string{"big", "small", "evening"},
+			expectedPriority: 13,
+			expectedRejected: map[string]RejectionReason{},
+		},
+		{
+			name:             "Mandatory task loses to a pin",
+			tasks:            []Task{forced, evening},
+			pinned:           []Task{big},
+			expectedChosen:   []string{"big", "evening"},
+			expectedPriority: 11,
+			expectedRejected: map[string]RejectionReason{"forced": RejectionReasonConflict},
+		},
+		{
+			name:             "Blocked task takes its bundle",
+			tasks:            []Task{uplink, downlink, evening},
+			pinned:           []Task{small},
+			expectedChosen:   []string{"small", "evening"},
+			expectedPriority: 3,
+			expectedRejected: map[string]RejectionReason{"uplink": RejectionReasonConflict, "downlink": RejectionReasonBundleInfeasible},
+		},
+		{
+			name:             "Pins count towards the cap",
+			tasks:            []Task{later, evening},
+			pinned:           []Task{small},
+			opts:             []Option{WithMaxTasks(2)},
+			expectedChosen:   []string{"small", "later"},
+			expectedPriority: 5,
+			expectedRejected: map[string]RejectionReason{"evening": RejectionReasonCapExceeded},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chosen, totalPriority, rejected, err := newTestScheduler().FindBestScheduleWithPinned(tt.tasks, tt.pinned, tt.opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if totalPriority != tt.expectedPriority {
+				t.Errorf("Expected total priority %v, got %v", tt.expectedPriority, totalPriority)
+			}
+			if len(chosen) != len(tt.expectedChosen) {
+				t.Fatalf("Expected %v chosen, got %+v", tt.expectedChosen, chosen)
+			}
+			for i, id := range tt.expectedChosen {
+				if chosen[i].ID != id {
+					t.Errorf("Expected task %d to be %s, got %s", i, id, chosen[i].ID)
+				}
+			}
+			if len(rejected) != len(tt.expectedRejected) {
+				t.Fatalf("Expected rejections %v, got %+v", tt.expectedRejected, rejected)
+			}
+			for _, rejection := range rejected {
+				if reason := tt.expectedRejected[rejection.TaskRejected.ID]; rejection.Reason != reason {
+					t.Errorf("Expected %s to be rejected with %s, got %s", rejection.TaskRejected.ID, reason, rejection.Reason)
+				}
+			}
+		})
+	}
+}
+
+func TestFindBestScheduleWithPinnedErrors(t *testing.T) {
+	pin := Task{ID: "pin", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1}
+	tests := []struct {
+		name   string
+		tasks  []Task
+		pinned []Task
+	}{
+		{
+			name: "Conflicting mandatory tasks",
+			tasks: []Task{
+				{ID: "first", StartTime: fixedTime(12), EndTime: fixedTime(14), Priority: 1, Mandatory: true},
+				{ID: "second", StartTime: fixedTime(13), EndTime: fixedTime(15), Priority: 1, Mandatory: true},
+			},
+			pinned: []Task{pin},
+		},
+		{
+			name: "Dependency cycle",
+			tasks: []Task{
+				{ID: "first", StartTime: fixedTime(12), EndTime: fixedTime(13), Priority: 1, DependsOn: []string{"second"}},
+				{ID: "second", StartTime: fixedTime(14), EndTime: fixedTime(15), Priority: 1, DependsOn: []string{"first"}},
+			},
+			pinned: []Task{pin},
+		},
+		{
+			name:   "Pin without times",
+			pinned: []Task{{ID: "pin", Priority: 1}},
+		},
+		{
+			name:   "Pin with a NaN priority",
+			pinned: []Task{{ID: "pin", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: math.NaN()}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chosen, totalPriority, rejected, err := newTestScheduler().FindBestScheduleWithPinned(tt.tasks, tt.pinned)
+			if err == nil {
+				t.Errorf("Expected an error, got %+v (%v) and rejections %+v", chosen, totalPriority, rejected)
+			}
+		})
+	}
+}
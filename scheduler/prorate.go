@@ -0,0 +1,302 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+	"turionspace/nei-mission-planner/scheduler/scheduler/core"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxProRateExact is the largest cluster ProRate searches exhaustively, bigger ones only get
+// the heuristic
+const maxProRateExact = 16
+
+// proRateCluster is a cluster of tasks with the overlaps between them worked out for ProRate
+type proRateCluster struct {
+	tasks []Task
+	// instant marks the zero duration tasks, they earn all or nothing
+	instant []bool
+	// overlaps lists, for each task, the others that take credit from it if both are chosen
+	// (or that it takes credit from)
+	overlaps [][]int
+}
+
+// newProRateCluster works out which pairs of tasks overlap under ProRate
+func (s *Scheduler) newProRateCluster(tasks []Task) proRateCluster {
+	cluster := proRateCluster{tasks: tasks, instant: make([]bool, len(tasks)), overlaps: make([][]int, len(tasks))}
+	for i, task := range tasks {
+		cluster.instant[i] = s.isZeroDuration(task)
+	}
+	for i := range tasks {
+		for j := i + 1; j < len(tasks); j++ {
+			if s.proRateOverlap(tasks[i], tasks[j], cluster.instant[i], cluster.instant[j]) {
+				cluster.overlaps[i] = append(cluster.overlaps[i], j)
+				cluster.overlaps[j] = append(cluster.overlaps[j], i)
+			}
+		}
+	}
+	return cluster
+}
+
+// proRateOverlap checks if two tasks share any time under ProRate: regular tasks that overlap
+// by more than an instant, a zero duration task strictly inside a regular one, or two zero
+// duration tasks on the same instant. Touching tasks don't overlap.
+func (s *Scheduler) proRateOverlap(task1, task2 Task, instant1, instant2 bool) bool {
+	if task1.ResourceID != task2.ResourceID {
+		return false
+	}
+	switch {
+	case instant1 && instant2:
+		return task1.StartTime.Equal(task2.StartTime)
+	case instant1 || instant2:
+		if s.options.instantaneousCoexist {
+			return false
+		}
+		if instant2 {
+			task1, task2 = task2, task1
+		}
+		return task1.StartTime.After(task2.StartTime) && task1.StartTime.Before(task2.EndTime)
+	default:
+		return task1.StartTime.Before(task2.EndTime) && task2.StartTime.Before(task1.EndTime)
+	}
+}
+
+// credit is what task i earns alongside the chosen tasks: its priority times the fraction of
+// its time none of them cover, for a zero duration task all or nothing. Zero duration tasks
+// never cover any of a regular task's time.
+func (c proRateCluster) credit(i int, chosen []bool) float64 {
+	task := c.tasks[i]
+	if c.instant[i] {
+		for _, j := range c.overlaps[i] {
+			if chosen[j] {
+				return 0
+			}
+		}
+		return task.Priority
+	}
+
+	covered := make([]core.Interval, 0)
+	for _, j := range c.overlaps[i] {
+		if !chosen[j] || c.instant[j] {
+			continue
+		}
+		other := c.tasks[j]
+		covered = append(covered, core.Interval{
+			Start: latest(task.StartTime, other.StartTime),
+			End:   earliest(task.EndTime, other.EndTime),
+		})
+	}
+	if len(covered) == 0 {
+		return task.Priority
+	}
+	// Merge the covered stretches so time covered by several tasks only counts once
+	sort.Slice(covered, func(first, second int) bool {
+		return covered[first].Start.Before(covered[second].Start)
+	})
+	var overlapped time.Duration
+	current := covered[0]
+	for _, next := range covered[1:] {
+		if next.Start.After(current.End) {
+			overlapped += current.End.Sub(current.Start)
+			current = next
+			continue
+		}
+		current.End = latest(current.End, next.End)
+	}
+	overlapped += current.End.Sub(current.Start)
+	duration := task.EndTime.Sub(task.StartTime)
+	return task.Priority * float64(duration-overlapped) / float64(duration)
+}
+
+// value is the total credit of the chosen tasks
+func (c proRateCluster) value(chosen []bool) float64 {
+	total := 0.0
+	for i := range c.tasks {
+		if chosen[i] {
+			total += c.credit(i, chosen)
+		}
+	}
+	return total
+}
+
+// gain is what choosing task i adds to the chosen tasks' total, its own credit less what it
+// takes from the ones it overlaps
+func (c proRateCluster) gain(i int, chosen []bool) float64 {
+	before := 0.0
+	for _, j := range c.overlaps[i] {
+		if chosen[j] {
+			before += c.credit(j, chosen)
+		}
+	}
+	chosen[i] = true
+	after := c.credit(i, chosen)
+	for _, j := range c.overlaps[i] {
+		if chosen[j] {
+			after += c.credit(j, chosen)
+		}
+	}
+	chosen[i] = false
+	return after - before
+}
+
+// scheduleProRate is schedule for the ProRate overlap policy. Credit only moves between tasks
+// that overlap, so clusters are still independent and each is solved on its own, exactly by
+// trying every subset if it has at most maxProRateExact tasks and with proRateHeuristic
+// otherwise. The chosen tasks come back with Priority set to the credit they earned.
+func (s *Scheduler) scheduleProRate(ctx context.Context, span trace.Span, tasks []Task) ([]Task, float64, []RejectedTask, error) {
+	if s.options.softConflict != nil || s.options.conflictFunc != nil || s.options.minGap != 0 {
+		return nil, 0, nil, errors.New("the ProRate overlap policy can't be combined with WithSoftConflict, WithConflictFunc or WithMinGap")
+	}
+	if s.options.limitTasks || s.options.exclusiveGroups || len(sharedBundles(tasks)) > 0 || hasDependencies(tasks) {
+		return nil, 0, nil, errors.New("the ProRate overlap policy can't be combined with WithMaxTasks, WithExclusiveGroups, bundles or dependencies")
+	}
+	chosenTasks := make([]Task, 0)
+	totalPriority := 0.0
+	rejectedTasks := []RejectedTask{}
+	heuristicClusters := 0
+	for _, clusterTasks := range s.splitIntoClusters(tasks) {
+		if err := checkCancelled(ctx, 0); err != nil {
+			return nil, 0, nil, err
+		}
+		cluster := s.newProRateCluster(clusterTasks)
+		chosen, err := s.proRateHeuristic(ctx, span, cluster)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		if len(clusterTasks) <= maxProRateExact {
+			if err := cluster.search(ctx, chosen); err != nil {
+				return nil, 0, nil, err
+			}
+		} else {
+			heuristicClusters++
+		}
+
+		for i, task := range cluster.tasks {
+			if !chosen[i] {
+				span.AddEvent("task_rejected", trace.WithAttributes(attribute.String("reason", RejectionReasonLowPriority.String())))
+				rejectedTasks = append(rejectedTasks, RejectedTask{TaskRejected: task, Reason: RejectionReasonLowPriority})
+				continue
+			}
+			task.Priority = cluster.credit(i, chosen)
+			chosenTasks = append(chosenTasks, task)
+			totalPriority += task.Priority
+		}
+	}
+	span.SetAttributes(attribute.Int("num_prorate_heuristic_clusters", heuristicClusters))
+	sort.SliceStable(chosenTasks, func(first, second int) bool {
+		return chosenTasks[first].StartTime.Before(chosenTasks[second].StartTime)
+	})
+	return chosenTasks, totalPriority, rejectedTasks, nil
+}
+
+// proRateHeuristic picks a good schedule for a cluster: the mandatory tasks, then the best
+// schedule with no overlaps at all among the tasks they leave room for (the plain interval
+// DP), then greedily whichever task adds the most until none adds anything. Without mandatory
+// tasks it's never worse than Exclude.
+func (s *Scheduler) proRateHeuristic(ctx context.Context, span trace.Span, cluster proRateCluster) ([]bool, error) {
+	chosen := make([]bool, len(cluster.tasks))
+	for i, task := range cluster.tasks {
+		chosen[i] = task.Mandatory
+	}
+
+	// The DP gets copies whose IDs are their index so its choices can be mapped back, the real
+	// IDs may repeat
+	free := make([]Task, 0, len(cluster.tasks))
+	for i, task := range cluster.tasks {
+		if chosen[i] || s.conflictsWithChosen(cluster.tasks, chosen, task) {
+			continue
+		}
+		task.ID = strconv.Itoa(i)
+		free = append(free, task)
+	}
+	if len(free) > 0 {
+		// The DP's schedule is only a starting point, its rejections and tables aren't ProRate's
+		// so it runs on a span that records nothing and without WithTrace
+		seed := *s
+		seed.options.trace = nil
+		freeChosen, _, _, err := seed.scheduleResources(ctx, trace.SpanFromContext(context.Background()), free)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range freeChosen {
+			i, _ := strconv.Atoi(task.ID)
+			chosen[i] = true
+		}
+	}
+
+	for {
+		best, bestGain := -1, 0.0
+		for i := range cluster.tasks {
+			if chosen[i] {
+				continue
+			}
+			if gain := cluster.gain(i, chosen); gain > bestGain {
+				best, bestGain = i, gain
+			}
+		}
+		if best == -1 {
+			return chosen, nil
+		}
+		chosen[best] = true
+	}
+}
+
+// conflictsWithChosen checks if task conflicts, under the usual rules, with any chosen task
+func (s *Scheduler) conflictsWithChosen(tasks []Task, chosen []bool, task Task) bool {
+	for i, other := range tasks {
+		if chosen[i] && s.tasksConflict(other, task) {
+			return true
+		}
+	}
+	return false
+}
+
+// search tries every subset of the cluster that includes the mandatory tasks and updates best
+// in place if one is worth strictly more
+func (c proRateCluster) search(ctx context.Context, best []bool) error {
+	optional := make([]int, 0, len(c.tasks))
+	for i, task := range c.tasks {
+		if !task.Mandatory {
+			optional = append(optional, i)
+		}
+	}
+	bestValue := c.value(best)
+	chosen := make([]bool, len(c.tasks))
+	for subset := 0; subset < 1<<len(optional); subset++ {
+		if err := checkCancelled(ctx, subset); err != nil {
+			return err
+		}
+		for i, task := range c.tasks {
+			chosen[i] = task.Mandatory
+		}
+		for bit, i := range optional {
+			chosen[i] = subset&(1<<bit) != 0
+		}
+		if value := c.value(chosen); value > bestValue {
+			bestValue = value
+			copy(best, chosen)
+		}
+	}
+	return nil
+}
+
+// latest returns the later of two times
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// earliest returns the earlier of two times
+func earliest(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
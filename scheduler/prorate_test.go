@@ -0,0 +1,472 @@
+package scheduler
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Helper function for a time on the half hour, 9.5 is half past nine
+func halfHour(hour float64) time.Time {
+	return fixedTime(0).Add(time.Duration(hour * float64(time.Hour)))
+}
+
+// Helper function to work out ProRate credits one minute at a time: a regular task earns for
+// each of its minutes no other chosen regular task on its resource covers, an instant earns
+// nothing strictly inside another chosen task or on another chosen instant
+func bruteForceCredits(tasks []Task, chosen []bool) []float64 {
+	credits := make([]float64, len(tasks))
+	for i, task := range tasks {
+		if !chosen[i] {
+			continue
+		}
+		duration := task.EndTime.Sub(task.StartTime)
+		if duration == 0 {
+			credits[i] = task.Priority
+			for j, other := range tasks {
+				if j == i || !chosen[j] || other.ResourceID != task.ResourceID {
+					continue
+				}
+				if other.StartTime.Equal(other.EndTime) && other.StartTime.Equal(task.StartTime) ||
+					other.StartTime.Before(task.StartTime) && other.EndTime.After(task.StartTime) {
+					credits[i] = 0
+				}
+			}
+			continue
+		}
+		own := 0
+		for minute := task.StartTime; minute.Before(task.EndTime); minute = minute.Add(time.Minute) {
+			covered := false
+			for j, other := range tasks {
+				if j != i && chosen[j] && other.ResourceID == task.ResourceID && !other.StartTime.After(minute) && other.EndTime.After(minute) {
+					covered = true
+				}
+			}
+			if !covered {
+				own++
+			}
+		}
+		credits[i] = task.Priority * float64(own) / duration.Minutes()
+	}
+	return credits
+}
+
+// Helper function to find the best ProRate total by trying every subset
+func bruteForceProRate(tasks []Task) float64 {
+	best := math.Inf(-1)
+	chosen := make([]bool, len(tasks))
+	for subset := 0; subset < 1<<len(tasks); subset++ {
+		valid := true
+		for i := range tasks {
+			chosen[i] = subset&(1<<i) != 0
+			valid = valid && (chosen[i] || !tasks[i].Mandatory)
+		}
+		if !valid {
+			continue
+		}
+		total := 0.0
+		for _, credit := range bruteForceCredits(tasks, chosen) {
+			total += credit
+		}
+		best = max(best, total)
+	}
+	return best
+}
+
+func TestProRateCredits(t *testing.T) {
+	tests := []struct {
+		name            string
+		tasks           []Task
+		opts            []Option
+		leftOut         []string
+		expectedCredits []float64
+	}{
+		{
+			name: "Touching tasks keep everything",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 4},
+				{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 6},
+			},
+			expectedCredits: []float64{4, 6},
+		},
+		{
+			name: "Partial overlap",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 4},
+				{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 6},
+			},
+			expectedCredits: []float64{2, 3},
+		},
+		{
+			name: "Quarter overlap",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(13), Priority: 1},
+				{ID: "b", StartTime: fixedTime(12), EndTime: fixedTime(14), Priority: 2},
+			},
+			expectedCredits: []float64{0.75, 1},
+		},
+		{
+			name: "Nested task earns nothing",
+			tasks: []Task{
+				{ID: "outer", StartTime: fixedTime(9), EndTime: fixedTime(13), Priority: 8},
+				{ID: "inner", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 3},
+			},
+			expectedCredits: []float64{6, 0},
+		},
+		{
+			name: "Identical tasks both earn nothing",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 2},
+				{ID: "b", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 4},
+			},
+			expectedCredits: []float64{0, 0},
+		},
+		{
+			name: "Two nested tasks at either end",
+			tasks: []Task{
+				{ID: "outer", StartTime: fixedTime(9), EndTime: fixedTime(13), Priority: 8},
+				{ID: "first", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1},
+				{ID: "last", StartTime: fixedTime(12), EndTime: fixedTime(13), Priority: 1},
+			},
+			expectedCredits: []float64{4, 0, 0},
+		},
+		{
+			name: "Time covered twice only counts once",
+			tasks: []Task{
+				{ID: "outer", StartTime: fixedTime(9), EndTime: fixedTime(13), Priority: 8},
+				{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 2},
+				{ID: "c", StartTime: fixedTime(11), EndTime: fixedTime(13), Priority: 2},
+			},
+			expectedCredits: []float64{2, 0, 0},
+		},
+		{
+			name: "Chain of partial overlaps",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 2},
+				{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 2},
+				{ID: "c", StartTime: fixedTime(11), EndTime: fixedTime(13), Priority: 2},
+			},
+			expectedCredits: []float64{1, 0, 1},
+		},
+		{
+			name: "Three way partial overlap",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(12), Priority: 3},
+				{ID: "b", StartTime: halfHour(10.5), EndTime: fixedTime(13), Priority: 5},
+				{ID: "c", StartTime: fixedTime(11), EndTime: fixedTime(14), Priority: 6},
+			},
+			expectedCredits: []float64{1.5, 0, 2},
+		},
+		{
+			name: "Tasks left out take nothing",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 4},
+				{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 6},
+			},
+			leftOut:         []string{"b"},
+			expectedCredits: []float64{4, 0},
+		},
+		{
+			name: "Other resources take nothing",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 4, ResourceID: "x"},
+				{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 6, ResourceID: "y"},
+			},
+			expectedCredits: []float64{4, 6},
+		},
+		{
+			name: "Instant inside a task earns nothing and takes nothing",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 4},
+				{ID: "instant", StartTime: fixedTime(10), EndTime: fixedTime(10), Priority: 1},
+			},
+			expectedCredits: []float64{4, 0},
+		},
+		{
+			name: "Instant at a task's end keeps everything",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 4},
+				{ID: "instant", StartTime: fixedTime(11), EndTime: fixedTime(11), Priority: 1},
+			},
+			expectedCredits: []float64{4, 1},
+		},
+		{
+			name: "Instants on the same instant earn nothing",
+			tasks: []Task{
+				{ID: "first", StartTime: fixedTime(10), EndTime: fixedTime(10), Priority: 1},
+				{ID: "second", StartTime: fixedTime(10), EndTime: fixedTime(10), Priority: 2},
+			},
+			expectedCredits: []float64{0, 0},
+		},
+		{
+			name: "Instant inside a task with instants coexisting",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 4},
+				{ID: "instant", StartTime: fixedTime(10), EndTime: fixedTime(10), Priority: 1},
+			},
+			opts:            []Option{WithInstantaneousCoexist()},
+			expectedCredits: []float64{4, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := newTestScheduler().withOptions(tt.opts).newProRateCluster(tt.tasks)
+			chosen := make([]bool, len(tt.tasks))
+			for i, task := range tt.tasks {
+				chosen[i] = true
+				for _, id := range tt.leftOut {
+					chosen[i] = chosen[i] && task.ID != id
+				}
+			}
+			for i, task := range tt.tasks {
+				if !chosen[i] {
+					continue
+				}
+				if credit := cluster.credit(i, chosen); math.Abs(credit-tt.expectedCredits[i]) > 1e-9 {
+					t.Errorf("Expected %s to earn %v, got %v", task.ID, tt.expectedCredits[i], credit)
+				}
+			}
+			if tt.opts == nil {
+				brute := bruteForceCredits(tt.tasks, chosen)
+				for i := range brute {
+					if math.Abs(brute[i]-tt.expectedCredits[i]) > 1e-9 {
+						t.Errorf("Expected the minute by minute credit of %s to be %v, got %v", tt.tasks[i].ID, tt.expectedCredits[i], brute[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFindBestScheduleProRate(t *testing.T) {
+	tests := []struct {
+		name             string
+		tasks            []Task
+		expectedChosen   []string
+		expectedPriority float64
+	}{
+		{
+			name: "Partial overlap worth sharing",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(12), Priority: 6},
+				{ID: "b", StartTime: fixedTime(11), EndTime: fixedTime(14), Priority: 6},
+			},
+			expectedChosen:   []string{"a", "b"},
+			expectedPriority: 8,
+		},
+		{
+			name: "Partial overlap not worth sharing",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 10},
+				{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 8},
+			},
+			expectedChosen:   []string{"a"},
+			expectedPriority: 10,
+		},
+		{
+			name: "Nested task left out",
+			tasks: []Task{
+				{ID: "outer", StartTime: fixedTime(9), EndTime: fixedTime(13), Priority: 8},
+				{ID: "inner", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 1},
+			},
+			expectedChosen:   []string{"outer"},
+			expectedPriority: 8,
+		},
+		{
+			name: "Nested task beats its container",
+			tasks: []Task{
+				{ID: "outer", StartTime: fixedTime(9), EndTime: fixedTime(13), Priority: 4},
+				{ID: "inner", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 10},
+			},
+			expectedChosen:   []string{"inner"},
+			expectedPriority: 10,
+		},
+		{
+			name: "Overlapping mandatory tasks share",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 2, Mandatory: true},
+				{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 4, Mandatory: true},
+			},
+			expectedChosen:   []string{"a", "b"},
+			expectedPriority: 3,
+		},
+		{
+			name: "Instant inside a task adds nothing",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 4},
+				{ID: "instant", StartTime: fixedTime(10), EndTime: fixedTime(10), Priority: 1},
+			},
+			expectedChosen:   []string{"a"},
+			expectedPriority: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chosen, totalPriority, rejected, err := newTestScheduler().FindBestSchedule(tt.tasks, WithOverlapPolicy(ProRate))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if math.Abs(totalPriority-tt.expectedPriority) > 1e-9 {
+				t.Errorf("Expected total priority %v, got %v", tt.expectedPriority, totalPriority)
+			}
+			if len(chosen) != len(tt.expectedChosen) {
+				t.Fatalf("Expected %v chosen, got %+v", tt.expectedChosen, chosen)
+			}
+			credits := 0.0
+			for i, task := range chosen {
+				if task.ID != tt.expectedChosen[i] {
+					t.Errorf("Expected %s at position %d, got %s", tt.expectedChosen[i], i, task.ID)
+				}
+				credits += task.Priority
+			}
+			if math.Abs(credits-totalPriority) > 1e-9 {
+				t.Errorf("Expected the chosen tasks' credits to add up to %v, got %v", totalPriority, credits)
+			}
+			for _, rejection := range rejected {
+				if rejection.Reason != RejectionReasonLowPriority {
+					t.Errorf("Expected only LOW_PRIORITY rejections, got %+v", rejection)
+				}
+			}
+			if len(chosen)+len(rejected) != len(tt.tasks) {
+				t.Errorf("Expected every task to be chosen or rejected, got %d and %d", len(chosen), len(rejected))
+			}
+		})
+	}
+}
+
+func TestProRateErrors(t *testing.T) {
+	tasks := []Task{{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1}}
+	s := newTestScheduler()
+	optionSets := map[string][]Option{
+		"soft conflict": {WithSoftConflict(flatPenalty(1))},
+		"conflict func": {WithConflictFunc(func(a, b Task) bool { return false })},
+		"min gap":       {WithMinGap(time.Minute)},
+		"max tasks":     {WithMaxTasks(1)},
+		"groups":        {WithExclusiveGroups()},
+	}
+	for name, opts := range optionSets {
+		if _, _, _, err := s.FindBestSchedule(tasks, append(opts, WithOverlapPolicy(ProRate))...); err == nil {
+			t.Errorf("Expected ProRate to refuse %s", name)
+		}
+	}
+	if _, _, _, err := s.FindBestScheduleCapacity(tasks, 2, WithOverlapPolicy(ProRate)); err == nil {
+		t.Error("Expected FindBestScheduleCapacity to refuse ProRate")
+	}
+	if _, _, _, err := s.FindBestScheduleMulti(tasks, 2, WithOverlapPolicy(ProRate)); err == nil {
+		t.Error("Expected FindBestScheduleMulti to refuse ProRate")
+	}
+	if _, _, _, err := s.ScheduleStream(context.Background(), tasks, WithOverlapPolicy(ProRate)); err == nil {
+		t.Error("Expected ScheduleStream to refuse ProRate")
+	}
+}
+
+func TestProRateMatchesBruteForce(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for round := 0; round < 300; round++ {
+		tasks := make([]Task, 1+random.Intn(9))
+		for i := range tasks {
+			start := fixedTime(9).Add(time.Duration(random.Intn(12)) * 30 * time.Minute)
+			tasks[i] = Task{
+				StartTime: start,
+				EndTime:   start.Add(time.Duration(random.Intn(5)) * 30 * time.Minute),
+				// Distinct priorities so no task is dropped as a duplicate of another
+				Priority:   float64(random.Intn(20))/3 + float64(i)/1000,
+				ResourceID: []string{"", "", "a"}[random.Intn(3)],
+				Mandatory:  random.Intn(10) == 0,
+			}
+		}
+		chosen, totalPriority, rejected, err := newTestScheduler().FindBestSchedule(tasks, WithOverlapPolicy(ProRate))
+		if err != nil {
+			t.Fatalf("Round %d: unexpected error: %v", round, err)
+		}
+		if expected := bruteForceProRate(tasks); math.Abs(totalPriority-expected) > 1e-9 {
+			t.Fatalf("Round %d: expected %v, got %v for %+v", round, expected, totalPriority, tasks)
+		}
+		if len(chosen)+len(rejected) != len(tasks) {
+			t.Fatalf("Round %d: expected every task to be chosen or rejected, got %d and %d", round, len(chosen), len(rejected))
+		}
+	}
+}
+
+func TestProRateHeuristic(t *testing.T) {
+	// Too many overlapping tasks for the exact search, the heuristic still beats Exclude
+	tasks := make([]Task, 0, 3*maxProRateExact)
+	for i := 0; i < 3*maxProRateExact; i++ {
+		start := fixedTime(9).Add(time.Duration(i) * 20 * time.Minute)
+		tasks = append(tasks, Task{StartTime: start, EndTime: start.Add(30 * time.Minute), Priority: float64(1 + i%3)})
+	}
+	_, excludePriority, _, err := newTestScheduler().FindBestSchedule(tasks, WithOverlapPolicy(Exclude))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	chosen, proRatePriority, rejected, err := newTestScheduler().FindBestSchedule(tasks, WithOverlapPolicy(ProRate))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if proRatePriority <= excludePriority {
+		t.Errorf("Expected sharing small overlaps to beat %v, got %v", excludePriority, proRatePriority)
+	}
+	if len(chosen)+len(rejected) != len(tasks) {
+		t.Errorf("Expected every task to be chosen or rejected, got %d and %d", len(chosen), len(rejected))
+	}
+	// The reported total is what the chosen tasks earn between them
+	credits := 0.0
+	for _, credit := range bruteForceCredits(tasks, chosenMask(tasks, chosen)) {
+		credits += credit
+	}
+	if math.Abs(credits-proRatePriority) > 1e-9 {
+		t.Errorf("Expected the total %v to match what the chosen tasks earn, %v", proRatePriority, credits)
+	}
+}
+
+func TestProRateSeedIsNotTraced(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	// The interval DP that seeds the search pushes the first task out for the second, ProRate
+	// takes both
+	tasks := []Task{
+		{StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 5},
+		{StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 8},
+	}
+	trace := &ScheduleTrace{}
+	if _, _, _, err := newTestScheduler().FindBestSchedule(tasks, WithOverlapPolicy(ProRate), WithTrace(trace)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	for _, event := range spans[0].Events() {
+		for _, kv := range event.Attributes {
+			if kv.Key == "reason" && kv.Value.AsString() == RejectionReasonConflict.String() {
+				t.Errorf("Expected no %s rejections from ProRate, got a %s event", RejectionReasonConflict, event.Name)
+			}
+		}
+	}
+	if len(trace.Timelines) != 0 {
+		t.Errorf("Expected no DP tables from ProRate, got %d", len(trace.Timelines))
+	}
+}
+
+// Helper function to mark which of tasks were chosen, matching them by start time
+func chosenMask(tasks, chosen []Task) []bool {
+	mask := make([]bool, len(tasks))
+	for i, task := range tasks {
+		for _, other := range chosen {
+			mask[i] = mask[i] || other.StartTime.Equal(task.StartTime)
+		}
+	}
+	return mask
+}
@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxRecurrences caps how many tasks a single RecurringTask can expand into, so a typo in
+// an end date can't eat all our memory
+const maxRecurrences = 100000
+
+// RecurringTask describes a task that repeats at a fixed interval, like a contact every orbit
+type RecurringTask struct {
+	// Base is the first occurrence, every other occurrence is a copy shifted by Interval
+	Base     Task          `json:"base"`
+	Interval time.Duration `json:"interval"`
+	// Count is the total number of occurrences including the first. If zero, occurrences
+	// continue while their start time is not after Until.
+	Count int       `json:"count"`
+	Until time.Time `json:"until"`
+}
+
+// ExpandRecurring materialises recurring tasks into concrete tasks ready for FindBestSchedule.
+// Occurrences are computed in UTC by adding whole intervals, so there are no DST jumps.
+// Occurrences are allowed to overlap each other (interval shorter than the task), the
+// scheduler then picks between them like any other conflicting tasks. A Deadline or NotBefore
+// on the base moves along with each occurrence. If the base task has an ID, occurrence k gets
+// the ID "<id>-<k>".
+func ExpandRecurring(rts []RecurringTask) ([]Task, error) {
+	tasks := make([]Task, 0)
+	for i, rt := range rts {
+		if rt.Interval <= 0 {
+			return nil, fmt.Errorf("recurring task %d: interval must be positive, got %v", i, rt.Interval)
+		}
+		if rt.Count < 0 {
+			return nil, fmt.Errorf("recurring task %d: count must not be negative, got %d", i, rt.Count)
+		}
+		if rt.Count == 0 && rt.Until.IsZero() {
+			return nil, fmt.Errorf("recurring task %d: either count or until must be set", i)
+		}
+
+		for k := 0; rt.Count == 0 || k < rt.Count; k++ {
+			if k >= maxRecurrences {
+				return nil, fmt.Errorf("recurring task %d: expands to more than %d occurrences", i, maxRecurrences)
+			}
+			offset := time.Duration(k) * rt.Interval
+			occurrence := rt.Base
+			occurrence.StartTime = rt.Base.StartTime.UTC().Add(offset)
+			occurrence.EndTime = rt.Base.EndTime.UTC().Add(offset)
+			if !rt.Base.Deadline.IsZero() {
+				occurrence.Deadline = rt.Base.Deadline.UTC().Add(offset)
+			}
+			if !rt.Base.NotBefore.IsZero() {
+				occurrence.NotBefore = rt.Base.NotBefore.UTC().Add(offset)
+			}
+			if !rt.Until.IsZero() && occurrence.StartTime.After(rt.Until) {
+				break
+			}
+			if rt.Base.ID != "" {
+				occurrence.ID = fmt.Sprintf("%s-%d", rt.Base.ID, k)
+			}
+			tasks = append(tasks, occurrence)
+		}
+	}
+	return tasks, nil
+}
@@ -0,0 +1,116 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpandRecurring(t *testing.T) {
+	orbit := 90 * time.Minute
+	base := Task{ID: "pass", StartTime: fixedTime(9), EndTime: fixedTime(9).Add(10 * time.Minute), Priority: 3}
+
+	t.Run("Count", func(t *testing.T) {
+		tasks, err := ExpandRecurring([]RecurringTask{{Base: base, Interval: orbit, Count: 3}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(tasks) != 3 {
+			t.Fatalf("Expected 3 tasks, got %d", len(tasks))
+		}
+		if !tasks[2].StartTime.Equal(fixedTime(12)) || !tasks[2].EndTime.Equal(fixedTime(12).Add(10*time.Minute)) {
+			t.Errorf("Expected the third pass at 12:00, got %v", tasks[2].StartTime)
+		}
+		if tasks[1].ID != "pass-1" || tasks[1].Priority != 3 {
+			t.Errorf("Expected a copy of the base with ID pass-1, got %+v", tasks[1])
+		}
+	})
+
+	t.Run("Until is inclusive", func(t *testing.T) {
+		tasks, err := ExpandRecurring([]RecurringTask{{Base: base, Interval: orbit, Until: fixedTime(13)}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		// 9:00, 10:30, 12:00 fit, 13:30 doesn't
+		if len(tasks) != 3 {
+			t.Errorf("Expected 3 tasks, got %d", len(tasks))
+		}
+	})
+
+	t.Run("Computed in UTC across a DST change", func(t *testing.T) {
+		newYork, err := time.LoadLocation("America/New_York")
+		if err != nil {
+			t.Skipf("No timezone data: %v", err)
+		}
+		// DST starts at 2:00 local on 10 March 2024
+		start := time.Date(2024, 3, 10, 0, 0, 0, 0, newYork)
+		tasks, err := ExpandRecurring([]RecurringTask{{
+			Base:     Task{StartTime: start, EndTime: start.Add(time.Hour), Priority: 1},
+			Interval: 24 * time.Hour,
+			Count:    2,
+		}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if gap := tasks[1].StartTime.Sub(tasks[0].StartTime); gap != 24*time.Hour {
+			t.Errorf("Expected exactly 24h between occurrences, got %v", gap)
+		}
+		if tasks[1].StartTime.Location() != time.UTC {
+			t.Errorf("Expected occurrences in UTC, got %v", tasks[1].StartTime.Location())
+		}
+	})
+
+	t.Run("Overlapping occurrences are scheduled like any other tasks", func(t *testing.T) {
+		long := Task{StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 1}
+		tasks, err := ExpandRecurring([]RecurringTask{{Base: long, Interval: time.Hour, Count: 4}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		_, resultPriority, _, err := newTestScheduler().FindBestSchedule(tasks)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resultPriority != 2 {
+			t.Errorf("Expected 2 of the 4 overlapping occurrences, got %.2f", resultPriority)
+		}
+	})
+
+	t.Run("Deadline and NotBefore move with each occurrence", func(t *testing.T) {
+		windowed := base
+		windowed.NotBefore = fixedTime(8)
+		windowed.Deadline = fixedTime(10)
+		tasks, err := ExpandRecurring([]RecurringTask{{Base: windowed, Interval: 24 * time.Hour, Count: 3}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for k, task := range tasks {
+			offset := time.Duration(k) * 24 * time.Hour
+			if !task.NotBefore.Equal(fixedTime(8).Add(offset)) {
+				t.Errorf("Expected occurrence %d not before %v, got %v", k, fixedTime(8).Add(offset), task.NotBefore)
+			}
+			if !task.Deadline.Equal(fixedTime(10).Add(offset)) {
+				t.Errorf("Expected occurrence %d due by %v, got %v", k, fixedTime(10).Add(offset), task.Deadline)
+			}
+		}
+		_, _, rejected, err := newTestScheduler().FindBestSchedule(tasks)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(rejected) != 0 {
+			t.Errorf("Expected every occurrence to fit its own window, got %+v", rejected)
+		}
+	})
+
+	t.Run("Invalid input", func(t *testing.T) {
+		invalid := []RecurringTask{
+			{Base: base, Interval: 0, Count: 2},
+			{Base: base, Interval: -time.Hour, Count: 2},
+			{Base: base, Interval: time.Hour},
+			{Base: base, Interval: time.Hour, Count: -1},
+		}
+		for i, rt := range invalid {
+			if _, err := ExpandRecurring([]RecurringTask{rt}); err == nil {
+				t.Errorf("Expected an error for invalid recurring task %d", i)
+			}
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+// RenderText writes a schedule as a plain text report for people to read: the chosen tasks,
+// the rejected tasks with why they were dropped, the total priority and the statistics. The
+// layout only depends on out, so the same schedule always renders the same way. Times are
+// written as they are in out, see FormatSchedule for showing them in a local time zone.
+func RenderText(out ScheduleOutput, w io.Writer) error {
+	writer := bufio.NewWriter(w)
+
+	fmt.Fprintf(writer, "Chosen tasks (%d):\n", len(out.ChosenTasks))
+	if len(out.ChosenTasks) == 0 {
+		fmt.Fprintln(writer, "  none")
+	} else {
+		table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "  ID\tSTART\tEND\tPRIORITY\tRESOURCE")
+		for _, task := range out.ChosenTasks {
+			fmt.Fprintf(table, "  %s\t%s\t%s\t%s\t%s\n", textOrDash(task.ID), task.StartTime, task.EndTime, formatTextNumber(task.Priority), textOrDash(task.ResourceID))
+		}
+		table.Flush()
+	}
+
+	fmt.Fprintf(writer, "\nRejected tasks (%d):\n", len(out.RejectedTasks))
+	if len(out.RejectedTasks) == 0 {
+		fmt.Fprintln(writer, "  none")
+	} else {
+		table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "  ID\tSTART\tEND\tPRIORITY\tREASON\tCAUSED BY")
+		for _, task := range out.RejectedTasks {
+			fmt.Fprintf(table, "  %s\t%s\t%s\t%s\t%s\t%s\n", textOrDash(task.ID), task.StartTime, task.EndTime, formatTextNumber(task.Priority), textOrDash(task.RejectionReason.String()), textOrDash(task.CausedByID))
+		}
+		table.Flush()
+	}
+
+	stats := out.Statistics
+	fmt.Fprintf(writer, "\nTotal priority: %s\n", formatTextNumber(out.TotalPriority))
+	fmt.Fprintln(writer, "\nStatistics:")
+	table := tabwriter.NewWriter(writer, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(table, "  Total tasks:\t%d\n", stats.TotalTasks)
+	fmt.Fprintf(table, "  Scheduled tasks:\t%d\n", stats.ScheduledTasks)
+	fmt.Fprintf(table, "  Rejected tasks:\t%d\n", stats.RejectedTasks)
+	if out.TimeRange.Start == "" && out.TimeRange.End == "" {
+		fmt.Fprintln(table, "  Time range:\t-")
+	} else {
+		fmt.Fprintf(table, "  Time range:\t%s - %s\n", out.TimeRange.Start, out.TimeRange.End)
+	}
+	fmt.Fprintf(table, "  Utilization:\t%s of %s minutes (%.1f%%)\n", formatTextNumber(stats.UtilizedMinutes), formatTextNumber(stats.WindowMinutes), stats.UtilizationRatio*100)
+	if stats.TotalValue != 0 || stats.TotalCost != 0 {
+		fmt.Fprintf(table, "  Value:\t%s, cost %s, net %s\n", formatTextNumber(stats.TotalValue), formatTextNumber(stats.TotalCost), formatTextNumber(stats.NetValue))
+	}
+	table.Flush()
+
+	if len(stats.ByTag) > 0 {
+		tags := make([]string, 0, len(stats.ByTag))
+		for tag := range stats.ByTag {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+		fmt.Fprintln(writer, "\nBy tag:")
+		table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "  TAG\tCHOSEN\tCHOSEN PRIORITY\tREJECTED\tREJECTED PRIORITY")
+		for _, tag := range tags {
+			tagStats := stats.ByTag[tag]
+			fmt.Fprintf(table, "  %s\t%d\t%s\t%d\t%s\n", tag, tagStats.ChosenTasks, formatTextNumber(tagStats.ChosenPriority), tagStats.RejectedTasks, formatTextNumber(tagStats.RejectedPriority))
+		}
+		table.Flush()
+	}
+	return writer.Flush()
+}
+
+// formatTextNumber writes a number as briefly as it can be without losing anything
+func formatTextNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+// textOrDash stands in a dash for an empty field so the report's columns stay readable
+func textOrDash(text string) string {
+	if text == "" {
+		return "-"
+	}
+	return text
+}
@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata with the current output")
+
+// Helper function to compare output with a golden file in testdata, rewriting it with -update
+func checkGolden(t *testing.T, name, output string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, []byte(output), 0644); err != nil {
+			t.Fatalf("Unexpected error updating %s: %v", path, err)
+		}
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error reading %s: %v", path, err)
+	}
+	if output != string(expected) {
+		t.Errorf("Output doesn't match %s (rerun with -update if the change is intended), got:\n%s", path, output)
+	}
+}
+
+func TestRenderText(t *testing.T) {
+	tasks := []Task{
+		{ID: "pass-1", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 7.5, ResourceID: "antenna-1", Tags: []string{"acme"}, Value: 500, Cost: 120},
+		{ID: "pass-2", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 3, Tags: []string{"acme", "beta"}},
+		{ID: "downlink", StartTime: fixedTime(11), EndTime: fixedTime(13), Priority: 10},
+		{ID: "snapshot", StartTime: fixedTime(12), EndTime: fixedTime(12), Priority: 2},
+		{ID: "late", StartTime: fixedTime(14), EndTime: fixedTime(15), Priority: 1, Deadline: fixedTime(14)},
+	}
+	chosen, totalPriority, rejected, err := newTestScheduler().FindBestSchedule(tasks)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var buffer strings.Builder
+	if err := RenderText(NewScheduleOutput(tasks, chosen, totalPriority, rejected), &buffer); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkGolden(t, "render_text.golden", buffer.String())
+}
+
+func TestRenderTextEmpty(t *testing.T) {
+	var buffer strings.Builder
+	if err := RenderText(NewScheduleOutput(nil, nil, 0, nil), &buffer); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkGolden(t, "render_text_empty.golden", buffer.String())
+}
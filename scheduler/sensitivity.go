@@ -0,0 +1,131 @@
+package scheduler
+
+import (
+	"math"
+)
+
+// MinPriorityToSchedule answers "how high would tasks[taskIndex]'s priority have to be for it
+// to be scheduled?". Any priority above the returned threshold gets the task into the best
+// schedule, at exactly the threshold it ties with leaving the task out and the tie-break
+// decides. The threshold is 0 for a task that fits alongside the best schedule for free, and
+// -Inf for one that's scheduled whatever its priority because it (or a task in its bundle) is
+// mandatory.
+//
+// Rather than searching over priorities it re-runs the scheduler twice: once with the task
+// (and the rest of its bundle) left out, and once with it forced in at priority 0. The
+// threshold is the difference between the two totals. false is returned if the task can't be
+// scheduled at any priority, e.g. it misses its deadline or conflicts with a mandatory task,
+// or if taskIndex is out of range or the input is invalid or infeasible anyway. opts apply to
+// both runs.
+func (s *Scheduler) MinPriorityToSchedule(tasks []Task, taskIndex int, opts ...Option) (float64, bool) {
+	if taskIndex < 0 || taskIndex >= len(tasks) {
+		return 0, false
+	}
+	target := tasks[taskIndex]
+	if target.ID == "" {
+		// The forced run has to find the task again in the schedule it returns
+		target.ID = "min-priority-target"
+	}
+	alwaysScheduled := target.Mandatory
+
+	forced := make([]Task, len(tasks))
+	without := make([]Task, 0, len(tasks))
+	for i, task := range tasks {
+		forced[i] = task
+		sameBundle := target.BundleID != "" && task.BundleID == target.BundleID
+		if sameBundle {
+			alwaysScheduled = alwaysScheduled || task.Mandatory
+		}
+		if i != taskIndex && !sameBundle {
+			without = append(without, task)
+		}
+	}
+	target.Priority = 0
+	target.PriorityFunc = nil
+	target.Mandatory = true
+	forced[taskIndex] = target
+
+	forcedChosen, forcedTotal, _, err := s.FindBestSchedule(forced, opts...)
+	if err != nil || !containsTaskID(forcedChosen, target.ID) {
+		return 0, false
+	}
+	if alwaysScheduled {
+		return math.Inf(-1), true
+	}
+	_, withoutTotal, _, err := s.FindBestSchedule(without, opts...)
+	if err != nil {
+		return 0, false
+	}
+	return withoutTotal - forcedTotal, true
+}
+
+// containsTaskID checks if any of tasks has the given ID
+func containsTaskID(tasks []Task, id string) bool {
+	for _, task := range tasks {
+		if task.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// MostBlockingTask answers "which commitment is costing the others the most?". It finds the
+// chosen task whose removal lets the rest of the tasks achieve the most extra priority, and
+// returns its index in tasks with that gain: the best total without it, less what the other
+// chosen tasks were worth alongside it. A task sitting in a gap nobody else wants frees
+// nothing and gains 0. Removing a task in a bundle removes the whole bundle, since the rest
+// of it can't be chosen alone.
+//
+// It re-runs the scheduler once per chosen task with that task left out, so it costs as many
+// runs as there are chosen tasks. Ties go to the task earliest in tasks. -1 is returned if
+// nothing is chosen or the input is invalid or infeasible. opts apply to every run.
+func (s *Scheduler) MostBlockingTask(tasks []Task, opts ...Option) (taskIndex int, priorityGain float64) {
+	// Fill in IDs up front so the chosen tasks can be found in tasks again, and so they
+	// don't shift when a task is left out of a re-run
+	withIDs := append([]Task(nil), tasks...)
+	s.withOptions(opts).assignMissingIDs(withIDs)
+	chosen, total, _, err := s.FindBestSchedule(withIDs, opts...)
+	if err != nil {
+		return -1, 0
+	}
+	indexesByID := make(map[string][]int, len(withIDs))
+	for i, task := range withIDs {
+		indexesByID[task.ID] = append(indexesByID[task.ID], i)
+	}
+
+	// Repeated IDs are matched up with their tasks in order
+	chosenIndexes := make([]int, len(chosen))
+	for i, task := range chosen {
+		chosenIndexes[i] = indexesByID[task.ID][0]
+		indexesByID[task.ID] = indexesByID[task.ID][1:]
+	}
+
+	taskIndex, priorityGain = -1, 0
+	for _, index := range chosenIndexes {
+		bundleID := withIDs[index].BundleID
+		removed := func(i int) bool {
+			return i == index || (bundleID != "" && withIDs[i].BundleID == bundleID)
+		}
+		without := make([]Task, 0, len(withIDs))
+		for i, task := range withIDs {
+			if !removed(i) {
+				without = append(without, task)
+			}
+		}
+		removedPriority := 0.0
+		for i, task := range chosen {
+			if removed(chosenIndexes[i]) {
+				removedPriority += task.Priority
+			}
+		}
+		_, withoutTotal, _, err := s.FindBestSchedule(without, opts...)
+		if err != nil {
+			continue
+		}
+		gain := withoutTotal - (total - removedPriority)
+		if taskIndex == -1 || gain > priorityGain || (gain == priorityGain && index < taskIndex) {
+			taskIndex, priorityGain = index, gain
+		}
+	}
+	return taskIndex, priorityGain
+}
@@ -0,0 +1,152 @@
+package scheduler
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMinPriorityToSchedule(t *testing.T) {
+	tasks := []Task{
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 5},
+		{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 3},
+		{ID: "c", StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 1},
+		{ID: "fixed", StartTime: fixedTime(13), EndTime: fixedTime(14), Priority: 1, Mandatory: true},
+		{ID: "blocked", StartTime: fixedTime(13), EndTime: fixedTime(15), Priority: 9},
+		{ID: "late", StartTime: fixedTime(15), EndTime: fixedTime(17), Deadline: fixedTime(16), Priority: 2},
+	}
+	tests := []struct {
+		name              string
+		taskIndex         int
+		expectedThreshold float64
+		expectedOK        bool
+	}{
+		{name: "Rejected task", taskIndex: 1, expectedThreshold: 6, expectedOK: true},
+		{name: "Already chosen task", taskIndex: 2, expectedThreshold: 0, expectedOK: true},
+		{name: "Mandatory task", taskIndex: 3, expectedThreshold: math.Inf(-1), expectedOK: true},
+		{name: "Blocked by a mandatory task", taskIndex: 4, expectedOK: false},
+		{name: "Misses its deadline", taskIndex: 5, expectedOK: false},
+		{name: "Out of range", taskIndex: 6, expectedOK: false},
+	}
+
+	s := newTestScheduler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			threshold, ok := s.MinPriorityToSchedule(tasks, tt.taskIndex)
+			if ok != tt.expectedOK {
+				t.Fatalf("Expected ok to be %v, got %v", tt.expectedOK, ok)
+			}
+			if ok && threshold != tt.expectedThreshold {
+				t.Errorf("Expected threshold %v, got %v", tt.expectedThreshold, threshold)
+			}
+		})
+	}
+	if tasks[1].Priority != 3 || tasks[4].Mandatory {
+		t.Error("Expected the input to be left alone")
+	}
+}
+
+func TestMinPriorityToScheduleThreshold(t *testing.T) {
+	// Just above the threshold the task is scheduled, just below it isn't
+	tasks := []Task{
+		{ID: "long", StartTime: fixedTime(9), EndTime: fixedTime(12), Priority: 10},
+		{ID: "first", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 4},
+		{ID: "second", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 3},
+		{ID: "third", StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 2},
+		{ID: "evening", StartTime: fixedTime(18), EndTime: fixedTime(19), Priority: -2},
+		{ID: "bundled", StartTime: fixedTime(11).Add(30 * time.Minute), EndTime: fixedTime(13), Priority: 1, BundleID: "pair"},
+		{ID: "partner", StartTime: fixedTime(14), EndTime: fixedTime(15), Priority: 1, BundleID: "pair"},
+	}
+	s := newTestScheduler()
+	for i := range tasks {
+		threshold, ok := s.MinPriorityToSchedule(tasks, i, WithMaxTasks(3))
+		if !ok {
+			t.Fatalf("Expected a threshold for %s", tasks[i].ID)
+		}
+		for _, delta := range []float64{0.5, -0.5} {
+			adjusted := append([]Task(nil), tasks...)
+			adjusted[i].Priority = threshold + delta
+			chosen, _, _, err := s.FindBestSchedule(adjusted, WithMaxTasks(3))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if containsTaskID(chosen, tasks[i].ID) != (delta > 0) {
+				t.Errorf("Expected %s at %v to be chosen: %v", tasks[i].ID, threshold+delta, delta > 0)
+			}
+		}
+	}
+}
+
+func TestMostBlockingTask(t *testing.T) {
+	tests := []struct {
+		name          string
+		tasks         []Task
+		expectedIndex int
+		expectedGain  float64
+	}{
+		{
+			name: "Long task blocking two others",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 3},
+				{ID: "long", StartTime: fixedTime(10), EndTime: fixedTime(14), Priority: 10},
+				{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 6},
+				{ID: "c", StartTime: fixedTime(12), EndTime: fixedTime(14), Priority: 3},
+				{ID: "d", StartTime: fixedTime(15), EndTime: fixedTime(16), Priority: 1},
+				{ID: "e", StartTime: fixedTime(15), EndTime: fixedTime(16), Priority: 0.5},
+			},
+			expectedIndex: 1,
+			expectedGain:  9,
+		},
+		{
+			name: "Nothing is blocked",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 3},
+				{ID: "b", StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 1},
+			},
+			expectedIndex: 0,
+			expectedGain:  0,
+		},
+		{
+			name: "Bundle leaves together",
+			tasks: []Task{
+				{ID: "uplink", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 4, BundleID: "pair"},
+				{ID: "downlink", StartTime: fixedTime(12), EndTime: fixedTime(13), Priority: 4, BundleID: "pair"},
+				{ID: "x", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 3},
+				{ID: "y", StartTime: fixedTime(12), EndTime: fixedTime(13), Priority: 3},
+				{ID: "z", StartTime: fixedTime(14), EndTime: fixedTime(15), Priority: 1},
+			},
+			expectedIndex: 0,
+			expectedGain:  6,
+		},
+		{
+			name: "Duplicate IDs are told apart",
+			tasks: []Task{
+				{ID: "dup", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 2},
+				{ID: "dup", StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 2},
+				{ID: "other", StartTime: fixedTime(11), EndTime: fixedTime(12), Priority: 1.5},
+			},
+			expectedIndex: 1,
+			expectedGain:  1.5,
+		},
+		{
+			name:          "Nothing chosen",
+			tasks:         []Task{{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: -1}},
+			expectedIndex: -1,
+		},
+		{
+			name:          "Invalid input",
+			tasks:         []Task{{StartTime: fixedTime(10), EndTime: fixedTime(9), Priority: 1}},
+			expectedIndex: -1,
+		},
+	}
+
+	s := newTestScheduler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, gain := s.MostBlockingTask(tt.tasks)
+			if index != tt.expectedIndex || gain != tt.expectedGain {
+				t.Errorf("Expected task %d gaining %v, got task %d gaining %v", tt.expectedIndex, tt.expectedGain, index, gain)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// ErrServiceStopped is returned by SchedulerService.Submit once the service has started
+// shutting down
+var ErrServiceStopped = errors.New("scheduler service stopped")
+
+// serviceQueueSize is how many submitted batches can wait for Run before Submit blocks
+const serviceQueueSize = 64
+
+// ScheduleResult is what a batch submitted to a SchedulerService comes back as, the same
+// values FindBestSchedule returns
+type ScheduleResult struct {
+	ChosenTasks   []Task
+	TotalPriority float64
+	RejectedTasks []RejectedTask
+	Err           error
+}
+
+// serviceBatch is a submitted batch waiting for Run
+type serviceBatch struct {
+	ctx     context.Context
+	tasks   []Task
+	opts    []Option
+	results chan ScheduleResult
+}
+
+// SchedulerService runs batches of tasks through a Scheduler in the background for long
+// running services. Batches are submitted with Submit and solved one at a time by Run, which
+// ServiceModule starts and stops with the fx lifecycle.
+type SchedulerService struct {
+	scheduler *Scheduler
+	logger    *otelzap.Logger
+	// batches is never closed, a submitter might still be sending on it when Stop is called
+	batches chan serviceBatch
+	// done is closed by Stop, Submit gives up waiting for room in the queue once it is
+	done chan struct{}
+	// mu guards stopped and is only ever held briefly, never across a send. submitting counts
+	// the Submit calls still trying to queue, Run waits for them before its last drain so none
+	// of their batches are left behind.
+	mu         sync.Mutex
+	stopped    bool
+	submitting sync.WaitGroup
+}
+
+func NewSchedulerService(scheduler *Scheduler, logger *otelzap.Logger) *SchedulerService {
+	return &SchedulerService{
+		scheduler: scheduler,
+		logger:    logger,
+		batches:   make(chan serviceBatch, serviceQueueSize),
+		done:      make(chan struct{}),
+	}
+}
+
+// Submit queues tasks to be scheduled with opts, the result arrives on the returned channel
+// which gets exactly one value. ctx covers both waiting for room in the queue and the
+// scheduling itself. ErrServiceStopped is returned once Stop has been called, including to a
+// Submit still waiting for room in the queue.
+func (s *SchedulerService) Submit(ctx context.Context, tasks []Task, opts ...Option) (<-chan ScheduleResult, error) {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return nil, ErrServiceStopped
+	}
+	s.submitting.Add(1)
+	s.mu.Unlock()
+	defer s.submitting.Done()
+
+	results := make(chan ScheduleResult, 1)
+	select {
+	case s.batches <- serviceBatch{ctx: ctx, tasks: tasks, opts: opts, results: results}:
+		return results, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.done:
+		return nil, ErrServiceStopped
+	}
+}
+
+// Run solves submitted batches until Stop has been called and every batch queued before it
+// has been solved. Each batch's own context controls its computation, cancelling ctx only
+// stops Run waiting, anything still queued then gets ctx's error as its result.
+func (s *SchedulerService) Run(ctx context.Context) {
+	for {
+		select {
+		case batch := <-s.batches:
+			s.solve(batch)
+		case <-s.done:
+			s.drain(s.solve)
+			return
+		case <-ctx.Done():
+			s.Stop()
+			s.drain(func(batch serviceBatch) {
+				batch.results <- ScheduleResult{Err: ctx.Err()}
+			})
+			return
+		}
+	}
+}
+
+// drain hands every batch left in the queue to handle once Stop has been called. Submit calls
+// still trying to queue give up as soon as done is closed, so waiting for them is quick and
+// makes sure a batch that got in at the last moment isn't left without a result.
+func (s *SchedulerService) drain(handle func(serviceBatch)) {
+	s.submitting.Wait()
+	for {
+		select {
+		case batch := <-s.batches:
+			handle(batch)
+		default:
+			return
+		}
+	}
+}
+
+// solve runs one batch and hands back its result
+func (s *SchedulerService) solve(batch serviceBatch) {
+	chosenTasks, totalPriority, rejectedTasks, err := s.scheduler.FindBestScheduleContext(batch.ctx, batch.tasks, batch.opts...)
+	if err != nil {
+		s.logger.Ctx(batch.ctx).Warn("Scheduler service batch failed", zap.Error(err))
+	}
+	batch.results <- ScheduleResult{
+		ChosenTasks:   chosenTasks,
+		TotalPriority: totalPriority,
+		RejectedTasks: rejectedTasks,
+		Err:           err,
+	}
+}
+
+// Stop stops the service taking new batches, Run carries on until the ones already queued
+// are done. It never waits on a submitter and is safe to call more than once.
+func (s *SchedulerService) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return
+	}
+	s.stopped = true
+	close(s.done)
+}
+
+// RegisterServiceHooks runs the service for the lifetime of the fx app, on stop it waits for
+// in-flight and queued batches to drain (or the stop context to run out)
+func RegisterServiceHooks(lc fx.Lifecycle, service *SchedulerService) {
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			// The start context only covers starting, Run has to outlive it
+			go func() {
+				defer close(done)
+				service.Run(context.Background())
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			service.Stop()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				service.logger.Error("Scheduler service didn't drain before shutdown", zap.Error(ctx.Err()))
+				return ctx.Err()
+			}
+		},
+	})
+}
+
+// ServiceModule provides a SchedulerService tied to the fx lifecycle, it needs Module for the
+// Scheduler
+var ServiceModule = fx.Module("scheduler_service",
+	fx.Provide(NewSchedulerService),
+	fx.Invoke(RegisterServiceHooks),
+)
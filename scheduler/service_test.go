@@ -0,0 +1,132 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+)
+
+func TestSchedulerService(t *testing.T) {
+	var service *SchedulerService
+	app := fxtest.New(t,
+		fx.Supply(otelzap.New(zap.NewNop())),
+		Module,
+		ServiceModule,
+		fx.Populate(&service),
+	)
+	app.RequireStart()
+
+	tasks := []Task{
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 5},
+		{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 3},
+	}
+	results, err := service.Submit(context.Background(), tasks)
+	if err != nil {
+		t.Fatalf("Unexpected error submitting: %v", err)
+	}
+	result := <-results
+	if result.Err != nil || result.TotalPriority != 5 || len(result.ChosenTasks) != 1 || len(result.RejectedTasks) != 1 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	// Everything queued before shutdown still gets solved
+	pending := make([]<-chan ScheduleResult, 0)
+	for i := 0; i < 10; i++ {
+		results, err := service.Submit(context.Background(), tasks, WithMinGap(0))
+		if err != nil {
+			t.Fatalf("Unexpected error submitting: %v", err)
+		}
+		pending = append(pending, results)
+	}
+	app.RequireStop()
+	for i, results := range pending {
+		if result := <-results; result.Err != nil || result.TotalPriority != 5 {
+			t.Errorf("Expected batch %d to drain, got %+v", i, result)
+		}
+	}
+
+	if _, err := service.Submit(context.Background(), tasks); !errors.Is(err, ErrServiceStopped) {
+		t.Errorf("Expected ErrServiceStopped after shutdown, got %v", err)
+	}
+}
+
+func TestSchedulerServiceBatchErrors(t *testing.T) {
+	service := NewSchedulerService(newTestScheduler(), otelzap.New(zap.NewNop()))
+	go service.Run(context.Background())
+	defer service.Stop()
+
+	invalid := []Task{{ID: "backwards", StartTime: fixedTime(10), EndTime: fixedTime(9), Priority: 1}}
+	results, err := service.Submit(context.Background(), invalid)
+	if err != nil {
+		t.Fatalf("Unexpected error submitting: %v", err)
+	}
+	var invalidTask ErrInvalidTask
+	if result := <-results; !errors.As(result.Err, &invalidTask) {
+		t.Errorf("Expected ErrInvalidTask in the result, got %v", result.Err)
+	}
+}
+
+func TestSchedulerServiceStopWithFullQueue(t *testing.T) {
+	service := NewSchedulerService(newTestScheduler(), otelzap.New(zap.NewNop()))
+	tasks := []Task{{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1}}
+	queued := make([]<-chan ScheduleResult, 0, serviceQueueSize)
+	for i := 0; i < serviceQueueSize; i++ {
+		results, err := service.Submit(context.Background(), tasks)
+		if err != nil {
+			t.Fatalf("Unexpected error submitting: %v", err)
+		}
+		queued = append(queued, results)
+	}
+	// This submitter waits for room with a context that never ends
+	blocked := make(chan error, 1)
+	go func() {
+		_, err := service.Submit(context.Background(), tasks)
+		blocked <- err
+	}()
+
+	// Give the submitter time to start waiting, Stop mustn't wait for it either way
+	time.Sleep(10 * time.Millisecond)
+	stopped := make(chan struct{})
+	go func() {
+		service.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop didn't return with a submitter waiting on a full queue")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ran := make(chan struct{})
+	go func() {
+		service.Run(ctx)
+		close(ran)
+	}()
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run didn't return after the service stopped")
+	}
+	// Run may solve a few before it notices ctx, every one still gets a result
+	for i, results := range queued {
+		if result := <-results; result.Err != nil && !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("Expected batch %d to be solved or get the cancellation, got %v", i, result.Err)
+		}
+	}
+	select {
+	case err := <-blocked:
+		if !errors.Is(err, ErrServiceStopped) {
+			t.Errorf("Expected the waiting submitter to get ErrServiceStopped, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Submit didn't return after the service stopped")
+	}
+}
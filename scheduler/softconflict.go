@@ -0,0 +1,233 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxSoftConflictExact is the largest cluster the soft conflict solver searches exhaustively,
+// bigger ones only get the heuristic
+const maxSoftConflictExact = 20
+
+// softEdge is a conflict between two tasks in a soft conflict cluster, other is the index of the
+// other task. hard conflicts can't both be chosen, soft ones cost penalty when they are.
+type softEdge struct {
+	other   int
+	penalty float64
+	hard    bool
+}
+
+// softCluster is a cluster of tasks with every conflict between them worked out
+type softCluster struct {
+	tasks []Task
+	edges [][]softEdge
+}
+
+// newSoftCluster works out the conflicts between every pair of tasks, asking the soft conflict
+// penalty function about each pair tasksConflict says conflict. A NaN or +Inf penalty keeps
+// the pair a hard conflict, a negative one counts as zero.
+func (s *Scheduler) newSoftCluster(tasks []Task) softCluster {
+	cluster := softCluster{tasks: tasks, edges: make([][]softEdge, len(tasks))}
+	for i := range tasks {
+		for j := i + 1; j < len(tasks); j++ {
+			if !s.tasksConflict(tasks[i], tasks[j]) {
+				continue
+			}
+			penalty := s.options.softConflict(tasks[i], tasks[j])
+			hard := math.IsNaN(penalty) || math.IsInf(penalty, 1)
+			penalty = max(penalty, 0)
+			cluster.edges[i] = append(cluster.edges[i], softEdge{other: j, penalty: penalty, hard: hard})
+			cluster.edges[j] = append(cluster.edges[j], softEdge{other: i, penalty: penalty, hard: hard})
+		}
+	}
+	return cluster
+}
+
+// gain is what choosing task i adds to a schedule of the chosen tasks, its priority less the
+// penalties against them. ok is false if it hard conflicts with one of them.
+func (c softCluster) gain(i int, chosen []bool) (gain float64, ok bool) {
+	gain = c.tasks[i].Priority
+	for _, edge := range c.edges[i] {
+		if !chosen[edge.other] {
+			continue
+		}
+		if edge.hard {
+			return 0, false
+		}
+		gain -= edge.penalty
+	}
+	return gain, true
+}
+
+// scheduleSoftConflicts is schedule for WithSoftConflict. Penalties only apply between tasks
+// that conflict, so clusters are still independent and each is solved on its own, exactly
+// with a branch and bound search if it has at most maxSoftConflictExact tasks and with
+// softConflictHeuristic otherwise. The total returned has the penalties taken off.
+func (s *Scheduler) scheduleSoftConflicts(ctx context.Context, span trace.Span, tasks []Task) ([]Task, float64, []RejectedTask, error) {
+	if s.options.limitTasks || s.options.exclusiveGroups || len(sharedBundles(tasks)) > 0 || hasDependencies(tasks) {
+		return nil, 0, nil, errors.New("soft conflicts can't be combined with WithMaxTasks, WithExclusiveGroups, bundles or dependencies")
+	}
+	chosenTasks := make([]Task, 0)
+	totalPriority := 0.0
+	rejectedTasks := []RejectedTask{}
+	heuristicClusters := 0
+	for _, clusterTasks := range s.splitIntoClusters(tasks) {
+		if err := checkCancelled(ctx, 0); err != nil {
+			return nil, 0, nil, err
+		}
+		cluster := s.newSoftCluster(clusterTasks)
+		chosen, value, err := s.softConflictHeuristic(ctx, span, cluster)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		if len(clusterTasks) <= maxSoftConflictExact {
+			value = cluster.search(chosen, value)
+		} else {
+			heuristicClusters++
+		}
+
+		for i, task := range cluster.tasks {
+			if chosen[i] {
+				chosenTasks = append(chosenTasks, task)
+				continue
+			}
+			rejected := RejectedTask{TaskRejected: task, Reason: RejectionReasonLowPriority}
+			for _, edge := range cluster.edges[i] {
+				if chosen[edge.other] {
+					rejected.Reason = RejectionReasonConflict
+					rejected.CausedByID = cluster.tasks[edge.other].ID
+					break
+				}
+			}
+			span.AddEvent("task_rejected", trace.WithAttributes(attribute.String("reason", rejected.Reason.String())))
+			rejectedTasks = append(rejectedTasks, rejected)
+		}
+		totalPriority += value
+	}
+	span.SetAttributes(attribute.Int("num_soft_conflict_heuristic_clusters", heuristicClusters))
+	sort.SliceStable(chosenTasks, func(first, second int) bool {
+		return chosenTasks[first].StartTime.Before(chosenTasks[second].StartTime)
+	})
+	return chosenTasks, totalPriority, rejectedTasks, nil
+}
+
+// softConflictHeuristic picks a good schedule for a cluster: the mandatory tasks, then the
+// best schedule with no conflicts at all among the tasks they leave room for (the plain interval
+// DP), then greedily whichever task adds the most until none adds anything. It's never worse
+// than ignoring soft conflicts. An ErrInfeasible is returned if mandatory tasks hard conflict.
+func (s *Scheduler) softConflictHeuristic(ctx context.Context, span trace.Span, cluster softCluster) ([]bool, float64, error) {
+	chosen := make([]bool, len(cluster.tasks))
+	value := 0.0
+	for i, task := range cluster.tasks {
+		if !task.Mandatory {
+			continue
+		}
+		gain, ok := cluster.gain(i, chosen)
+		if !ok {
+			taskIDs := []string{task.ID}
+			for _, edge := range cluster.edges[i] {
+				if edge.hard && chosen[edge.other] {
+					taskIDs = append(taskIDs, cluster.tasks[edge.other].ID)
+				}
+			}
+			return nil, 0, ErrInfeasible{TaskIDs: taskIDs, Reason: "mandatory tasks conflict"}
+		}
+		chosen[i] = true
+		value += gain
+	}
+
+	// The DP gets copies whose IDs are their index so its choices can be mapped back, the real
+	// IDs may repeat
+	free := make([]Task, 0, len(cluster.tasks))
+	for i, task := range cluster.tasks {
+		if chosen[i] || touchesChosen(cluster.edges[i], chosen) {
+			continue
+		}
+		task.ID = strconv.Itoa(i)
+		free = append(free, task)
+	}
+	if len(free) > 0 {
+		freeChosen, freePriority, _, err := s.scheduleResources(ctx, span, free)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, task := range freeChosen {
+			i, _ := strconv.Atoi(task.ID)
+			chosen[i] = true
+		}
+		value += freePriority
+	}
+
+	for {
+		best, bestGain := -1, 0.0
+		for i := range cluster.tasks {
+			if chosen[i] {
+				continue
+			}
+			if gain, ok := cluster.gain(i, chosen); ok && gain > bestGain {
+				best, bestGain = i, gain
+			}
+		}
+		if best == -1 {
+			return chosen, value, nil
+		}
+		chosen[best] = true
+		value += bestGain
+	}
+}
+
+// touchesChosen checks if any of a task's conflicts, hard or soft, is with a chosen task
+func touchesChosen(edges []softEdge, chosen []bool) bool {
+	for _, edge := range edges {
+		if chosen[edge.other] {
+			return true
+		}
+	}
+	return false
+}
+
+// search looks for a strictly better schedule than best (worth bestValue) by branch and
+// bound over every subset of the cluster that includes the mandatory tasks and no hard
+// conflicts, updating best in place and returning its value. The bound is the value so far
+// plus every remaining positive priority, penalties only ever take away from that.
+func (c softCluster) search(best []bool, bestValue float64) float64 {
+	numTasks := len(c.tasks)
+	remaining := make([]float64, numTasks+1)
+	for i := numTasks - 1; i >= 0; i-- {
+		remaining[i] = remaining[i+1]
+		if c.tasks[i].Mandatory {
+			remaining[i] += c.tasks[i].Priority
+		} else {
+			remaining[i] += max(c.tasks[i].Priority, 0)
+		}
+	}
+
+	chosen := make([]bool, numTasks)
+	var visit func(i int, value float64)
+	visit = func(i int, value float64) {
+		if value+remaining[i] <= bestValue {
+			return
+		}
+		if i == numTasks {
+			copy(best, chosen)
+			bestValue = value
+			return
+		}
+		if gain, ok := c.gain(i, chosen); ok {
+			chosen[i] = true
+			visit(i+1, value+gain)
+			chosen[i] = false
+		}
+		if !c.tasks[i].Mandatory {
+			visit(i+1, value)
+		}
+	}
+	visit(0, 0)
+	return bestValue
+}
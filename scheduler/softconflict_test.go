@@ -0,0 +1,215 @@
+package scheduler
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// Helper function for a soft conflict penalty that's the same for every pair
+func flatPenalty(penalty float64) func(a, b Task) float64 {
+	return func(a, b Task) float64 { return penalty }
+}
+
+// Helper function to find the best total under soft conflicts by trying every subset
+func bruteForceSoft(tasks []Task, penalty func(a, b Task) float64) (float64, bool) {
+	s := newTestScheduler()
+	best, found := 0.0, false
+	for subset := 0; subset < 1<<len(tasks); subset++ {
+		total, valid := 0.0, true
+		for i := range tasks {
+			if subset&(1<<i) == 0 {
+				valid = valid && !tasks[i].Mandatory
+				continue
+			}
+			total += tasks[i].Priority
+			for j := 0; j < i; j++ {
+				if subset&(1<<j) == 0 || !s.tasksConflict(tasks[i], tasks[j]) {
+					continue
+				}
+				p := penalty(tasks[i], tasks[j])
+				valid = valid && !math.IsInf(p, 1)
+				total -= max(p, 0)
+			}
+		}
+		if valid && (!found || total > best) {
+			best, found = total, true
+		}
+	}
+	return best, found
+}
+
+func TestSoftConflict(t *testing.T) {
+	a := Task{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 5}
+	b := Task{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 3}
+	c := Task{ID: "c", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 2, Mandatory: true}
+	tests := []struct {
+		name             string
+		tasks            []Task
+		penalty          func(a, b Task) float64
+		expectedChosen   []string
+		expectedPriority float64
+		expectedCausedBy string
+	}{
+		{
+			name:             "Cheap overlap is worth it",
+			tasks:            []Task{a, b},
+			penalty:          flatPenalty(1),
+			expectedChosen:   []string{"a", "b"},
+			expectedPriority: 7,
+		},
+		{
+			name:             "Expensive overlap isn't",
+			tasks:            []Task{a, b},
+			penalty:          flatPenalty(4),
+			expectedChosen:   []string{"a"},
+			expectedPriority: 5,
+			expectedCausedBy: "a",
+		},
+		{
+			name:             "Infinite penalty is a hard conflict",
+			tasks:            []Task{a, b},
+			penalty:          flatPenalty(math.Inf(1)),
+			expectedChosen:   []string{"a"},
+			expectedPriority: 5,
+			expectedCausedBy: "a",
+		},
+		{
+			name:             "Mandatory task pays its penalties",
+			tasks:            []Task{a, b, c},
+			penalty:          flatPenalty(1),
+			expectedChosen:   []string{"a", "b", "c"},
+			expectedPriority: 7,
+		},
+		{
+			name:  "Penalty depends on the pair",
+			tasks: []Task{a, b, c},
+			penalty: func(first, second Task) float64 {
+				if first.ID == "b" || second.ID == "b" {
+					return 10
+				}
+				return 0.5
+			},
+			expectedChosen:   []string{"a", "c"},
+			expectedPriority: 6.5,
+			expectedCausedBy: "a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chosen, totalPriority, rejected, err := newTestScheduler().FindBestSchedule(tt.tasks, WithSoftConflict(tt.penalty))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if totalPriority != tt.expectedPriority {
+				t.Errorf("Expected total priority %v, got %v", tt.expectedPriority, totalPriority)
+			}
+			if len(chosen) != len(tt.expectedChosen) {
+				t.Fatalf("Expected %v chosen, got %+v", tt.expectedChosen, chosen)
+			}
+			for i, id := range tt.expectedChosen {
+				if chosen[i].ID != id {
+					t.Errorf("Expected task %d to be %s, got %s", i, id, chosen[i].ID)
+				}
+			}
+			if len(chosen)+len(rejected) != len(tt.tasks) {
+				t.Errorf("Expected every task to be chosen or rejected, got %d and %d", len(chosen), len(rejected))
+			}
+			for _, rejection := range rejected {
+				if rejection.Reason != RejectionReasonConflict || rejection.CausedByID != tt.expectedCausedBy {
+					t.Errorf("Expected a conflict with %s, got %+v", tt.expectedCausedBy, rejection)
+				}
+			}
+		})
+	}
+}
+
+func TestSoftConflictErrors(t *testing.T) {
+	first := Task{ID: "first", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 1, Mandatory: true}
+	second := Task{ID: "second", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 1, Mandatory: true}
+	var infeasible ErrInfeasible
+	_, _, _, err := newTestScheduler().FindBestSchedule([]Task{first, second}, WithSoftConflict(flatPenalty(math.Inf(1))))
+	if !errors.As(err, &infeasible) {
+		t.Errorf("Expected ErrInfeasible for hard conflicting mandatory tasks, got %v", err)
+	}
+	if _, _, _, err := newTestScheduler().FindBestSchedule([]Task{first}, WithSoftConflict(flatPenalty(1)), WithMaxTasks(1)); err == nil {
+		t.Error("Expected an error combining soft conflicts with WithMaxTasks")
+	}
+}
+
+func TestSoftConflictMatchesBruteForce(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for round := 0; round < 300; round++ {
+		tasks := make([]Task, 1+random.Intn(10))
+		for i := range tasks {
+			start := fixedTime(9).Add(time.Duration(random.Intn(12)) * 30 * time.Minute)
+			tasks[i] = Task{
+				StartTime:  start,
+				EndTime:    start.Add(time.Duration(random.Intn(5)) * 30 * time.Minute),
+				Priority:   float64(random.Intn(20)-4) / 4,
+				ResourceID: []string{"", "", "a"}[random.Intn(3)],
+				Mandatory:  random.Intn(15) == 0,
+			}
+		}
+		// Penalties from each pair's combined priority, some pairs hard conflicting
+		penalty := func(a, b Task) float64 {
+			if int(a.Priority*4+b.Priority*4)%5 == 0 {
+				return math.Inf(1)
+			}
+			return math.Abs(a.Priority-b.Priority) + 0.5
+		}
+		expected, feasible := bruteForceSoft(tasks, penalty)
+		_, totalPriority, _, err := newTestScheduler().FindBestSchedule(tasks, WithSoftConflict(penalty))
+		if !feasible {
+			if err == nil {
+				t.Fatalf("Round %d: expected an infeasible schedule for %+v", round, tasks)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Round %d: unexpected error: %v", round, err)
+		}
+		if math.Abs(totalPriority-expected) > 1e-9 {
+			t.Fatalf("Round %d: expected %v, got %v for %+v", round, expected, totalPriority, tasks)
+		}
+	}
+}
+
+func TestSoftConflictHeuristic(t *testing.T) {
+	// Too many overlapping tasks for the exact search, the heuristic still beats hard conflicts
+	tasks := make([]Task, 0, 3*maxSoftConflictExact)
+	for i := 0; i < 3*maxSoftConflictExact; i++ {
+		start := fixedTime(9).Add(time.Duration(i) * 10 * time.Minute)
+		tasks = append(tasks, Task{StartTime: start, EndTime: start.Add(30 * time.Minute), Priority: float64(1 + i%3)})
+	}
+	_, hardPriority, _, err := newTestScheduler().FindBestSchedule(tasks)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	chosen, softPriority, rejected, err := newTestScheduler().FindBestSchedule(tasks, WithSoftConflict(flatPenalty(0.5)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if softPriority <= hardPriority {
+		t.Errorf("Expected cheap overlaps to beat %v, got %v", hardPriority, softPriority)
+	}
+	if len(chosen)+len(rejected) != len(tasks) {
+		t.Errorf("Expected every task to be chosen or rejected, got %d and %d", len(chosen), len(rejected))
+	}
+	// The reported total is what the chosen tasks are worth after their penalties
+	worth := 0.0
+	for i := range chosen {
+		worth += chosen[i].Priority
+		for j := 0; j < i; j++ {
+			if newTestScheduler().tasksConflict(chosen[i], chosen[j]) {
+				worth -= 0.5
+			}
+		}
+	}
+	if math.Abs(worth-softPriority) > 1e-9 {
+		t.Errorf("Expected the total %v to match the chosen tasks' worth %v", softPriority, worth)
+	}
+}
@@ -0,0 +1,120 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// sendOrDone sends value on channel unless ctx is cancelled first, returning false if it was
+func sendOrDone[T any](ctx context.Context, channel chan<- T, value T) bool {
+	select {
+	case channel <- value:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// ScheduleStream is FindBestSchedule for very large inputs, it emits chosen tasks in
+// chronological order as soon as they're final instead of after the whole input is solved.
+// The input is split into clusters of tasks that can't affect each other, and each cluster
+// is solved and emitted in turn, so early tasks can be dispatched while later ones are still
+// being computed.
+//
+// Invalid input and infeasible mandatory tasks are reported through the error before anything
+// is emitted, as are options the stream can't honour like WithSoftConflict. The input goes
+// through the same checks as FindBestSchedule, so anything it refuses or drops the stream
+// does too. Both channels are closed once the stream is done, the caller has to keep reading
+// from both (e.g. in a select loop) or the stream stalls. The error channel is closed after
+// them and holds the error the stream stopped early on, if any, including ctx.Err() when ctx
+// is cancelled, so a stream that closes with no error finished the whole input.
+func (s *Scheduler) ScheduleStream(ctx context.Context, tasks []Task, opts ...Option) (<-chan Task, <-chan RejectedTask, <-chan error, error) {
+	s = s.withOptions(opts)
+	ctx, span := s.tracer().Start(ctx, "ScheduleStream")
+	logger := s.logger.Ctx(ctx)
+	span.SetAttributes(attribute.Int("num_tasks", len(tasks)))
+	logger.Info("Starting streaming scheduler", zap.Int("num_tasks", len(tasks)))
+
+	// Everything that can fail has to be checked before we hand out the channels. A cap on the
+	// number of tasks, exclusive groups, bundles and dependencies all mean no cluster is final
+	// until the last one is solved.
+	if s.options.limitTasks || s.options.exclusiveGroups || len(sharedBundles(tasks)) > 0 || hasDependencies(tasks) {
+		err := errors.New("ScheduleStream does not support WithMaxTasks, WithExclusiveGroups, bundles or dependencies")
+		span.RecordError(err)
+		span.End()
+		return nil, nil, nil, err
+	}
+	// Clusters are solved with the plain DP, which only knows hard conflicts
+	if s.options.softConflict != nil || s.options.overlapPolicy == ProRate {
+		err := errors.New("ScheduleStream does not support WithSoftConflict or the ProRate overlap policy")
+		span.RecordError(err)
+		span.End()
+		return nil, nil, nil, err
+	}
+	tasks, unschedulable, _, err := s.prepareTasks(span, logger, tasks, 1)
+	if err != nil {
+		span.End()
+		return nil, nil, nil, err
+	}
+	// FindBestSchedule finds clashing mandatory tasks as it goes, by then we'd have emitted
+	if err := s.checkMandatory(mandatoryTasks(tasks)); err != nil {
+		span.RecordError(err)
+		span.End()
+		logger.Warn("Streaming scheduler failed", zap.Error(err))
+		return nil, nil, nil, err
+	}
+
+	chosenChannel := make(chan Task)
+	rejectedChannel := make(chan RejectedTask)
+	errChannel := make(chan error, 1)
+	go func() {
+		defer span.End()
+		defer close(errChannel)
+		defer close(chosenChannel)
+		defer close(rejectedChannel)
+
+		if err := s.streamClusters(ctx, span, tasks, unschedulable, chosenChannel, rejectedChannel); err != nil {
+			span.RecordError(err)
+			logger.Warn("Streaming scheduler stopped", zap.Error(err))
+			errChannel <- err
+		}
+	}()
+	return chosenChannel, rejectedChannel, errChannel, nil
+}
+
+// streamClusters sends the unschedulable tasks and then solves tasks one cluster at a time,
+// sending what each cluster rejects and chooses. It returns why it stopped early, if it did.
+func (s *Scheduler) streamClusters(ctx context.Context, span trace.Span, tasks []Task, unschedulable []RejectedTask, chosenChannel chan<- Task, rejectedChannel chan<- RejectedTask) error {
+	for _, rejected := range unschedulable {
+		if !sendOrDone(ctx, rejectedChannel, rejected) {
+			return ctx.Err()
+		}
+	}
+	clusters := s.splitIntoClusters(tasks)
+	span.SetAttributes(attribute.Int("num_clusters", len(clusters)))
+	numChosen, numRejected := 0, len(unschedulable)
+	for _, cluster := range clusters {
+		chosenTasks, _, rejectedTasks, err := s.scheduleResources(ctx, span, cluster)
+		if err != nil {
+			return err
+		}
+		for _, rejected := range rejectedTasks {
+			if !sendOrDone(ctx, rejectedChannel, rejected) {
+				return ctx.Err()
+			}
+		}
+		for _, chosen := range chosenTasks {
+			if !sendOrDone(ctx, chosenChannel, chosen) {
+				return ctx.Err()
+			}
+		}
+		numChosen += len(chosenTasks)
+		numRejected += len(rejectedTasks)
+	}
+	s.logger.Ctx(ctx).Info("Streaming scheduler finished", zap.Int("num_chosen_tasks", numChosen), zap.Int("num_rejected_tasks", numRejected))
+	return nil
+}
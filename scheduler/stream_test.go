@@ -0,0 +1,174 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Helper function to drain a stream, returning the error it stopped on
+func collectStream(chosenChannel <-chan Task, rejectedChannel <-chan RejectedTask, errChannel <-chan error) ([]Task, []RejectedTask, error) {
+	chosen := make([]Task, 0)
+	rejected := make([]RejectedTask, 0)
+	for chosenChannel != nil || rejectedChannel != nil {
+		select {
+		case task, ok := <-chosenChannel:
+			if !ok {
+				chosenChannel = nil
+				continue
+			}
+			chosen = append(chosen, task)
+		case rejection, ok := <-rejectedChannel:
+			if !ok {
+				rejectedChannel = nil
+				continue
+			}
+			rejected = append(rejected, rejection)
+		}
+	}
+	return chosen, rejected, <-errChannel
+}
+
+func TestScheduleStream(t *testing.T) {
+	tasks := []Task{
+		// First cluster
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 5},
+		{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 8},
+		{ID: "c", StartTime: fixedTime(12), EndTime: fixedTime(12), Priority: 1},
+		// Second cluster, on two resources
+		{ID: "d", StartTime: fixedTime(13), EndTime: fixedTime(15), Priority: 4, ResourceID: "x"},
+		{ID: "e", StartTime: fixedTime(14), EndTime: fixedTime(16), Priority: 6, ResourceID: "x"},
+		{ID: "f", StartTime: fixedTime(13), EndTime: fixedTime(14), Priority: 2, ResourceID: "y"},
+		// Never schedulable
+		{ID: "g", StartTime: fixedTime(17), EndTime: fixedTime(18), Priority: 9, Deadline: fixedTime(17)},
+		// Third cluster, listed out of order
+		{ID: "h", StartTime: fixedTime(8), EndTime: fixedTime(8), Priority: 3},
+	}
+
+	chosenChannel, rejectedChannel, errChannel, err := newTestScheduler().ScheduleStream(context.Background(), tasks)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	chosen, rejected, err := collectStream(chosenChannel, rejectedChannel, errChannel)
+	if err != nil {
+		t.Fatalf("Unexpected stream error: %v", err)
+	}
+
+	expectedChosen, _, expectedRejected, err := newTestScheduler().FindBestSchedule(tasks)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(chosen, expectedChosen) {
+		t.Errorf("Expected the same schedule as FindBestSchedule:\nexpected %+v\ngot      %+v", expectedChosen, chosen)
+	}
+	if len(rejected) != len(expectedRejected) {
+		t.Errorf("Expected %d rejections, got %d", len(expectedRejected), len(rejected))
+	}
+	for i := 1; i < len(chosen); i++ {
+		if chosen[i].StartTime.Before(chosen[i-1].StartTime) {
+			t.Errorf("Chosen tasks out of order at %d", i)
+		}
+	}
+}
+
+func TestScheduleStreamErrors(t *testing.T) {
+	_, _, _, err := newTestScheduler().ScheduleStream(context.Background(), []Task{{Priority: 1}})
+	var invalid ErrInvalidTask
+	if !errors.As(err, &invalid) {
+		t.Errorf("Expected ErrInvalidTask, got %v", err)
+	}
+
+	_, _, _, err = newTestScheduler().ScheduleStream(context.Background(), []Task{
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 1, Mandatory: true},
+		{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(12), Priority: 1, Mandatory: true},
+	})
+	var infeasible ErrInfeasible
+	if !errors.As(err, &infeasible) {
+		t.Errorf("Expected ErrInfeasible, got %v", err)
+	}
+
+	task := Task{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1}
+	if _, _, _, err := newTestScheduler().ScheduleStream(context.Background(), []Task{task}, WithSoftConflict(flatPenalty(1))); err == nil {
+		t.Error("Expected ScheduleStream to refuse WithSoftConflict")
+	}
+}
+
+func TestScheduleStreamSharesChecks(t *testing.T) {
+	// Two conflicting tasks finishing together are an ambiguous tie under WithStrictTies
+	tied := []Task{
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 5},
+		{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 5},
+	}
+	_, _, _, expectedErr := newTestScheduler().FindBestSchedule(tied, WithStrictTies())
+	_, _, _, err := newTestScheduler().ScheduleStream(context.Background(), tied, WithStrictTies())
+	var tie ErrAmbiguousTie
+	if !errors.As(expectedErr, &tie) || !errors.As(err, &tie) {
+		t.Errorf("Expected ErrAmbiguousTie from both, got %v and %v", expectedErr, err)
+	}
+
+	// A copy of a task is dropped as a duplicate rather than rejected as a conflict
+	task := Task{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 5}
+	chosenChannel, rejectedChannel, errChannel, err := newTestScheduler().ScheduleStream(context.Background(), []Task{task, task})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	chosen, rejected, err := collectStream(chosenChannel, rejectedChannel, errChannel)
+	if err != nil {
+		t.Fatalf("Unexpected stream error: %v", err)
+	}
+	if len(chosen) != 1 {
+		t.Errorf("Expected 1 chosen task, got %d", len(chosen))
+	}
+	if len(rejected) != 1 || rejected[0].Reason != RejectionReasonDuplicate {
+		t.Errorf("Expected one duplicate rejection, got %+v", rejected)
+	}
+}
+
+func TestScheduleStreamCancel(t *testing.T) {
+	tasks := make([]Task, 0)
+	for hour := 0; hour < 20; hour++ {
+		tasks = append(tasks, Task{StartTime: fixedTime(hour), EndTime: fixedTime(hour).Add(30 * time.Minute), Priority: 1})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	chosenChannel, rejectedChannel, errChannel, err := newTestScheduler().ScheduleStream(ctx, tasks)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	<-chosenChannel
+	cancel()
+	// The stream has to close both channels rather than leaking its goroutine
+	chosen, _, err := collectStream(chosenChannel, rejectedChannel, errChannel)
+	if len(chosen) >= len(tasks)-1 {
+		t.Errorf("Expected the stream to stop early, got %d more tasks", len(chosen))
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the stream to report context.Canceled, got %v", err)
+	}
+}
+
+func TestSplitIntoClusters(t *testing.T) {
+	tasks := []Task{
+		{StartTime: fixedTime(13), EndTime: fixedTime(14)},
+		{StartTime: fixedTime(9), EndTime: fixedTime(11)},
+		{StartTime: fixedTime(10), EndTime: fixedTime(12)},
+		{StartTime: fixedTime(12), EndTime: fixedTime(12)},
+		{StartTime: fixedTime(12), EndTime: fixedTime(13)},
+		{StartTime: fixedTime(15), EndTime: fixedTime(16)},
+	}
+	clusters := newTestScheduler().splitIntoClusters(tasks)
+	sizes := make([]int, len(clusters))
+	for i, cluster := range clusters {
+		sizes[i] = len(cluster)
+	}
+	// 9-12, the instant at 12 and 12-13 touch, and so does 13-14
+	if !reflect.DeepEqual(sizes, []int{5, 1}) {
+		t.Errorf("Expected clusters of sizes [5 1], got %v", sizes)
+	}
+
+	withGap := newTestScheduler().withOptions([]Option{WithMinGap(2 * time.Hour)}).splitIntoClusters(tasks)
+	if len(withGap) != 1 {
+		t.Errorf("Expected a minimum gap to join everything into one cluster, got %d", len(withGap))
+	}
+}
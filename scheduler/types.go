@@ -1,14 +1,206 @@
 package scheduler
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
 type Task struct {
+	// ID identifies the task in rejections, one is generated by the scheduler if it's empty
+	ID        string    `json:"id"`
 	StartTime time.Time `json:"start_time"`
 	EndTime   time.Time `json:"end_time"`
-	Priority  float64   `json:"priority"`
+	// Priority is what the task adds to the schedule's total. A negative priority marks a task
+	// to avoid unless forced, it's never chosen on its own merits but is still scheduled (and
+	// counted against the total) when it's Mandatory.
+	Priority float64 `json:"priority"`
+	// ResourceID pins the task to a specific resource (e.g. an antenna), tasks only
+	// conflict with tasks on the same resource. Empty means the shared/global timeline.
+	ResourceID string `json:"resource_id,omitempty"`
+	// Deadline is an optional hard limit the task has to finish by, zero means no deadline
+	Deadline time.Time `json:"deadline"`
+	// NotBefore is an optional release time the task can't start before, zero means no limit
+	NotBefore time.Time `json:"not_before"`
+	// Mandatory tasks are always scheduled, the scheduler errors rather than dropping one
+	Mandatory bool `json:"mandatory,omitempty"`
+	// GroupID puts the task in an exclusive group, with WithExclusiveGroups at most one task
+	// from each group is chosen. Empty means no group.
+	GroupID string `json:"group_id,omitempty"`
+	// BundleID ties tasks that are only worth anything together, e.g. an uplink and the
+	// downlink that returns its results. Either every task sharing a BundleID is chosen or none
+	// are. Empty means no bundle.
+	BundleID string `json:"bundle_id,omitempty"`
+	// PriorityFunc optionally gives the task's priority depending on when it starts, e.g. for
+	// science that's worth less the later it runs. When set it replaces Priority, which the
+	// scheduler overwrites with the value at the task's placement in everything it returns.
+	PriorityFunc func(placedStart time.Time) float64 `json:"-"`
+	// Level optionally gives the task's priority as a named tier. When set (and PriorityFunc
+	// isn't) it replaces Priority with the level's weight from WithPriorityMapping, or its
+	// DefaultPriority, in everything the scheduler returns. See TaskFromLevel.
+	Level PriorityLevel `json:"level,omitempty"`
+	// Quality is an optional measure of how good the task's opportunity is, e.g. a pass's link
+	// quality from its max elevation. The scheduler ignores it unless WithEffectivePriority
+	// weights the priority with it.
+	Quality float64 `json:"quality,omitempty"`
+	// SetupBefore and TeardownAfter are lead-in and lead-out time the task needs on its
+	// timeline, e.g. pointing an antenna before a pass. Nothing else can use the timeline from
+	// StartTime-SetupBefore to EndTime+TeardownAfter, but the task keeps its own times.
+	SetupBefore   time.Duration `json:"setup_before,omitempty"`
+	TeardownAfter time.Duration `json:"teardown_after,omitempty"`
+	// Value and Cost are the task's monetary worth and what it costs to run. The scheduler
+	// ignores them unless WithObjective(MaxNetValue) optimises on Value-Cost instead of
+	// Priority, Statistics totals them over the chosen tasks either way.
+	Value float64 `json:"value,omitempty"`
+	Cost  float64 `json:"cost,omitempty"`
+	// Tags label the task for reporting, e.g. the mission or customer it's for, see
+	// StatisticsByTag. The scheduler ignores them.
+	Tags []string `json:"tags,omitempty"`
+	// DependsOn lists the IDs of tasks that have to run first, e.g. a calibration before
+	// imaging. The task is only chosen if, for each ID, a task with it is chosen too and ends
+	// by the time this one starts. Dependencies make the problem much harder, see
+	// FindBestSchedule.
+	DependsOn []string `json:"depends_on,omitempty"`
 }
+
+// PriorityAt is the task's priority if it starts at start, PriorityFunc(start) when that's
+// set and Priority otherwise
+func (t Task) PriorityAt(start time.Time) float64 {
+	if t.PriorityFunc != nil {
+		return t.PriorityFunc(start)
+	}
+	return t.Priority
+}
+
+// taskJSON is Task's JSON form, the same fields in the same order with the times as strings.
+// A field added to Task needs adding here too.
+type taskJSON struct {
+	ID         string  `json:"id"`
+	StartTime  string  `json:"start_time"`
+	EndTime    string  `json:"end_time"`
+	Priority   float64 `json:"priority"`
+	ResourceID string  `json:"resource_id,omitempty"`
+	Deadline   string  `json:"deadline,omitempty"`
+	NotBefore  string  `json:"not_before,omitempty"`
+	Mandatory  bool    `json:"mandatory,omitempty"`
+	GroupID    string  `json:"group_id,omitempty"`
+	BundleID   string  `json:"bundle_id,omitempty"`
+	Level      string  `json:"level,omitempty"`
+	Quality    float64 `json:"quality,omitempty"`
+	// Durations are Go duration strings like "10m"
+	SetupBefore   string   `json:"setup_before,omitempty"`
+	TeardownAfter string   `json:"teardown_after,omitempty"`
+	Value         float64  `json:"value,omitempty"`
+	Cost          float64  `json:"cost,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	DependsOn     []string `json:"depends_on,omitempty"`
+}
+
+// MarshalJSON writes the times as RFC3339 in whatever location they carry, with fractional
+// seconds only when there are any, the same form TaskOutput and LoadTasksCSV use. An unset
+// Deadline or NotBefore is left out.
+func (t Task) MarshalJSON() ([]byte, error) {
+	return json.Marshal(taskJSON{
+		ID:            t.ID,
+		StartTime:     formatJSONTime(t.StartTime),
+		EndTime:       formatJSONTime(t.EndTime),
+		Priority:      t.Priority,
+		ResourceID:    t.ResourceID,
+		Deadline:      formatJSONTime(t.Deadline),
+		NotBefore:     formatJSONTime(t.NotBefore),
+		Mandatory:     t.Mandatory,
+		GroupID:       t.GroupID,
+		BundleID:      t.BundleID,
+		Level:         string(t.Level),
+		Quality:       t.Quality,
+		SetupBefore:   formatJSONDuration(t.SetupBefore),
+		TeardownAfter: formatJSONDuration(t.TeardownAfter),
+		Value:         t.Value,
+		Cost:          t.Cost,
+		Tags:          t.Tags,
+		DependsOn:     t.DependsOn,
+	})
+}
+
+// UnmarshalJSON parses the times as RFC3339, keeping their offset. A missing, null or empty
+// time is left unset, which validation then catches for start_time and end_time.
+func (t *Task) UnmarshalJSON(data []byte) error {
+	var raw taskJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	task := Task{
+		ID:         raw.ID,
+		Priority:   raw.Priority,
+		ResourceID: raw.ResourceID,
+		Mandatory:  raw.Mandatory,
+		GroupID:    raw.GroupID,
+		BundleID:   raw.BundleID,
+		Level:      PriorityLevel(raw.Level),
+		Quality:    raw.Quality,
+		Value:      raw.Value,
+		Cost:       raw.Cost,
+		Tags:       raw.Tags,
+		DependsOn:  raw.DependsOn,
+	}
+	fields := []struct {
+		name  string
+		value string
+		into  *time.Time
+	}{
+		{"start_time", raw.StartTime, &task.StartTime},
+		{"end_time", raw.EndTime, &task.EndTime},
+		{"deadline", raw.Deadline, &task.Deadline},
+		{"not_before", raw.NotBefore, &task.NotBefore},
+	}
+	for _, field := range fields {
+		if field.value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, field.value)
+		if err != nil {
+			return fmt.Errorf("invalid %s for task %q: %w", field.name, task.ID, err)
+		}
+		*field.into = parsed
+	}
+	durations := []struct {
+		name  string
+		value string
+		into  *time.Duration
+	}{
+		{"setup_before", raw.SetupBefore, &task.SetupBefore},
+		{"teardown_after", raw.TeardownAfter, &task.TeardownAfter},
+	}
+	for _, duration := range durations {
+		if duration.value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(duration.value)
+		if err != nil {
+			return fmt.Errorf("invalid %s for task %q: %w", duration.name, task.ID, err)
+		}
+		*duration.into = parsed
+	}
+	*t = task
+	return nil
+}
+
+// formatJSONDuration formats a task duration for JSON, zero as an empty string
+func formatJSONDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
+// formatJSONTime formats a task time for JSON, the zero time as an empty string
+func formatJSONTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
 type ScheduleOutput struct {
 	ChosenTasks   []TaskOutput `json:"chosen_tasks"`
 	RejectedTasks []TaskOutput `json:"rejected_tasks"`
@@ -18,31 +210,91 @@ type ScheduleOutput struct {
 }
 
 type TaskOutput struct {
-	StartTime      string  `json:"start_time"`
-	EndTime        string  `json:"end_time"`
-	Priority       float64 `json:"priority"`
-	DurationMins   int     `json:"duration_mins"`
-	IsZeroDuration bool    `json:"is_zero_duration"`
+	ID             string   `json:"id"`
+	StartTime      string   `json:"start_time"`
+	EndTime        string   `json:"end_time"`
+	Priority       float64  `json:"priority"`
+	DurationMins   int      `json:"duration_mins"`
+	IsZeroDuration bool     `json:"is_zero_duration"`
+	ResourceID     string   `json:"resource_id,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	// RejectionReason and CausedByID are only set on rejected tasks
+	RejectionReason RejectionReason `json:"rejection_reason,omitempty"`
+	CausedByID      string          `json:"caused_by_id,omitempty"`
 }
 
 type Statistics struct {
 	TotalTasks     int `json:"total_tasks"`
 	ScheduledTasks int `json:"scheduled_tasks"`
 	RejectedTasks  int `json:"rejected_tasks"`
+	// UtilizedMinutes is how much of the window at least one chosen task covers, see
+	// ComputeStatistics
+	UtilizedMinutes  float64 `json:"utilized_minutes"`
+	WindowMinutes    float64 `json:"window_minutes"`
+	UtilizationRatio float64 `json:"utilization_ratio"`
+	// TotalValue and TotalCost add up the chosen tasks' Value and Cost, NetValue is the
+	// difference, which is what WithObjective(MaxNetValue) maximises
+	TotalValue float64 `json:"total_value"`
+	TotalCost  float64 `json:"total_cost"`
+	NetValue   float64 `json:"net_value"`
+	// ByTag breaks the chosen and rejected priority down by task tag, see StatisticsByTag. It's
+	// left out when no task has a tag.
+	ByTag map[string]TagStats `json:"by_tag,omitempty"`
 }
 
-// RejectionReason represents why a task was rejected
+// TagStats is how the tasks carrying one tag fared in a schedule
+type TagStats struct {
+	ChosenTasks      int     `json:"chosen_tasks"`
+	ChosenPriority   float64 `json:"chosen_priority"`
+	RejectedTasks    int     `json:"rejected_tasks"`
+	RejectedPriority float64 `json:"rejected_priority"`
+}
+
+// RejectionReason represents why a task was rejected. The values are stable and machine
+// readable, they're what ends up in the JSON output and on span events.
 type RejectionReason string
 
 const (
-	RejectionReasonConflict    RejectionReason = "CONFLICT"
+	// RejectionReasonConflict means the task overlaps a chosen task, see RejectedTask.CausedByID
+	RejectionReasonConflict RejectionReason = "CONFLICT"
+	// RejectionReasonLowPriority means leaving the task out gave an equal or better total
 	RejectionReasonLowPriority RejectionReason = "LOW_PRIORITY"
+	// RejectionReasonDeadlineMissed means the task would finish after its Deadline
+	RejectionReasonDeadlineMissed RejectionReason = "DEADLINE_MISSED"
+	// RejectionReasonNotReady means the task would start before its NotBefore time
+	RejectionReasonNotReady RejectionReason = "NOT_READY"
+	// RejectionReasonCapExceeded means the task fits alongside the chosen tasks but WithMaxTasks
+	// left no room for it
+	RejectionReasonCapExceeded RejectionReason = "CAP_EXCEEDED"
+	// RejectionReasonNoFeasiblePlacement means none of a FlexTask's possible starts fit
+	// alongside the chosen tasks
+	RejectionReasonNoFeasiblePlacement RejectionReason = "NO_FEASIBLE_PLACEMENT"
+	// RejectionReasonGroupExclusive means another task from the same exclusive group was chosen,
+	// see RejectedTask.CausedByID
+	RejectionReasonGroupExclusive RejectionReason = "GROUP_EXCLUSIVE"
+	// RejectionReasonBundleInfeasible means the task's bundle couldn't be chosen whole, see
+	// RejectedTask.CausedByID for a chosen task it would have conflicted with
+	RejectionReasonBundleInfeasible RejectionReason = "BUNDLE_INFEASIBLE"
+	// RejectionReasonDuplicate means the task is an exact copy of another task, see
+	// RejectedTask.CausedByID for the copy that was kept
+	RejectionReasonDuplicate RejectionReason = "DUPLICATE"
+	// RejectionReasonOutsideWindow means the task doesn't fit inside the WithWindow planning
+	// window, or with WithClampToWindow doesn't overlap it at all
+	RejectionReasonOutsideWindow RejectionReason = "OUTSIDE_WINDOW"
+	// RejectionReasonUnmetDependency means a task in the task's DependsOn wasn't chosen to end
+	// before it starts, see RejectedTask.CausedByID for the ID depended on
+	RejectionReasonUnmetDependency RejectionReason = "UNMET_DEPENDENCY"
 )
 
+func (r RejectionReason) String() string {
+	return string(r)
+}
+
 type RejectedTask struct {
-	TaskRejected Task            `json:"task_rejected"`
-	CausedBy     *Task           `json:"caused_by"`
-	Reason       RejectionReason `json:"reason"`
+	TaskRejected Task `json:"task_rejected"`
+	// CausedByID is the ID of the chosen task that pushed this one out, if any
+	CausedByID string          `json:"caused_by_id,omitempty"`
+	Reason     RejectionReason `json:"reason"`
 }
 
 type TimeRange struct {
@@ -0,0 +1,132 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTaskJSON(t *testing.T) {
+	tests := []struct {
+		name              string
+		input             string
+		expectedStart     time.Time
+		expectedEnd       time.Time
+		expectedDeadline  time.Time
+		expectedOffset    int
+		expectedMarshaled string
+	}{
+		{
+			name:              "UTC",
+			input:             `{"id":"a","start_time":"2024-01-01T09:00:00Z","end_time":"2024-01-01T10:00:00Z","priority":2}`,
+			expectedStart:     fixedTime(9),
+			expectedEnd:       fixedTime(10),
+			expectedOffset:    0,
+			expectedMarshaled: `{"id":"a","start_time":"2024-01-01T09:00:00Z","end_time":"2024-01-01T10:00:00Z","priority":2}`,
+		},
+		{
+			name:              "Offset and deadline",
+			input:             `{"id":"b","start_time":"2024-01-01T04:00:00-05:00","end_time":"2024-01-01T15:30:00+05:30","priority":1,"deadline":"2024-01-01T11:00:00+01:00"}`,
+			expectedStart:     fixedTime(9),
+			expectedEnd:       fixedTime(10),
+			expectedDeadline:  fixedTime(10),
+			expectedOffset:    -5 * 60 * 60,
+			expectedMarshaled: `{"id":"b","start_time":"2024-01-01T04:00:00-05:00","end_time":"2024-01-01T15:30:00+05:30","priority":1,"deadline":"2024-01-01T11:00:00+01:00"}`,
+		},
+		{
+			name:              "Fractional seconds and null times",
+			input:             `{"id":"c","start_time":"2024-01-01T09:00:00.25Z","end_time":"2024-01-01T10:00:00Z","priority":1,"deadline":null,"not_before":""}`,
+			expectedStart:     fixedTime(9).Add(250 * time.Millisecond),
+			expectedEnd:       fixedTime(10),
+			expectedOffset:    0,
+			expectedMarshaled: `{"id":"c","start_time":"2024-01-01T09:00:00.25Z","end_time":"2024-01-01T10:00:00Z","priority":1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var task Task
+			if err := json.Unmarshal([]byte(tt.input), &task); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !task.StartTime.Equal(tt.expectedStart) || !task.EndTime.Equal(tt.expectedEnd) || !task.Deadline.Equal(tt.expectedDeadline) {
+				t.Errorf("Expected %v to %v by %v, got %+v", tt.expectedStart, tt.expectedEnd, tt.expectedDeadline, task)
+			}
+			if _, offset := task.StartTime.Zone(); offset != tt.expectedOffset {
+				t.Errorf("Expected the start offset %d to be kept, got %d", tt.expectedOffset, offset)
+			}
+			marshaled, err := json.Marshal(task)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(marshaled) != tt.expectedMarshaled {
+				t.Errorf("Expected %s, got %s", tt.expectedMarshaled, marshaled)
+			}
+		})
+	}
+}
+
+func TestTaskJSONInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expectedError string
+	}{
+		{
+			name:          "Not RFC3339",
+			input:         `{"id":"a","start_time":"9am","end_time":"2024-01-01T10:00:00Z"}`,
+			expectedError: `invalid start_time for task "a"`,
+		},
+		{
+			name:          "No offset",
+			input:         `{"id":"a","start_time":"2024-01-01T09:00:00Z","end_time":"2024-01-01T10:00:00"}`,
+			expectedError: `invalid end_time for task "a"`,
+		},
+		{
+			name:          "Wrong type",
+			input:         `{"id":"a","start_time":9}`,
+			expectedError: "cannot unmarshal number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var task Task
+			err := json.Unmarshal([]byte(tt.input), &task)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected an error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestTasksJSONRoundTrip(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("No timezone data: %v", err)
+	}
+	tasks := []Task{
+		{ID: "a", StartTime: fixedTime(9).In(newYork), EndTime: fixedTime(10).In(newYork), Priority: 1.5, ResourceID: "antenna-a", NotBefore: fixedTime(8)},
+		{ID: "b", StartTime: fixedTime(11), EndTime: fixedTime(11), Priority: 2, Mandatory: true, GroupID: "g", BundleID: "pair", Level: PriorityHigh, Quality: 0.75, SetupBefore: 10 * time.Minute, TeardownAfter: 90 * time.Second, Value: 500, Cost: 120.25, Tags: []string{"acme", "nei-7"}, DependsOn: []string{"a"}},
+	}
+	data, err := json.Marshal(tasks)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var decoded []Task
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i := range tasks {
+		want, got := tasks[i], decoded[i]
+		if !got.StartTime.Equal(want.StartTime) || !got.EndTime.Equal(want.EndTime) || !got.NotBefore.Equal(want.NotBefore) || !got.Deadline.IsZero() {
+			t.Errorf("Expected the times of %s to round trip, got %+v", want.ID, got)
+		}
+		got.StartTime, got.EndTime, got.NotBefore = want.StartTime, want.EndTime, want.NotBefore
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %+v, got %+v", want, got)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package scheduler
+
+import (
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"turionspace/nei-mission-planner/scheduler/scheduler/core"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidTask is returned when a task can't be scheduled as given, Index is the
+// position of the offending task in the input slice
+type ErrInvalidTask struct {
+	Index  int
+	Reason string
+}
+
+func (e ErrInvalidTask) Error() string {
+	return fmt.Sprintf("invalid task at index %d: %s", e.Index, e.Reason)
+}
+
+// ErrConflictingTasks is returned by ValidateSchedule when two tasks in a schedule conflict,
+// First and Second are their positions in the slice that was checked
+type ErrConflictingTasks struct {
+	First  int
+	Second int
+}
+
+func (e ErrConflictingTasks) Error() string {
+	return fmt.Sprintf("tasks at index %d and %d conflict", e.First, e.Second)
+}
+
+// ErrAmbiguousTie is returned with WithStrictTies when two conflicting tasks finish at the
+// same instant, First and Second are their positions in the input
+type ErrAmbiguousTie struct {
+	First  int
+	Second int
+}
+
+func (e ErrAmbiguousTie) Error() string {
+	return fmt.Sprintf("tasks at index %d and %d conflict and end at the same time, their order is ambiguous", e.First, e.Second)
+}
+
+// optimalityTolerance allows for float rounding when comparing a schedule's total against
+// the recomputed optimum, the two may have been summed in a different order
+const optimalityTolerance = 1e-9
+
+// ErrTotalMismatch is returned with WithReconstructionCheck when the tasks a schedule came back
+// with don't add up to the total the solver says it found, which means reconstructing the
+// schedule from the DP went wrong
+type ErrTotalMismatch struct {
+	Optimal  float64
+	Achieved float64
+}
+
+func (e ErrTotalMismatch) Error() string {
+	return fmt.Sprintf("schedule total %v doesn't match its tasks' total %v", e.Optimal, e.Achieved)
+}
+
+// checkTotals adds up the chosen tasks' priorities and compares them against the total the
+// solver reported, allowing for the two being summed in a different order
+func checkTotals(optimal float64, chosen []Task) (float64, error) {
+	achieved := 0.0
+	for _, task := range chosen {
+		achieved += task.Priority
+	}
+	if math.Abs(optimal-achieved) > optimalityTolerance*math.Max(1, math.Abs(optimal)) {
+		return achieved, ErrTotalMismatch{Optimal: optimal, Achieved: achieved}
+	}
+	return achieved, nil
+}
+
+// ValidateSchedule independently checks that no two tasks in a schedule conflict, using the
+// same rules (and options) as FindBestSchedule. It's cheap enough to run on every schedule
+// before it's turned into commands.
+func ValidateSchedule(tasks []Task, opts ...Option) error {
+	s := (&Scheduler{}).withOptions(opts)
+	for i := range tasks {
+		for j := i + 1; j < len(tasks); j++ {
+			if s.tasksConflict(tasks[i], tasks[j]) {
+				return ErrConflictingTasks{First: i, Second: j}
+			}
+		}
+	}
+	return nil
+}
+
+// IsOptimal checks chosen is a valid schedule whose total priority matches the best
+// achievable from all, recomputing the optimum from scratch
+func (s *Scheduler) IsOptimal(all []Task, chosen []Task, opts ...Option) bool {
+	if err := ValidateSchedule(chosen, opts...); err != nil {
+		return false
+	}
+	_, bestPriority, _, err := s.FindBestSchedule(all, opts...)
+	if err != nil {
+		return false
+	}
+	chosenPriority := 0.0
+	for _, task := range chosen {
+		chosenPriority += task.PriorityAt(task.StartTime)
+	}
+	return math.Abs(bestPriority-chosenPriority) <= optimalityTolerance*math.Max(1, math.Abs(bestPriority))
+}
+
+// Validate checks a single task on its own, e.g. when a plan is ingested, returning every
+// problem found joined into one error (nil if there are none). A task ending before it
+// starts is always a problem here, use ValidateTasks with WithAllowNegativeDuration to
+// accept those. Negative priorities are fine, NaN and infinite ones are not.
+func (t Task) Validate() error {
+	var errs []error
+	for _, problem := range t.problems(false) {
+		errs = append(errs, errors.New(problem))
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateTasks checks every task the way Task.Validate does, respecting
+// WithAllowNegativeDuration, and returns all the problems found as ErrInvalidTasks joined into
+// one error so errors.As finds the first. The indexes are positions in tasks.
+func ValidateTasks(tasks []Task, opts ...Option) error {
+	s := (&Scheduler{}).withOptions(opts)
+	var errs []error
+	for i, task := range tasks {
+		for _, problem := range s.taskProblems(task) {
+			errs = append(errs, ErrInvalidTask{Index: i, Reason: problem})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// problems lists everything wrong with the task, in the order validateTasks reports them
+func (t Task) problems(allowNegativeDuration bool) []string {
+	var problems []string
+	if t.StartTime.IsZero() {
+		problems = append(problems, "start time is not set")
+	}
+	if t.EndTime.IsZero() {
+		problems = append(problems, "end time is not set")
+	}
+	if !t.StartTime.IsZero() && !t.EndTime.IsZero() && t.EndTime.Before(t.StartTime) && !allowNegativeDuration {
+		problems = append(problems, "end time is before start time")
+	}
+	if t.SetupBefore < 0 || t.TeardownAfter < 0 {
+		problems = append(problems, "setup and teardown can't be negative")
+	}
+	if t.Level != "" && !t.Level.valid() {
+		problems = append(problems, fmt.Sprintf("unknown priority level %q", t.Level))
+	}
+	// With a PriorityFunc the priority that counts is the one at the task's start
+	switch priority := t.PriorityAt(t.StartTime); {
+	case math.IsNaN(priority):
+		problems = append(problems, "priority is NaN")
+	case math.IsInf(priority, 0):
+		problems = append(problems, "priority is infinite")
+	}
+	if math.IsNaN(t.Value) || math.IsInf(t.Value, 0) || math.IsNaN(t.Cost) || math.IsInf(t.Cost, 0) {
+		problems = append(problems, "value and cost must be finite")
+	}
+	return problems
+}
+
+// taskProblems is problems with the scheduler's options applied, which adds checks that the
+// task's Level doesn't map to a NaN or infinite priority under WithPriorityMapping and that
+// WithEffectivePriority or MaxNetValue don't turn it into one
+func (s *Scheduler) taskProblems(t Task) []string {
+	problems := t.problems(s.options.allowNegativeDuration)
+	if t.Level != "" && t.PriorityFunc == nil && t.Level.valid() {
+		if priority := s.levelPriority(t.Level); math.IsNaN(priority) || math.IsInf(priority, 0) {
+			problems = append(problems, fmt.Sprintf("priority level %s maps to %v", t.Level, priority))
+		}
+	}
+	if (s.options.effectivePriority != nil || s.options.objective == MaxNetValue) && len(problems) == 0 {
+		resolved := []Task{t}
+		s.resolvePriorities(resolved)
+		if priority := resolved[0].Priority; math.IsNaN(priority) || math.IsInf(priority, 0) {
+			problems = append(problems, fmt.Sprintf("effective priority is %v", priority))
+		}
+	}
+	return problems
+}
+
+// validateTasks checks the input before any sorting happens so the reported index
+// matches the caller's slice. NaN and infinite priorities are refused here because every
+// comparison the DP makes against a NaN is false, so one would silently corrupt the schedule.
+func (s *Scheduler) validateTasks(tasks []Task) error {
+	for i, task := range tasks {
+		if problems := s.taskProblems(task); len(problems) > 0 {
+			return ErrInvalidTask{Index: i, Reason: problems[0]}
+		}
+	}
+	return nil
+}
+
+// checkStrictTies is WithStrictTies' check, it errors on the first pair of tasks (in input
+// order) that conflict and finish at the same instant. Tasks are compared as the DP sees them,
+// snapped and clamped to the window with their setup and teardown, so the instant is the
+// sortKey the DP orders by. An instant and a regular task ending together aren't a tie, the
+// regular task always sorts first.
+func (s *Scheduler) checkStrictTies(tasks []Task) error {
+	placed := make([]Task, len(tasks))
+	keys := make([]int64, len(tasks))
+	byKey := make(map[int64][]int)
+	for i, task := range tasks {
+		placed[i] = s.clampToWindow(s.snap(task))
+		keys[i] = s.sortKey(s.occupied(placed[i])).UnixNano()
+		byKey[keys[i]] = append(byKey[keys[i]], i)
+	}
+	for first := range placed {
+		for _, second := range byKey[keys[first]] {
+			if second > first && core.Compare(interval(s.occupied(placed[first])), interval(s.occupied(placed[second]))) == 0 &&
+				s.tasksConflict(placed[first], placed[second]) {
+				return ErrAmbiguousTie{First: first, Second: second}
+			}
+		}
+	}
+	return nil
+}
+
+// assignMissingIDs gives every task without an ID one derived from its position and contents,
+// so running the scheduler twice on the same input produces the same IDs
+func (s *Scheduler) assignMissingIDs(tasks []Task) {
+	// The name buffer and hash are shared across tasks, on large inputs allocating them per
+	// task costs more than the DP itself
+	var name []byte
+	hash := sha1.New()
+	for i := range tasks {
+		if tasks[i].ID != "" {
+			continue
+		}
+		// Same as fmt's "%d/%d/%d/%g/%s", so IDs don't change from what we used to generate
+		name = strconv.AppendInt(name[:0], int64(i), 10)
+		name = append(name, '/')
+		name = strconv.AppendInt(name, tasks[i].StartTime.UnixNano(), 10)
+		name = append(name, '/')
+		name = strconv.AppendInt(name, tasks[i].EndTime.UnixNano(), 10)
+		name = append(name, '/')
+		name = strconv.AppendFloat(name, tasks[i].Priority, 'g', -1, 64)
+		name = append(name, '/')
+		name = append(name, tasks[i].ResourceID...)
+		tasks[i].ID = uuid.NewHash(hash, uuid.NameSpaceOID, name, 5).String()
+	}
+}
+
+// resolvePriorities fixes each task's Priority at its placement, so the DP and everything
+// reporting on the schedule afterwards see the same number. It runs after assignMissingIDs
+// so generated IDs don't depend on PriorityFunc, WithPriorityMapping or WithEffectivePriority.
+// Under MaxNetValue the priority is Value-Cost whatever else the task sets. WithEffectivePriority
+// sees the task with its placed priority already filled in.
+func (s *Scheduler) resolvePriorities(tasks []Task) {
+	for i := range tasks {
+		switch {
+		case tasks[i].PriorityFunc != nil:
+			tasks[i].Priority = tasks[i].PriorityFunc(tasks[i].StartTime)
+		case tasks[i].Level != "":
+			tasks[i].Priority = s.levelPriority(tasks[i].Level)
+		}
+		if s.options.objective == MaxNetValue {
+			tasks[i].Priority = tasks[i].Value - tasks[i].Cost
+		}
+		if s.options.effectivePriority != nil {
+			tasks[i].Priority = s.options.effectivePriority(tasks[i])
+		}
+	}
+}
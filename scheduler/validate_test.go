@@ -0,0 +1,256 @@
+package scheduler
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateSchedule(t *testing.T) {
+	valid := []Task{
+		{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1},
+		{StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 1},
+		{StartTime: fixedTime(12), EndTime: fixedTime(12), Priority: 1},
+	}
+	if err := ValidateSchedule(valid); err != nil {
+		t.Errorf("Expected a valid schedule, got %v", err)
+	}
+
+	conflicting := []Task{
+		{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1},
+		{StartTime: fixedTime(12), EndTime: fixedTime(13), Priority: 1},
+		{StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 1},
+	}
+	var conflict ErrConflictingTasks
+	if err := ValidateSchedule(conflicting); !errors.As(err, &conflict) {
+		t.Fatalf("Expected ErrConflictingTasks, got %v", err)
+	}
+	if conflict.First != 0 || conflict.Second != 2 {
+		t.Errorf("Expected tasks 0 and 2 to conflict, got %d and %d", conflict.First, conflict.Second)
+	}
+
+	// Back-to-back tasks break a minimum gap
+	if err := ValidateSchedule(valid, WithMinGap(time.Minute)); err == nil {
+		t.Errorf("Expected back-to-back tasks to fail a minimum gap")
+	}
+}
+
+func TestIsOptimal(t *testing.T) {
+	all := []Task{
+		{StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 10},
+		{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 8},
+		{StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 8},
+	}
+	s := newTestScheduler()
+	if !s.IsOptimal(all, all[1:]) {
+		t.Errorf("Expected the two short tasks to be optimal")
+	}
+	if s.IsOptimal(all, all[:1]) {
+		t.Errorf("Expected the long task alone not to be optimal")
+	}
+	if s.IsOptimal(all, all) {
+		t.Errorf("Expected a conflicting schedule not to be optimal")
+	}
+	if all[0].Priority != 10 {
+		t.Errorf("Expected IsOptimal to leave the input order alone")
+	}
+}
+
+func TestTaskValidate(t *testing.T) {
+	tests := []struct {
+		name             string
+		task             Task
+		expectedProblems []string
+	}{
+		{
+			name:             "Valid task",
+			task:             Task{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1},
+			expectedProblems: nil,
+		},
+		{
+			name:             "Negative priority is allowed",
+			task:             Task{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: -3},
+			expectedProblems: nil,
+		},
+		{
+			name:             "Zero value",
+			task:             Task{},
+			expectedProblems: []string{"start time is not set", "end time is not set"},
+		},
+		{
+			name:             "Backwards with a NaN priority",
+			task:             Task{StartTime: fixedTime(10), EndTime: fixedTime(9), Priority: math.NaN()},
+			expectedProblems: []string{"end time is before start time", "priority is NaN"},
+		},
+		{
+			name: "Infinite priority from PriorityFunc",
+			task: Task{StartTime: fixedTime(9), EndTime: fixedTime(10), PriorityFunc: func(time.Time) float64 {
+				return math.Inf(-1)
+			}},
+			expectedProblems: []string{"priority is infinite"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.task.Validate()
+			if len(tt.expectedProblems) == 0 {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected %v, got no error", tt.expectedProblems)
+			}
+			if err.Error() != strings.Join(tt.expectedProblems, "\n") {
+				t.Errorf("Expected %q, got %q", strings.Join(tt.expectedProblems, "\n"), err.Error())
+			}
+		})
+	}
+}
+
+func TestValidateTasks(t *testing.T) {
+	tasks := []Task{
+		{StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1},
+		{StartTime: fixedTime(11), EndTime: fixedTime(10), Priority: 1},
+		{EndTime: fixedTime(10), Priority: math.NaN()},
+	}
+	err := ValidateTasks(tasks)
+	expected := "invalid task at index 1: end time is before start time\n" +
+		"invalid task at index 2: start time is not set\n" +
+		"invalid task at index 2: priority is NaN"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("Expected %q, got %v", expected, err)
+	}
+	var invalid ErrInvalidTask
+	if !errors.As(err, &invalid) || invalid.Index != 1 {
+		t.Errorf("Expected errors.As to find index 1 first, got %+v", invalid)
+	}
+
+	if err := ValidateTasks(tasks[:2], WithAllowNegativeDuration()); err != nil {
+		t.Errorf("Expected a negative duration to be allowed, got %v", err)
+	}
+	if err := ValidateTasks(nil); err != nil {
+		t.Errorf("Expected no tasks to be valid, got %v", err)
+	}
+}
+
+func TestStrictTies(t *testing.T) {
+	tests := []struct {
+		name          string
+		tasks         []Task
+		opts          []Option
+		expectedError *ErrAmbiguousTie
+	}{
+		{
+			name: "Overlapping tasks ending together",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(8), EndTime: fixedTime(9), Priority: 1},
+				{ID: "b", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 2},
+				{ID: "c", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 2},
+			},
+			expectedError: &ErrAmbiguousTie{First: 1, Second: 2},
+		},
+		{
+			name: "Same end on different resources",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 2, ResourceID: "x"},
+				{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 2, ResourceID: "y"},
+			},
+		},
+		{
+			name: "Instant at another task's end",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11), Priority: 2},
+				{ID: "b", StartTime: fixedTime(11), EndTime: fixedTime(11), Priority: 2},
+			},
+		},
+		{
+			name: "Teardown moves the end",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 2, TeardownAfter: time.Hour},
+				{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 2},
+			},
+			expectedError: &ErrAmbiguousTie{First: 0, Second: 1},
+		},
+		{
+			name: "Snapping makes a tie",
+			tasks: []Task{
+				{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(11).Add(-5 * time.Minute), Priority: 2},
+				{ID: "b", StartTime: fixedTime(10), EndTime: fixedTime(11), Priority: 2},
+			},
+			opts:          []Option{WithSnap(15 * time.Minute)},
+			expectedError: &ErrAmbiguousTie{First: 0, Second: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, err := newTestScheduler().FindBestSchedule(tt.tasks, append(tt.opts, WithStrictTies())...)
+			if tt.expectedError == nil {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			var tie ErrAmbiguousTie
+			if !errors.As(err, &tie) || tie != *tt.expectedError {
+				t.Fatalf("Expected %v, got %v", *tt.expectedError, err)
+			}
+			if _, _, _, err := newTestScheduler().FindBestSchedule(tt.tasks, tt.opts...); err != nil {
+				t.Errorf("Expected no error without WithStrictTies, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckTotals(t *testing.T) {
+	chosen := []Task{{Priority: 0.1}, {Priority: 0.2}, {Priority: 0.3}}
+	if achieved, err := checkTotals(0.3+0.2+0.1, chosen); err != nil || math.Abs(achieved-0.6) > 1e-12 {
+		t.Errorf("Expected totals summed in another order to match, got %v and %v", achieved, err)
+	}
+	var mismatch ErrTotalMismatch
+	if _, err := checkTotals(1, chosen); !errors.As(err, &mismatch) || mismatch.Optimal != 1 {
+		t.Errorf("Expected ErrTotalMismatch, got %v", err)
+	}
+}
+
+func TestReconstructionCheck(t *testing.T) {
+	random := rand.New(rand.NewSource(3))
+	optionSets := [][]Option{
+		nil,
+		{WithMinGap(15 * time.Minute)},
+		{WithMaxTasks(3)},
+		{WithExclusiveGroups()},
+		{WithObjective(MaxPriorityThenUtilization)},
+		{WithFixedPointPriorities(100)},
+	}
+	for round := 0; round < 200; round++ {
+		tasks := make([]Task, 1+random.Intn(12))
+		for i := range tasks {
+			start := fixedTime(9).Add(time.Duration(random.Intn(16)) * 15 * time.Minute)
+			tasks[i] = Task{
+				StartTime:  start,
+				EndTime:    start.Add(time.Duration(random.Intn(5)) * 15 * time.Minute),
+				Priority:   float64(random.Intn(40)-5) / 7,
+				ResourceID: []string{"", "", "a"}[random.Intn(3)],
+				GroupID:    []string{"", "g", "h"}[random.Intn(3)],
+				BundleID:   []string{"", "", "", "b"}[random.Intn(4)],
+			}
+		}
+		for _, opts := range optionSets {
+			opts = append(opts, WithReconstructionCheck())
+			chosen, totalPriority, _, err := newTestScheduler().FindBestSchedule(tasks, opts...)
+			if err != nil {
+				t.Fatalf("Round %d: unexpected error: %v", round, err)
+			}
+			if _, err := checkTotals(totalPriority, chosen); err != nil {
+				t.Fatalf("Round %d: %v", round, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package scheduler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FieldError is one problem with one field of a task payload
+type FieldError struct {
+	// Index is the task's position in an array payload, -1 for a single task object or for a
+	// problem with the payload as a whole
+	Index int `json:"index"`
+	// Field is the JSON name of the field, empty for a problem with the whole task or payload
+	Field  string `json:"field,omitempty"`
+	Reason string `json:"reason"`
+}
+
+func (e FieldError) Error() string {
+	var where string
+	switch {
+	case e.Index >= 0 && e.Field != "":
+		where = fmt.Sprintf("task %d %s: ", e.Index, e.Field)
+	case e.Index >= 0:
+		where = fmt.Sprintf("task %d: ", e.Index)
+	case e.Field != "":
+		where = e.Field + ": "
+	}
+	return where + e.Reason
+}
+
+// ErrInvalidTaskJSON is returned by ValidateTaskJSON with every problem it found, in the
+// order the tasks and their fields come in
+type ErrInvalidTaskJSON struct {
+	Fields []FieldError
+}
+
+func (e ErrInvalidTaskJSON) Error() string {
+	reasons := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		reasons[i] = field.Error()
+	}
+	return "invalid task JSON: " + strings.Join(reasons, "; ")
+}
+
+// jsonFieldKind is what a task field's JSON value has to look like
+type jsonFieldKind int
+
+const (
+	jsonString jsonFieldKind = iota
+	jsonNumber
+	jsonBool
+	// jsonTime is an RFC3339 string with an offset, like every time Task reads and writes
+	jsonTime
+	// jsonDuration is a Go duration string like "10m"
+	jsonDuration
+	// jsonStrings is an array of strings
+	jsonStrings
+)
+
+// taskJSONFields is every field of taskJSON with what it has to hold, in the same order
+var taskJSONFields = []struct {
+	name     string
+	kind     jsonFieldKind
+	required bool
+}{
+	{"id", jsonString, false},
+	{"start_time", jsonTime, true},
+	{"end_time", jsonTime, true},
+	{"priority", jsonNumber, false},
+	{"resource_id", jsonString, false},
+	{"deadline", jsonTime, false},
+	{"not_before", jsonTime, false},
+	{"mandatory", jsonBool, false},
+	{"group_id", jsonString, false},
+	{"bundle_id", jsonString, false},
+	{"level", jsonString, false},
+	{"quality", jsonNumber, false},
+	{"setup_before", jsonDuration, false},
+	{"teardown_after", jsonDuration, false},
+	{"value", jsonNumber, false},
+	{"cost", jsonNumber, false},
+	{"tags", jsonStrings, false},
+	{"depends_on", jsonStrings, false},
+}
+
+// ValidateTaskJSON checks a task payload's structure before it's unmarshaled into Task, so
+// clients can be told exactly which field of which task is wrong rather than getting the
+// first parse error. data is either a single task object or an array of them. Every task
+// needs a start_time and end_time, times must be RFC3339 with an offset, numbers (priority,
+// quality, value, cost) must be JSON numbers rather than strings, and unknown fields are
+// refused so a misspelt one isn't silently ignored. An optional field may be null, and an
+// optional time may also be "" to leave it unset.
+//
+// All the problems found come back in an ErrInvalidTaskJSON. It only checks the JSON, a
+// payload that passes can still hold tasks FindBestSchedule rejects, e.g. one that ends
+// before it starts.
+func ValidateTaskJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var payload any
+	if err := decoder.Decode(&payload); err != nil {
+		return ErrInvalidTaskJSON{Fields: []FieldError{{Index: -1, Reason: "malformed JSON: " + err.Error()}}}
+	}
+	if decoder.More() {
+		return ErrInvalidTaskJSON{Fields: []FieldError{{Index: -1, Reason: "unexpected data after the payload"}}}
+	}
+
+	var problems []FieldError
+	switch payload := payload.(type) {
+	case map[string]any:
+		problems = validateTaskObject(-1, payload)
+	case []any:
+		for i, task := range payload {
+			object, ok := task.(map[string]any)
+			if !ok {
+				problems = append(problems, FieldError{Index: i, Reason: "expected a task object, got " + jsonKind(task)})
+				continue
+			}
+			problems = append(problems, validateTaskObject(i, object)...)
+		}
+	default:
+		problems = []FieldError{{Index: -1, Reason: "expected a task object or an array of them, got " + jsonKind(payload)}}
+	}
+	if len(problems) > 0 {
+		return ErrInvalidTaskJSON{Fields: problems}
+	}
+	return nil
+}
+
+// validateTaskObject checks one decoded task object, index is its position for FieldError
+func validateTaskObject(index int, object map[string]any) []FieldError {
+	var problems []FieldError
+	fail := func(field, reason string) {
+		problems = append(problems, FieldError{Index: index, Field: field, Reason: reason})
+	}
+	known := make(map[string]bool, len(taskJSONFields))
+	for _, field := range taskJSONFields {
+		known[field.name] = true
+		value, present := object[field.name]
+		if !present || value == nil {
+			if field.required {
+				fail(field.name, "is required")
+			}
+			continue
+		}
+		switch field.kind {
+		case jsonNumber:
+			if _, ok := value.(json.Number); !ok {
+				fail(field.name, "must be a number, got "+jsonKind(value))
+			}
+		case jsonBool:
+			if _, ok := value.(bool); !ok {
+				fail(field.name, "must be true or false, got "+jsonKind(value))
+			}
+		case jsonStrings:
+			items, ok := value.([]any)
+			if !ok {
+				fail(field.name, "must be an array of strings, got "+jsonKind(value))
+				continue
+			}
+			for _, item := range items {
+				if _, ok := item.(string); !ok {
+					fail(field.name, "must be an array of strings, got "+jsonKind(item)+" in it")
+					break
+				}
+			}
+		default:
+			text, ok := value.(string)
+			if !ok {
+				fail(field.name, "must be a string, got "+jsonKind(value))
+				continue
+			}
+			switch {
+			case field.kind == jsonTime && text == "" && field.required:
+				fail(field.name, "is required")
+			case field.kind == jsonTime && text != "":
+				if _, err := time.Parse(time.RFC3339, text); err != nil {
+					fail(field.name, fmt.Sprintf("must be an RFC3339 time like 2024-01-01T09:00:00Z, got %q", text))
+				}
+			case field.kind == jsonDuration && text != "":
+				if _, err := time.ParseDuration(text); err != nil {
+					fail(field.name, fmt.Sprintf("must be a duration like 10m, got %q", text))
+				}
+			case field.name == "level" && text != "" && !PriorityLevel(text).valid():
+				fail(field.name, fmt.Sprintf("unknown priority level %q", text))
+			}
+		}
+	}
+
+	unknown := make([]string, 0)
+	for name := range object {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		fail(name, "is not a task field")
+	}
+	return problems
+}
+
+// jsonKind names the JSON type of a value decoded with UseNumber, for error messages
+func jsonKind(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "a string"
+	case json.Number:
+		return "a number"
+	case bool:
+		return "a boolean"
+	case []any:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
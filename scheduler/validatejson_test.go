@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidateTaskJSON(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedFields []FieldError
+	}{
+		{
+			name:  "Valid task",
+			input: `{"id":"a","start_time":"2024-01-01T09:00:00Z","end_time":"2024-01-01T10:00:00+01:00","priority":5,"mandatory":true,"setup_before":"10m","level":"HIGH","deadline":""}`,
+		},
+		{
+			name:  "Valid array",
+			input: `[{"start_time":"2024-01-01T09:00:00Z","end_time":"2024-01-01T10:00:00Z"},{"start_time":"2024-01-01T11:00:00Z","end_time":"2024-01-01T11:00:00Z","priority":null}]`,
+		},
+		{
+			name:  "Missing fields",
+			input: `{"id":"a","priority":1}`,
+			expectedFields: []FieldError{
+				{Index: -1, Field: "start_time", Reason: "is required"},
+				{Index: -1, Field: "end_time", Reason: "is required"},
+			},
+		},
+		{
+			name:  "Bad time formats",
+			input: `[{"start_time":"9am","end_time":"2024-01-01T10:00:00","not_before":"2024-01-01"}]`,
+			expectedFields: []FieldError{
+				{Index: 0, Field: "start_time", Reason: `must be an RFC3339 time like 2024-01-01T09:00:00Z, got "9am"`},
+				{Index: 0, Field: "end_time", Reason: `must be an RFC3339 time like 2024-01-01T09:00:00Z, got "2024-01-01T10:00:00"`},
+				{Index: 0, Field: "not_before", Reason: `must be an RFC3339 time like 2024-01-01T09:00:00Z, got "2024-01-01"`},
+			},
+		},
+		{
+			name:  "String priority",
+			input: `[{"start_time":"2024-01-01T09:00:00Z","end_time":"2024-01-01T10:00:00Z"},{"start_time":"2024-01-01T09:00:00Z","end_time":"2024-01-01T10:00:00Z","priority":"5"}]`,
+			expectedFields: []FieldError{
+				{Index: 1, Field: "priority", Reason: "must be a number, got a string"},
+			},
+		},
+		{
+			name:  "Wrong types and unknown fields",
+			input: `{"id":7,"start_time":9,"end_time":"2024-01-01T10:00:00Z","mandatory":"yes","level":"URGENT","teardown_after":"soon","prio":1}`,
+			expectedFields: []FieldError{
+				{Index: -1, Field: "id", Reason: "must be a string, got a number"},
+				{Index: -1, Field: "start_time", Reason: "must be a string, got a number"},
+				{Index: -1, Field: "mandatory", Reason: "must be true or false, got a string"},
+				{Index: -1, Field: "level", Reason: `unknown priority level "URGENT"`},
+				{Index: -1, Field: "teardown_after", Reason: `must be a duration like 10m, got "soon"`},
+				{Index: -1, Field: "prio", Reason: "is not a task field"},
+			},
+		},
+		{
+			name:  "Bad tags",
+			input: `[{"start_time":"2024-01-01T09:00:00Z","end_time":"2024-01-01T10:00:00Z","tags":"acme"},{"start_time":"2024-01-01T09:00:00Z","end_time":"2024-01-01T10:00:00Z","tags":["acme",7]}]`,
+			expectedFields: []FieldError{
+				{Index: 0, Field: "tags", Reason: "must be an array of strings, got a string"},
+				{Index: 1, Field: "tags", Reason: "must be an array of strings, got a number in it"},
+			},
+		},
+		{
+			name:           "Not a task",
+			input:          `[{"start_time":"2024-01-01T09:00:00Z","end_time":"2024-01-01T10:00:00Z"}, 3]`,
+			expectedFields: []FieldError{{Index: 1, Reason: "expected a task object, got a number"}},
+		},
+		{
+			name:           "Not an object or array",
+			input:          `"task"`,
+			expectedFields: []FieldError{{Index: -1, Reason: "expected a task object or an array of them, got a string"}},
+		},
+		{
+			name:           "Trailing data",
+			input:          `{"start_time":"2024-01-01T09:00:00Z","end_time":"2024-01-01T10:00:00Z"} {}`,
+			expectedFields: []FieldError{{Index: -1, Reason: "unexpected data after the payload"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTaskJSON([]byte(tt.input))
+			if tt.expectedFields == nil {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			var invalid ErrInvalidTaskJSON
+			if !errors.As(err, &invalid) {
+				t.Fatalf("Expected ErrInvalidTaskJSON, got %v", err)
+			}
+			if !reflect.DeepEqual(invalid.Fields, tt.expectedFields) {
+				t.Errorf("Expected %+v, got %+v", tt.expectedFields, invalid.Fields)
+			}
+		})
+	}
+}
+
+func TestValidateTaskJSONMalformed(t *testing.T) {
+	var invalid ErrInvalidTaskJSON
+	if err := ValidateTaskJSON([]byte(`[{"start_time": `)); !errors.As(err, &invalid) || len(invalid.Fields) != 1 || invalid.Fields[0].Index != -1 {
+		t.Errorf("Expected a single payload level problem, got %v", err)
+	}
+}
+
+func TestValidateTaskJSONAcceptsMarshaledTasks(t *testing.T) {
+	tasks := []Task{
+		{ID: "a", StartTime: fixedTime(9), EndTime: fixedTime(10), Priority: 1.5, ResourceID: "antenna-a", NotBefore: fixedTime(8), Deadline: fixedTime(12)},
+		{ID: "b", StartTime: fixedTime(11), EndTime: fixedTime(11), Mandatory: true, GroupID: "g", BundleID: "pair", Level: PriorityHigh, Quality: 0.75, SetupBefore: 10 * time.Minute, TeardownAfter: 90 * time.Second, Value: 500, Cost: 120.25, Tags: []string{"acme"}, DependsOn: []string{"a"}},
+	}
+	data, err := json.Marshal(tasks)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := ValidateTaskJSON(data); err != nil {
+		t.Errorf("Expected what Task marshals to to validate, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package schedulerpb
+
+import (
+	"turionspace/nei-mission-planner/scheduler/scheduler"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// MarshalScheduleProto encodes a schedule's output as a ScheduleOutput message, a more compact
+// alternative to its JSON for archiving. Every field is kept, including the derived
+// duration_mins and is_zero_duration, so UnmarshalScheduleProto gives back the same output.
+func MarshalScheduleProto(output scheduler.ScheduleOutput) ([]byte, error) {
+	return proto.Marshal(ScheduleOutputToProto(output))
+}
+
+// UnmarshalScheduleProto decodes what MarshalScheduleProto wrote
+func UnmarshalScheduleProto(data []byte) (scheduler.ScheduleOutput, error) {
+	var message ScheduleOutput
+	if err := proto.Unmarshal(data, &message); err != nil {
+		return scheduler.ScheduleOutput{}, err
+	}
+	return ScheduleOutputFromProto(&message), nil
+}
+
+// ScheduleOutputToProto converts a scheduler.ScheduleOutput into its message
+func ScheduleOutputToProto(output scheduler.ScheduleOutput) *ScheduleOutput {
+	return &ScheduleOutput{
+		ChosenTasks:   taskOutputsToProto(output.ChosenTasks),
+		RejectedTasks: taskOutputsToProto(output.RejectedTasks),
+		TotalPriority: output.TotalPriority,
+		Statistics: &Statistics{
+			TotalTasks:       int64(output.Statistics.TotalTasks),
+			ScheduledTasks:   int64(output.Statistics.ScheduledTasks),
+			RejectedTasks:    int64(output.Statistics.RejectedTasks),
+			UtilizedMinutes:  output.Statistics.UtilizedMinutes,
+			WindowMinutes:    output.Statistics.WindowMinutes,
+			UtilizationRatio: output.Statistics.UtilizationRatio,
+			TotalValue:       output.Statistics.TotalValue,
+			TotalCost:        output.Statistics.TotalCost,
+			NetValue:         output.Statistics.NetValue,
+			ByTag:            tagStatsToProto(output.Statistics.ByTag),
+		},
+		TimeRange: &TimeRange{Start: output.TimeRange.Start, End: output.TimeRange.End},
+	}
+}
+
+// ScheduleOutputFromProto converts a message into a scheduler.ScheduleOutput. The task lists
+// are never nil, matching NewScheduleOutput, so the JSON has [] rather than null.
+func ScheduleOutputFromProto(message *ScheduleOutput) scheduler.ScheduleOutput {
+	statistics := message.GetStatistics()
+	return scheduler.ScheduleOutput{
+		ChosenTasks:   taskOutputsFromProto(message.GetChosenTasks()),
+		RejectedTasks: taskOutputsFromProto(message.GetRejectedTasks()),
+		TotalPriority: message.GetTotalPriority(),
+		Statistics: scheduler.Statistics{
+			TotalTasks:       int(statistics.GetTotalTasks()),
+			ScheduledTasks:   int(statistics.GetScheduledTasks()),
+			RejectedTasks:    int(statistics.GetRejectedTasks()),
+			UtilizedMinutes:  statistics.GetUtilizedMinutes(),
+			WindowMinutes:    statistics.GetWindowMinutes(),
+			UtilizationRatio: statistics.GetUtilizationRatio(),
+			TotalValue:       statistics.GetTotalValue(),
+			TotalCost:        statistics.GetTotalCost(),
+			NetValue:         statistics.GetNetValue(),
+			ByTag:            tagStatsFromProto(statistics.GetByTag()),
+		},
+		TimeRange: scheduler.TimeRange{
+			Start: message.GetTimeRange().GetStart(),
+			End:   message.GetTimeRange().GetEnd(),
+		},
+	}
+}
+
+// taskOutputsToProto converts the task outputs of a schedule
+func taskOutputsToProto(tasks []scheduler.TaskOutput) []*TaskOutput {
+	messages := make([]*TaskOutput, len(tasks))
+	for i, task := range tasks {
+		messages[i] = &TaskOutput{
+			Id:              task.ID,
+			StartTime:       task.StartTime,
+			EndTime:         task.EndTime,
+			Priority:        task.Priority,
+			DurationMins:    int64(task.DurationMins),
+			IsZeroDuration:  task.IsZeroDuration,
+			ResourceId:      task.ResourceID,
+			RejectionReason: task.RejectionReason.String(),
+			CausedById:      task.CausedByID,
+			Tags:            task.Tags,
+		}
+	}
+	return messages
+}
+
+// taskOutputsFromProto converts the task output messages of a schedule back
+func taskOutputsFromProto(messages []*TaskOutput) []scheduler.TaskOutput {
+	tasks := make([]scheduler.TaskOutput, len(messages))
+	for i, message := range messages {
+		tasks[i] = scheduler.TaskOutput{
+			ID:              message.GetId(),
+			StartTime:       message.GetStartTime(),
+			EndTime:         message.GetEndTime(),
+			Priority:        message.GetPriority(),
+			DurationMins:    int(message.GetDurationMins()),
+			IsZeroDuration:  message.GetIsZeroDuration(),
+			ResourceID:      message.GetResourceId(),
+			RejectionReason: scheduler.RejectionReason(message.GetRejectionReason()),
+			CausedByID:      message.GetCausedById(),
+			Tags:            message.GetTags(),
+		}
+	}
+	return tasks
+}
+
+// tagStatsToProto converts the per tag statistics, nil when there aren't any
+func tagStatsToProto(byTag map[string]scheduler.TagStats) map[string]*TagStats {
+	if len(byTag) == 0 {
+		return nil
+	}
+	messages := make(map[string]*TagStats, len(byTag))
+	for tag, stats := range byTag {
+		messages[tag] = &TagStats{
+			ChosenTasks:      int64(stats.ChosenTasks),
+			ChosenPriority:   stats.ChosenPriority,
+			RejectedTasks:    int64(stats.RejectedTasks),
+			RejectedPriority: stats.RejectedPriority,
+		}
+	}
+	return messages
+}
+
+// tagStatsFromProto converts the per tag statistics back, nil when there aren't any so an
+// untagged schedule comes back the same
+func tagStatsFromProto(messages map[string]*TagStats) map[string]scheduler.TagStats {
+	if len(messages) == 0 {
+		return nil
+	}
+	byTag := make(map[string]scheduler.TagStats, len(messages))
+	for tag, message := range messages {
+		byTag[tag] = scheduler.TagStats{
+			ChosenTasks:      int(message.GetChosenTasks()),
+			ChosenPriority:   message.GetChosenPriority(),
+			RejectedTasks:    int(message.GetRejectedTasks()),
+			RejectedPriority: message.GetRejectedPriority(),
+		}
+	}
+	return byTag
+}
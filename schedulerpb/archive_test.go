@@ -0,0 +1,55 @@
+package schedulerpb
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+	"turionspace/nei-mission-planner/scheduler/scheduler"
+)
+
+func TestScheduleProtoRoundTrip(t *testing.T) {
+	base := time.Date(2024, 1, 1, 9, 0, 0, 0, time.FixedZone("EST", -5*60*60))
+	tasks := []scheduler.Task{
+		{ID: "pass", StartTime: base, EndTime: base.Add(90 * time.Minute), Priority: 2.5, ResourceID: "antenna", Value: 1200, Cost: 350.5, Tags: []string{"acme", "nei-7"}},
+		{ID: "command", StartTime: base.Add(2 * time.Hour), EndTime: base.Add(2 * time.Hour), Priority: 1},
+		{ID: "clash", StartTime: base.Add(time.Hour), EndTime: base.Add(3 * time.Hour), Priority: 0.5, ResourceID: "antenna", Tags: []string{"acme"}},
+	}
+	chosen := tasks[:2]
+	rejected := []scheduler.RejectedTask{{TaskRejected: tasks[2], Reason: scheduler.RejectionReasonConflict, CausedByID: "pass"}}
+	outputs := []scheduler.ScheduleOutput{
+		scheduler.NewScheduleOutput(tasks, chosen, 3.5, rejected),
+		scheduler.NewScheduleOutput(nil, nil, 0, nil),
+	}
+
+	for _, output := range outputs {
+		data, err := MarshalScheduleProto(output)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		back, err := UnmarshalScheduleProto(data)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want, err := json.Marshal(output)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got, err := json.Marshal(back)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("Expected the JSON to round trip\nwant %s\ngot  %s", want, got)
+		}
+		if len(output.ChosenTasks) > 0 && len(data) >= len(want) {
+			t.Errorf("Expected the protobuf (%d bytes) to be smaller than the JSON (%d bytes)", len(data), len(want))
+		}
+	}
+}
+
+func TestUnmarshalScheduleProtoInvalid(t *testing.T) {
+	if _, err := UnmarshalScheduleProto([]byte{0xff, 0xff}); err == nil {
+		t.Error("Expected an error for garbage input")
+	}
+}
@@ -0,0 +1,97 @@
+// Package schedulerpb holds the protobuf messages and gRPC service for the scheduler, along
+// with conversions between the messages and the scheduler package's types
+package schedulerpb
+
+//go:generate protoc -I .. --go_out=.. --go_opt=paths=source_relative --go-grpc_out=.. --go-grpc_opt=paths=source_relative ../schedulerpb/scheduler.proto ../schedulerpb/archive.proto
+
+import (
+	"time"
+	"turionspace/nei-mission-planner/scheduler/scheduler"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// timestamp converts a time, leaving zero times (no deadline and so on) unset
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// timeOf converts a timestamp back, an unset one is the zero time
+func timeOf(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// TaskToProto converts a scheduler.Task into its message, PriorityFunc can't be sent so only
+// Priority is
+func TaskToProto(task scheduler.Task) *Task {
+	return &Task{
+		Id:         task.ID,
+		StartTime:  timestamp(task.StartTime),
+		EndTime:    timestamp(task.EndTime),
+		Priority:   task.Priority,
+		ResourceId: task.ResourceID,
+		Deadline:   timestamp(task.Deadline),
+		NotBefore:  timestamp(task.NotBefore),
+		Mandatory:  task.Mandatory,
+		GroupId:    task.GroupID,
+		BundleId:   task.BundleID,
+	}
+}
+
+// TaskFromProto converts a message into a scheduler.Task, times come back in UTC
+func TaskFromProto(task *Task) scheduler.Task {
+	return scheduler.Task{
+		ID:         task.GetId(),
+		StartTime:  timeOf(task.GetStartTime()),
+		EndTime:    timeOf(task.GetEndTime()),
+		Priority:   task.GetPriority(),
+		ResourceID: task.GetResourceId(),
+		Deadline:   timeOf(task.GetDeadline()),
+		NotBefore:  timeOf(task.GetNotBefore()),
+		Mandatory:  task.GetMandatory(),
+		GroupID:    task.GetGroupId(),
+		BundleID:   task.GetBundleId(),
+	}
+}
+
+// TasksToProto converts a slice of tasks with TaskToProto
+func TasksToProto(tasks []scheduler.Task) []*Task {
+	messages := make([]*Task, len(tasks))
+	for i, task := range tasks {
+		messages[i] = TaskToProto(task)
+	}
+	return messages
+}
+
+// TasksFromProto converts a slice of messages with TaskFromProto
+func TasksFromProto(messages []*Task) []scheduler.Task {
+	tasks := make([]scheduler.Task, len(messages))
+	for i, message := range messages {
+		tasks[i] = TaskFromProto(message)
+	}
+	return tasks
+}
+
+// RejectedTaskToProto converts a scheduler.RejectedTask into its message
+func RejectedTaskToProto(rejected scheduler.RejectedTask) *RejectedTask {
+	return &RejectedTask{
+		Task:       TaskToProto(rejected.TaskRejected),
+		Reason:     rejected.Reason.String(),
+		CausedById: rejected.CausedByID,
+	}
+}
+
+// RejectedTaskFromProto converts a message into a scheduler.RejectedTask
+func RejectedTaskFromProto(rejected *RejectedTask) scheduler.RejectedTask {
+	return scheduler.RejectedTask{
+		TaskRejected: TaskFromProto(rejected.GetTask()),
+		Reason:       scheduler.RejectionReason(rejected.GetReason()),
+		CausedByID:   rejected.GetCausedById(),
+	}
+}
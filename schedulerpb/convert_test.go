@@ -0,0 +1,41 @@
+package schedulerpb
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"turionspace/nei-mission-planner/scheduler/scheduler"
+)
+
+func TestTaskRoundTrip(t *testing.T) {
+	base := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	tasks := []scheduler.Task{
+		{
+			ID:         "full",
+			StartTime:  base,
+			EndTime:    base.Add(90 * time.Minute),
+			Priority:   2.5,
+			ResourceID: "antenna",
+			Deadline:   base.Add(2 * time.Hour),
+			NotBefore:  base.Add(-time.Hour),
+			Mandatory:  true,
+			GroupID:    "passes",
+			BundleID:   "experiment",
+		},
+		{ID: "bare", StartTime: base, EndTime: base},
+	}
+	for _, task := range tasks {
+		message := TaskToProto(task)
+		if task.Deadline.IsZero() && message.GetDeadline() != nil {
+			t.Errorf("Expected no deadline on %s to stay unset", task.ID)
+		}
+		if back := TaskFromProto(message); !reflect.DeepEqual(back, task) {
+			t.Errorf("Expected %+v back, got %+v", task, back)
+		}
+	}
+
+	rejected := scheduler.RejectedTask{TaskRejected: tasks[1], Reason: scheduler.RejectionReasonConflict, CausedByID: "full"}
+	if back := RejectedTaskFromProto(RejectedTaskToProto(rejected)); !reflect.DeepEqual(back, rejected) {
+		t.Errorf("Expected %+v back, got %+v", rejected, back)
+	}
+}